@@ -4,13 +4,22 @@ import (
 	"bufio"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/vibium/clicker/internal/bidi"
 	"github.com/vibium/clicker/internal/browser"
+	"github.com/vibium/clicker/internal/daemon"
+	"github.com/vibium/clicker/internal/devices"
+	errs "github.com/vibium/clicker/internal/errors"
 	"github.com/vibium/clicker/internal/features"
 	"github.com/vibium/clicker/internal/log"
 	"github.com/vibium/clicker/internal/mcp"
@@ -18,18 +27,167 @@ import (
 	"github.com/vibium/clicker/internal/process"
 	"github.com/vibium/clicker/internal/proxy"
 	"github.com/vibium/clicker/internal/recording"
+	"github.com/vibium/clicker/internal/repl"
+	"github.com/vibium/clicker/internal/runner"
 )
 
 var version = "0.1.0"
 
 // Global flags
 var (
-	headless  bool
-	waitOpen  int
-	waitClose int
-	verbose   bool
+	headless     bool
+	waitOpen     int
+	waitClose    int
+	verbose      bool
+	device       string
+	viewport     string
+	dpr          float64
+	locale       string
+	timezone     string
+	geolocation  string
+	userAgent    string
+	downloadDir  string
+	dialog       string
+	pace         bool
+	paceMove     time.Duration
+	paceClick    time.Duration
+	paceKey      time.Duration
+	paceJitter   float64
+	paceSeed     int64
+	session      string
+	connectURL   string
+	capabilities string
+	browserName  string
+
+	browserArgs      []string
+	ignoreArgs       []string
+	userDataDir      string
+	proxyServer      string
+	proxyBypass      string
+	extensions       []string
+	envVars          []string
+	executablePath   string
+	chromedriverPath string
+	launchTimeout    time.Duration
+	chromeVersion    string
 )
 
+// launchOptions builds browser launch options from the global flags.
+func launchOptions() browser.LaunchOptions {
+	opts := browser.LaunchOptions{Headless: headless}
+
+	b, err := browser.ParseBrowser(browserName)
+	if err != nil {
+		fail("", err)
+	}
+	opts.Browser = b
+
+	if device != "" {
+		d, err := devices.Lookup(device)
+		if err != nil {
+			fail("", err)
+		}
+		opts.ApplyDevice(d)
+	}
+
+	if viewport != "" {
+		vp, err := devices.ParseViewport(viewport)
+		if err != nil {
+			fail("", err)
+		}
+		if opts.Viewport != nil {
+			vp.DevicePixelRatio = opts.Viewport.DevicePixelRatio
+		}
+		opts.Viewport = vp
+	}
+
+	if dpr > 0 {
+		if opts.Viewport == nil {
+			opts.Viewport = &devices.Viewport{}
+		}
+		opts.Viewport.DevicePixelRatio = dpr
+	}
+
+	if geolocation != "" {
+		geo, err := devices.ParseGeolocation(geolocation)
+		if err != nil {
+			fail("", err)
+		}
+		opts.Geolocation = geo
+	}
+
+	opts.Locale = locale
+	opts.Timezone = timezone
+	if userAgent != "" {
+		opts.UserAgent = userAgent
+	}
+	opts.DownloadDir = downloadDir
+
+	policy, err := bidi.ParseDialogPolicy(dialog)
+	if err != nil {
+		fail("", err)
+	}
+	opts.DialogPolicy = policy
+
+	if pace {
+		pacing := bidi.DefaultPacing()
+		pacing.MoveDuration = paceMove
+		pacing.ClickDelay = paceClick
+		pacing.KeyDelay = paceKey
+		pacing.Jitter = paceJitter
+		pacing.Seed = paceSeed
+		opts.Pacing = &pacing
+	}
+
+	opts.ConnectURL = connectURL
+	if capabilities != "" {
+		if err := json.Unmarshal([]byte(capabilities), &opts.Capabilities); err != nil {
+			fail("", errs.InvalidArgument("invalid --capabilities (expected a JSON object): %w", err))
+		}
+	}
+
+	opts.Args = browserArgs
+	opts.IgnoreDefaultArgs = ignoreArgs
+	opts.UserDataDir = userDataDir
+	opts.ProxyServer = proxyServer
+	opts.ProxyBypass = proxyBypass
+	opts.Extensions = extensions
+	for _, kv := range envVars {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || name == "" {
+			fail("", errs.InvalidArgument("invalid --env %q (expected NAME=VALUE)", kv))
+		}
+		if opts.Env == nil {
+			opts.Env = map[string]string{}
+		}
+		opts.Env[name] = value
+	}
+	opts.ExecutablePath = executablePath
+	opts.ChromedriverPath = chromedriverPath
+	opts.LaunchTimeout = launchTimeout
+	opts.ChromeVersion = chromeVersion
+
+	return opts
+}
+
+// applyEmulation applies the viewport, geolocation, locale, timezone,
+// download and dialog flags to the page before it navigates to url.
+func applyEmulation(client *bidi.Client, url string) {
+	opts := launchOptions()
+	if err := browser.ApplyEmulation(client, "", opts); err != nil {
+		fail("applying emulation", err)
+	}
+	if err := browser.PrepareSession(client, opts); err != nil {
+		fail("preparing session", err)
+	}
+	if p := client.Pacer(); p != nil {
+		fmt.Printf("Input pacing on (seed %d)\n", p.Seed())
+	}
+	if err := browser.GrantGeolocation(client, opts, url); err != nil {
+		fail("granting geolocation permission", err)
+	}
+}
+
 // doWaitOpen waits for page to load if --wait-open is set.
 func doWaitOpen() {
 	if waitOpen > 0 {
@@ -47,6 +205,205 @@ func waitAndClose(launchResult *browser.LaunchResult) {
 	launchResult.Close()
 }
 
+// startSession launches the browser and connects to it, applying the global
+// flags for a page at url, or attaches to the daemon named by --session.
+// Call the returned function to close the browser (or detach from it).
+func startSession(url string) (*bidi.Client, func()) {
+	if session != "" {
+		fmt.Printf("Attaching to session %q...\n", session)
+		client, _, err := daemon.Attach(session)
+		if err != nil {
+			fail("attaching", err)
+		}
+		if p := client.Pacer(); p != nil {
+			fmt.Printf("Input pacing on (seed %d)\n", p.Seed())
+		}
+		return client, func() { client.Close() }
+	}
+
+	fmt.Println("Launching browser...")
+	launchResult, err := browser.Start(launchOptions())
+	if err != nil {
+		fail("launching browser", err)
+	}
+
+	fmt.Println("Connecting to BiDi...")
+	conn, err := bidi.Connect(launchResult.WebSocketURL)
+	if err != nil {
+		launchResult.Close()
+		fail("connecting", err)
+	}
+
+	client := bidi.NewClient(conn)
+	if err := launchResult.Watch(client); err != nil {
+		fail("watching browser", err)
+	}
+	applyEmulation(client, url)
+
+	return client, func() {
+		conn.Close()
+		waitAndClose(launchResult)
+	}
+}
+
+// openPage launches the browser (see startSession) and navigates to url.
+// An empty url, as with --session, stays on the current page.
+// Call the returned function to close the browser.
+func openPage(url string) (*bidi.Client, func()) {
+	client, closeSession := startSession(url)
+	if url == "" {
+		return client, closeSession
+	}
+
+	fmt.Printf("Navigating to %s...\n", url)
+	if _, err := client.Navigate("", url); err != nil {
+		closeSession()
+		fail("navigating", err)
+	}
+
+	doWaitOpen()
+
+	return client, closeSession
+}
+
+// daemonSessionName returns the --session name for daemon commands.
+func daemonSessionName() string {
+	if session == "" {
+		return daemon.DefaultName
+	}
+	return session
+}
+
+// sessionCommand marks commands that can attach to a daemon with --session.
+var sessionCommand = map[string]string{"session": "attach"}
+
+// pageArgs accepts a URL followed by n arguments (at least n if variadic).
+// With --session the page is already open, so the URL is left out.
+func pageArgs(n int, variadic bool) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if session == "" {
+			n++
+		}
+		if variadic {
+			return cobra.MinimumNArgs(n)(cmd, args)
+		}
+		return cobra.ExactArgs(n)(cmd, args)
+	}
+}
+
+// splitURL splits the arguments accepted by pageArgs into the URL (empty
+// with --session) and the rest.
+func splitURL(args []string) (string, []string) {
+	if session != "" {
+		return "", args
+	}
+	return args[0], args[1:]
+}
+
+// parseModifiers parses modifier keys separated by "+" or ",", e.g. "Shift+Control".
+func parseModifiers(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.FieldsFunc(s, func(r rune) bool { return r == '+' || r == ',' })
+}
+
+// parsePoint parses an "X,Y" position.
+func parsePoint(s string) (*bidi.Point, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return nil, errs.InvalidArgument("invalid position %q (expected X,Y)", s)
+	}
+	x, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return nil, errs.InvalidArgument("invalid position %q: %w", s, err)
+	}
+	y, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return nil, errs.InvalidArgument("invalid position %q: %w", s, err)
+	}
+	return &bidi.Point{X: x, Y: y}, nil
+}
+
+// writeRunReport writes a run report as "junit" or "json" to path, or to
+// stdout if path is empty.
+func writeRunReport(report *runner.Report, format, path string) error {
+	out := io.Writer(os.Stdout)
+	if path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if format == "junit" {
+		return report.WriteJUnit(out)
+	}
+	return report.WriteJSON(out)
+}
+
+// formatFromExtension returns the screenshot format implied by a file name.
+func formatFromExtension(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jpg", ".jpeg":
+		return "jpeg"
+	case ".webp":
+		return "webp"
+	default:
+		return "png"
+	}
+}
+
+// parseClip parses an "X,Y,WIDTH,HEIGHT" clip rectangle.
+func parseClip(s string) (*bidi.ClipRectangle, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return nil, errs.InvalidArgument("invalid clip %q (expected X,Y,WIDTH,HEIGHT)", s)
+	}
+
+	values := make([]float64, 4)
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, errs.InvalidArgument("invalid clip %q: %w", s, err)
+		}
+		values[i] = v
+	}
+	if values[2] <= 0 || values[3] <= 0 {
+		return nil, errs.InvalidArgument("invalid clip %q: width and height must be positive", s)
+	}
+
+	return &bidi.ClipRectangle{X: values[0], Y: values[1], Width: values[2], Height: values[3]}, nil
+}
+
+// parseMargin parses a PDF margin in centimeters: either a single value for
+// all sides or "TOP,RIGHT,BOTTOM,LEFT".
+func parseMargin(s string) (*bidi.PDFMargin, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 1 && len(parts) != 4 {
+		return nil, errs.InvalidArgument("invalid margin %q (expected CM or TOP,RIGHT,BOTTOM,LEFT)", s)
+	}
+
+	values := make([]float64, len(parts))
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, errs.InvalidArgument("invalid margin %q: %w", s, err)
+		}
+		if v < 0 {
+			return nil, errs.InvalidArgument("invalid margin %q: margins must not be negative", s)
+		}
+		values[i] = v
+	}
+	if len(values) == 1 {
+		return &bidi.PDFMargin{Top: values[0], Right: values[0], Bottom: values[0], Left: values[0]}, nil
+	}
+
+	return &bidi.PDFMargin{Top: values[0], Right: values[1], Bottom: values[2], Left: values[3]}, nil
+}
+
 // printCheck prints an actionability check result with a checkmark or X.
 func printCheck(name string, passed bool) {
 	if passed {
@@ -68,6 +425,13 @@ func main() {
 			if verbose {
 				log.Setup(log.LevelVerbose)
 			}
+			setupOutput(cmd)
+			if session != "" && cmd.Annotations["session"] == "" {
+				fail("", errs.InvalidArgument("'%s' does not support --session", cmd.CommandPath()))
+			}
+			if session != "" && connectURL != "" {
+				fail("", errs.InvalidArgument("--session and --connect cannot be used together"))
+			}
 		},
 		Run: func(cmd *cobra.Command, args []string) {
 			cmd.Help()
@@ -75,16 +439,49 @@ func main() {
 	}
 
 	// Add global flags for browser commands
+	rootCmd.PersistentFlags().StringVar(&browserName, "browser", "chrome", "Browser to launch: chrome or firefox (Firefox needs geckodriver in PATH)")
 	rootCmd.PersistentFlags().BoolVar(&headless, "headless", false, "Hide browser window (visible by default)")
 	rootCmd.PersistentFlags().IntVar(&waitOpen, "wait-open", 0, "Seconds to wait after navigation for page to load")
 	rootCmd.PersistentFlags().IntVar(&waitClose, "wait-close", 0, "Seconds to keep browser open before closing")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable debug logging")
+	rootCmd.PersistentFlags().StringVar(&device, "device", "", "Emulate a device profile, e.g. \"Pixel 7\" (see 'clicker devices')")
+	rootCmd.PersistentFlags().StringVar(&viewport, "viewport", "", "Viewport size as WIDTHxHEIGHT, e.g. 1280x720")
+	rootCmd.PersistentFlags().Float64Var(&dpr, "dpr", 0, "Device pixel ratio (e.g. 2 for retina)")
+	rootCmd.PersistentFlags().StringVar(&locale, "locale", "", "Browser locale, e.g. de-DE")
+	rootCmd.PersistentFlags().StringVar(&timezone, "timezone", "", "IANA timezone, e.g. Europe/Berlin")
+	rootCmd.PersistentFlags().StringVar(&geolocation, "geolocation", "", "Emulated position as LAT,LON[,ACCURACY], e.g. 52.52,13.40")
+	rootCmd.PersistentFlags().StringVar(&userAgent, "user-agent", "", "Override the browser user agent")
+	rootCmd.PersistentFlags().StringVar(&downloadDir, "download-dir", "", "Directory to save downloads to (created if missing)")
+	rootCmd.PersistentFlags().StringVar(&dialog, "dialog", "dismiss", "Policy for alert/confirm/prompt dialogs: accept, dismiss or fail")
+	defaultPacing := bidi.DefaultPacing()
+	rootCmd.PersistentFlags().BoolVar(&pace, "pace", false, "Pace input like a person: eased pointer paths, held clicks, spaced keystrokes")
+	rootCmd.PersistentFlags().DurationVar(&paceMove, "pace-move", defaultPacing.MoveDuration, "With --pace: time the pointer takes to reach a target")
+	rootCmd.PersistentFlags().DurationVar(&paceClick, "pace-click", defaultPacing.ClickDelay, "With --pace: time a mouse button is held")
+	rootCmd.PersistentFlags().DurationVar(&paceKey, "pace-key", defaultPacing.KeyDelay, "With --pace: time between keystrokes")
+	rootCmd.PersistentFlags().Float64Var(&paceJitter, "pace-jitter", defaultPacing.Jitter, "With --pace: random variation of delays (0 to 1)")
+	rootCmd.PersistentFlags().Int64Var(&paceSeed, "pace-seed", 0, "With --pace: random seed to reproduce a run (0 = random)")
+	rootCmd.PersistentFlags().StringVar(&session, "session", "", "Attach to the browser of a running daemon instead of launching one (see 'clicker daemon')")
+	rootCmd.PersistentFlags().StringVar(&connectURL, "connect", "", "Use an existing browser instead of launching one: a WebDriver URL (chromedriver, Selenium Grid) or a ws:// BiDi URL")
+	rootCmd.PersistentFlags().StringVar(&capabilities, "capabilities", "", "Extra WebDriver capabilities as a JSON object, e.g. '{\"browserVersion\":\"stable\"}'")
+	rootCmd.PersistentFlags().StringArrayVar(&browserArgs, "browser-arg", nil, "Extra browser argument, e.g. --browser-arg=--start-maximized (repeatable)")
+	rootCmd.PersistentFlags().StringArrayVar(&ignoreArgs, "ignore-default-arg", nil, "Drop a default Chrome argument by name, e.g. --ignore-default-arg=--disable-extensions (repeatable)")
+	rootCmd.PersistentFlags().StringVar(&userDataDir, "user-data-dir", "", "Keep the browser profile in this directory across runs (created if missing)")
+	rootCmd.PersistentFlags().StringVar(&proxyServer, "proxy-server", "", "Proxy as [SCHEME://]HOST:PORT, e.g. http://proxy.corp:3128 or socks5://127.0.0.1:1080")
+	rootCmd.PersistentFlags().StringVar(&proxyBypass, "proxy-bypass", "", "Comma-separated hosts that skip the proxy, e.g. localhost,*.internal")
+	rootCmd.PersistentFlags().StringArrayVar(&extensions, "extension", nil, "Load an unpacked extension directory (repeatable)")
+	rootCmd.PersistentFlags().StringArrayVar(&envVars, "env", nil, "Set NAME=VALUE in the browser's environment (repeatable)")
+	rootCmd.PersistentFlags().StringVar(&executablePath, "executable-path", "", "Browser executable to launch instead of the one found")
+	rootCmd.PersistentFlags().StringVar(&chromedriverPath, "chromedriver-path", "", "Driver executable to use instead of the one found (geckodriver with --browser firefox)")
+	rootCmd.PersistentFlags().StringVar(&chromeVersion, "chrome-version", "", "Installed Chrome for Testing version to use, e.g. 131.0.6778.85 or 131 (default: $VIBIUM_CHROME_VERSION, else the newest; see 'clicker browsers list')")
+	rootCmd.PersistentFlags().DurationVar(&launchTimeout, "launch-timeout", 0, "How long starting the driver and creating the session may take (default 30s)")
+	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Print one JSON document with the result (or error) on stdout; progress goes to stderr")
 
 	rootCmd.AddCommand(&cobra.Command{
 		Use:   "version",
 		Short: "Print the version number",
 		Run: func(cmd *cobra.Command, args []string) {
 			fmt.Printf("Clicker v%s\n", version)
+			emit(fields{"version": version})
 		},
 	})
 
@@ -92,58 +489,195 @@ func main() {
 		Use:   "paths",
 		Short: "Print browser and cache paths",
 		Run: func(cmd *cobra.Command, args []string) {
+			// Missing paths are null in the JSON output
+			found := fields{"cacheDir": nil, "chrome": nil, "chromedriver": nil, "firefox": nil, "geckodriver": nil}
+
 			cacheDir, err := paths.GetCacheDir()
 			if err != nil {
 				fmt.Printf("Cache directory: error: %v\n", err)
 			} else {
 				fmt.Printf("Cache directory: %s\n", cacheDir)
+				found["cacheDir"] = cacheDir
 			}
 
-			chromePath, err := paths.GetChromeExecutable()
+			chromePath, err := paths.GetChromeExecutableFor(chromeVersion)
 			if err != nil {
 				fmt.Println("Chrome: not found")
 			} else {
 				fmt.Printf("Chrome: %s\n", chromePath)
+				found["chrome"] = chromePath
 			}
 
-			chromedriverPath, err := paths.GetChromedriverPath()
+			chromedriverPath, err := paths.GetChromedriverPathFor(chromeVersion)
 			if err != nil {
 				fmt.Println("Chromedriver: not found")
 			} else {
 				fmt.Printf("Chromedriver: %s\n", chromedriverPath)
+				found["chromedriver"] = chromedriverPath
+			}
+
+			firefoxPath, err := paths.GetFirefoxExecutable()
+			if err != nil {
+				fmt.Println("Firefox: not found")
+			} else {
+				fmt.Printf("Firefox: %s\n", firefoxPath)
+				found["firefox"] = firefoxPath
+			}
+
+			geckodriverPath, err := paths.GetGeckodriverPath()
+			if err != nil {
+				fmt.Println("Geckodriver: not found")
+			} else {
+				fmt.Printf("Geckodriver: %s\n", geckodriverPath)
+				found["geckodriver"] = geckodriverPath
 			}
+
+			emit(found)
 		},
 	})
 
 	rootCmd.AddCommand(&cobra.Command{
+		Use:   "devices",
+		Short: "List built-in device profiles for --device",
+		Run: func(cmd *cobra.Command, args []string) {
+			var list []fields
+			for _, d := range devices.List() {
+				kind := "desktop"
+				if d.Mobile {
+					kind = "mobile"
+				}
+				fmt.Printf("%-20s %4dx%-4d  dpr=%-5g %s\n", d.Name, d.Viewport.Width, d.Viewport.Height, d.Viewport.DevicePixelRatio, kind)
+				list = append(list, fields{
+					"name":   d.Name,
+					"width":  d.Viewport.Width,
+					"height": d.Viewport.Height,
+					"dpr":    d.Viewport.DevicePixelRatio,
+					"mobile": d.Mobile,
+					"touch":  d.Touch,
+				})
+			}
+			emit(fields{"devices": list})
+		},
+	})
+
+	installCmd := &cobra.Command{
 		Use:   "install",
 		Short: "Download Chrome for Testing and chromedriver",
+		Example: `  clicker install
+  clicker install --version 131.0.6778.85   # exactly the version CI used
+  clicker install --version 131             # the newest 131
+  clicker install --channel beta
+  clicker install --mirror https://artifacts.example.com/chrome-for-testing --checksums SHA256SUMS
+  clicker install --version 131.0.6778.85 --from-zip chrome-linux64.zip --driver-zip chromedriver-linux64.zip`,
 		Run: func(cmd *cobra.Command, args []string) {
-			result, err := browser.Install()
+			opts := browser.InstallOptions{}
+			opts.Version, _ = cmd.Flags().GetString("version")
+			opts.Channel, _ = cmd.Flags().GetString("channel")
+			opts.Mirror, _ = cmd.Flags().GetString("mirror")
+			opts.FromZip, _ = cmd.Flags().GetString("from-zip")
+			opts.DriverZip, _ = cmd.Flags().GetString("driver-zip")
+			opts.Checksums, _ = cmd.Flags().GetString("checksums")
+			opts.Progress = installProgress()
+			result, err := browser.Install(opts)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-				os.Exit(1)
+				fail("", err)
 			}
 
-			fmt.Println("Installation complete!")
+			if result.AlreadyInstalled {
+				fmt.Printf("Chrome for Testing v%s is already installed\n", result.Version)
+			} else {
+				fmt.Println("Installation complete!")
+			}
 			fmt.Printf("Chrome: %s\n", result.ChromePath)
 			fmt.Printf("Chromedriver: %s\n", result.ChromedriverPath)
 			fmt.Printf("Version: %s\n", result.Version)
+			emit(fields{"chrome": result.ChromePath, "chromedriver": result.ChromedriverPath, "version": result.Version, "alreadyInstalled": result.AlreadyInstalled})
+		},
+	}
+	installCmd.Flags().String("version", "", "Chrome for Testing version to install, exact or a prefix like 131 (default: latest of --channel)")
+	installCmd.Flags().String("channel", "", "Install the latest version of a channel: stable (default), beta, dev or canary")
+	installCmd.Flags().String("mirror", "", "Download from this base URL instead of Google's servers (default: $"+browser.MirrorEnv+")")
+	installCmd.Flags().String("from-zip", "", "Install Chrome from this local zip instead of downloading (needs --driver-zip and the exact --version)")
+	installCmd.Flags().String("driver-zip", "", "Install chromedriver from this local zip (with --from-zip)")
+	installCmd.Flags().String("checksums", "", "SHA-256 manifest (file or URL, sha256sum format) the zips must match")
+	rootCmd.AddCommand(installCmd)
+
+	browsersCmd := &cobra.Command{
+		Use:   "browsers",
+		Short: "Manage installed Chrome for Testing versions",
+		Long: `Manage the Chrome for Testing versions 'clicker install' downloaded.
+
+Browser commands use the newest installed version, unless --chrome-version
+or the VIBIUM_CHROME_VERSION environment variable picks another one, e.g.
+131.0.6778.85, or 131 for the newest 131.`,
+	}
+	browsersCmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List installed versions, newest first",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			installed, err := paths.ListInstalledChrome()
+			if err != nil {
+				fail("", err)
+			}
+			selected, _ := paths.FindInstalledChrome(chromeVersion)
+
+			list := []fields{}
+			for _, c := range installed {
+				isDefault := selected != nil && c.Version == selected.Version
+				marker := " "
+				if isDefault {
+					marker = "*"
+				}
+				fmt.Printf("%s %s  %s\n", marker, c.Version, c.Dir)
+				list = append(list, fields{"version": c.Version, "dir": c.Dir, "chrome": c.ChromePath, "chromedriver": c.ChromedriverPath, "default": isDefault})
+			}
+			if len(installed) == 0 {
+				fmt.Println("No Chrome for Testing installed (run 'clicker install')")
+			}
+			emit(fields{"browsers": list})
 		},
 	})
+	pruneCmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove all but the newest installed versions",
+		Long: `Remove all but the newest --keep installed Chrome for Testing versions.
+The version --chrome-version or VIBIUM_CHROME_VERSION pins is always kept. Partial installs and
+interrupted downloads are removed too.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			keep, _ := cmd.Flags().GetInt("keep")
+			removed, err := browser.Prune(keep, chromeVersion)
+			versions := []string{}
+			for _, c := range removed {
+				fmt.Printf("Removed %s\n", c.Version)
+				versions = append(versions, c.Version)
+			}
+			if err != nil {
+				failWith(fields{"removed": versions}, "", err)
+			}
+			if len(removed) == 0 {
+				fmt.Println("Nothing to remove")
+			}
+			emit(fields{"removed": versions})
+		},
+	}
+	pruneCmd.Flags().Int("keep", 1, "Number of newest versions to keep")
+	browsersCmd.AddCommand(pruneCmd)
+	rootCmd.AddCommand(browsersCmd)
 
 	rootCmd.AddCommand(&cobra.Command{
 		Use:   "launch-test",
 		Short: "Launch browser via chromedriver and print BiDi WebSocket URL",
 		Run: func(cmd *cobra.Command, args []string) {
-			result, err := browser.Launch(browser.LaunchOptions{Headless: headless})
+			result, err := browser.Launch(launchOptions())
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-				os.Exit(1)
+				fail("", err)
 			}
 
 			fmt.Printf("Session ID: %s\n", result.SessionID)
 			fmt.Printf("BiDi WebSocket: %s\n", result.WebSocketURL)
+			emit(fields{"sessionId": result.SessionID, "webSocketUrl": result.WebSocketURL})
 			fmt.Println("Press Ctrl+C to stop...")
 
 			// Wait for signal, then cleanup
@@ -155,15 +689,20 @@ func main() {
 	rootCmd.AddCommand(&cobra.Command{
 		Use:   "ws-test [url]",
 		Short: "Test WebSocket connection (type messages, see echoes)",
-		Args:  cobra.ExactArgs(1),
+		Long: `Test a WebSocket connection: each line typed is sent as is and every
+message received is printed.
+
+For an interactive session with a browser, with line editing, history and
+high-level commands, use 'clicker repl'.`,
+		Annotations: textOnly,
+		Args:        cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			url := args[0]
 			fmt.Printf("Connecting to %s...\n", url)
 
 			conn, err := bidi.Connect(url)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-				os.Exit(1)
+				fail("", err)
 			}
 			defer conn.Close()
 
@@ -200,8 +739,7 @@ func main() {
 			fmt.Println("[1/5] Launching chromedriver...")
 			launchResult, err := browser.Launch(browser.LaunchOptions{Headless: true, Verbose: true})
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error launching browser: %v\n", err)
-				os.Exit(1)
+				fail("launching browser", err)
 			}
 			defer waitAndClose(launchResult)
 			fmt.Printf("       Chromedriver started on port %d\n", launchResult.Port)
@@ -213,8 +751,7 @@ func main() {
 			fmt.Println("[3/5] Connecting to BiDi WebSocket...")
 			conn, err := bidi.Connect(launchResult.WebSocketURL)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error connecting: %v\n", err)
-				os.Exit(1)
+				fail("connecting", err)
 			}
 			defer conn.Close()
 			fmt.Println("       Connected!")
@@ -225,8 +762,7 @@ func main() {
 
 			status, err := client.SessionStatus()
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-				os.Exit(1)
+				fail("", err)
 			}
 
 			fmt.Println("[5/5] Parsed response:")
@@ -234,45 +770,32 @@ func main() {
 			fmt.Printf("       Message: %s\n", status.Message)
 
 			fmt.Println("\nTest complete!")
+			emit(fields{"ready": status.Ready, "message": status.Message})
 		},
 	})
 
 	rootCmd.AddCommand(&cobra.Command{
-		Use:   "navigate [url]",
-		Short: "Navigate to a URL and print page info",
-		Args:  cobra.ExactArgs(1),
+		Use:         "navigate [url]",
+		Short:       "Navigate to a URL and print page info",
+		Annotations: sessionCommand,
+		Args:        cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			process.WithCleanup(func() {
 				url := args[0]
 
-				fmt.Println("Launching browser...")
-				launchResult, err := browser.Launch(browser.LaunchOptions{Headless: headless})
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error launching browser: %v\n", err)
-					os.Exit(1)
-				}
-				defer waitAndClose(launchResult)
-
-				fmt.Println("Connecting to BiDi...")
-				conn, err := bidi.Connect(launchResult.WebSocketURL)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error connecting: %v\n", err)
-					os.Exit(1)
-				}
-				defer conn.Close()
-
-				client := bidi.NewClient(conn)
+				client, closeSession := startSession(url)
+				defer closeSession()
 
 				fmt.Printf("Navigating to %s...\n", url)
 				result, err := client.Navigate("", url)
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error navigating: %v\n", err)
-					os.Exit(1)
+					fail("navigating", err)
 				}
 
 				fmt.Printf("Navigation complete!\n")
 				fmt.Printf("  URL: %s\n", result.URL)
 				fmt.Printf("  Navigation ID: %s\n", result.Navigation)
+				emit(fields{"url": result.URL, "navigation": result.Navigation})
 			})
 		},
 	})
@@ -281,113 +804,210 @@ func main() {
 		Use:   "screenshot [url]",
 		Short: "Navigate to a URL and capture a screenshot",
 		Example: `  clicker screenshot https://example.com -o shot.png
-  # Saves screenshot to shot.png`,
-		Args: cobra.ExactArgs(1),
+  # Saves screenshot to shot.png
+
+  clicker screenshot https://example.com -o page.jpg --full-page --quality 80
+  # Captures the whole page as JPEG (format inferred from the extension)
+
+  clicker screenshot https://example.com -o heading.png --selector h1
+  # Captures just the first h1 element
+
+  clicker screenshot https://example.com -o area.webp --clip 0,0,400,300
+  # Captures a 400x300 rectangle from the top-left corner
+
+  clicker screenshot --session work -o now.png
+  # Captures the current page of a running daemon (see 'clicker daemon')`,
+		Annotations: sessionCommand,
+		Args:        pageArgs(0, false),
 		Run: func(cmd *cobra.Command, args []string) {
 			process.WithCleanup(func() {
-				url := args[0]
+				url, _ := splitURL(args)
 				output, _ := cmd.Flags().GetString("output")
+				fullPage, _ := cmd.Flags().GetBool("full-page")
+				selector, _ := cmd.Flags().GetString("selector")
+				clip, _ := cmd.Flags().GetString("clip")
+				format, _ := cmd.Flags().GetString("format")
+				quality, _ := cmd.Flags().GetInt("quality")
 
-				fmt.Println("Launching browser...")
-				launchResult, err := browser.Launch(browser.LaunchOptions{Headless: headless})
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error launching browser: %v\n", err)
-					os.Exit(1)
+				// Infer the format from the output extension unless given explicitly
+				if format == "" {
+					format = formatFromExtension(output)
 				}
-				defer waitAndClose(launchResult)
 
-				fmt.Println("Connecting to BiDi...")
-				conn, err := bidi.Connect(launchResult.WebSocketURL)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error connecting: %v\n", err)
-					os.Exit(1)
+				shotOpts := bidi.ScreenshotOptions{
+					FullPage: fullPage,
+					Format:   format,
+					Quality:  quality,
+				}
+				if _, err := bidi.ScreenshotMimeType(format); err != nil {
+					fail("", err)
+				}
+				if clip != "" {
+					rect, err := parseClip(clip)
+					if err != nil {
+						fail("", err)
+					}
+					shotOpts.Clip = rect
 				}
-				defer conn.Close()
 
-				client := bidi.NewClient(conn)
+				client, closePage := openPage(url)
+				defer closePage()
 
-				fmt.Printf("Navigating to %s...\n", url)
-				_, err = client.Navigate("", url)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error navigating: %v\n", err)
-					os.Exit(1)
+				if selector != "" {
+					fmt.Printf("Finding element: %s\n", selector)
+					sharedID, err := client.GetElementSharedID("", selector)
+					if err != nil {
+						fail("finding element", err)
+					}
+					shotOpts.ElementID = sharedID
 				}
 
-				doWaitOpen()
-
 				fmt.Println("Capturing screenshot...")
-				base64Data, err := client.CaptureScreenshot("")
+				base64Data, err := client.CaptureScreenshotWithOptions("", shotOpts)
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error capturing screenshot: %v\n", err)
-					os.Exit(1)
+					fail("capturing screenshot", err)
 				}
 
-				// Decode base64 to PNG bytes
-				pngData, err := base64.StdEncoding.DecodeString(base64Data)
+				// Decode base64 to image bytes
+				imageData, err := base64.StdEncoding.DecodeString(base64Data)
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error decoding screenshot: %v\n", err)
-					os.Exit(1)
+					fail("decoding screenshot", err)
 				}
 
 				// Save to file
-				if err := os.WriteFile(output, pngData, 0644); err != nil {
-					fmt.Fprintf(os.Stderr, "Error saving screenshot: %v\n", err)
-					os.Exit(1)
+				if err := os.WriteFile(output, imageData, 0644); err != nil {
+					fail("saving screenshot", err)
 				}
 
-				fmt.Printf("Screenshot saved to %s (%d bytes)\n", output, len(pngData))
+				fmt.Printf("Screenshot saved to %s (%d bytes)\n", output, len(imageData))
+				emit(fields{"path": output, "bytes": len(imageData), "format": format})
 			})
 		},
 	}
 	screenshotCmd.Flags().StringP("output", "o", "screenshot.png", "Output file path")
+	screenshotCmd.Flags().Bool("full-page", false, "Capture the full scrollable page instead of the viewport")
+	screenshotCmd.Flags().String("selector", "", "Capture only the element matching this CSS selector")
+	screenshotCmd.Flags().String("clip", "", "Capture only the rectangle X,Y,WIDTH,HEIGHT (CSS pixels)")
+	screenshotCmd.Flags().String("format", "", "Image format: png, jpeg or webp (default: from output extension)")
+	screenshotCmd.Flags().Int("quality", 0, "JPEG/WebP quality from 1 to 100")
 	rootCmd.AddCommand(screenshotCmd)
 
-	rootCmd.AddCommand(&cobra.Command{
-		Use:   "eval [url] [expression]",
-		Short: "Navigate to a URL and evaluate a JavaScript expression",
-		Example: `  clicker eval https://example.com "document.title"
-  # Prints: Example Domain`,
-		Args: cobra.ExactArgs(2),
+	pdfCmd := &cobra.Command{
+		Use:   "pdf [url]",
+		Short: "Navigate to a URL and print the page to PDF",
+		Example: `  clicker pdf https://example.com -o page.pdf
+  # Saves the page as page.pdf (US Letter, portrait)
+
+  clicker pdf https://example.com -o invoice.pdf --paper A4 --margin 1.5 --background
+  # A4 with 1.5cm margins and background graphics
+
+  clicker pdf https://example.com -o summary.pdf --landscape --scale 0.8 --pages 1-2
+  # First two pages in landscape at 80% scale`,
+		Annotations: sessionCommand,
+		Args:        pageArgs(0, false),
 		Run: func(cmd *cobra.Command, args []string) {
 			process.WithCleanup(func() {
-				url := args[0]
-				expression := args[1]
+				url, _ := splitURL(args)
+				output, _ := cmd.Flags().GetString("output")
+				paper, _ := cmd.Flags().GetString("paper")
+				width, _ := cmd.Flags().GetFloat64("width")
+				height, _ := cmd.Flags().GetFloat64("height")
+				margin, _ := cmd.Flags().GetString("margin")
+				landscape, _ := cmd.Flags().GetBool("landscape")
+				scale, _ := cmd.Flags().GetFloat64("scale")
+				background, _ := cmd.Flags().GetBool("background")
+				pages, _ := cmd.Flags().GetString("pages")
+
+				pdfOpts := bidi.PDFOptions{
+					PageWidth:  width,
+					PageHeight: height,
+					Landscape:  landscape,
+					Scale:      scale,
+					Background: background,
+					PageRanges: pages,
+				}
+				if paper != "" {
+					w, h, err := bidi.PaperSize(paper)
+					if err != nil {
+						fail("", err)
+					}
+					pdfOpts.PageWidth, pdfOpts.PageHeight = w, h
+				}
+				if margin != "" {
+					m, err := parseMargin(margin)
+					if err != nil {
+						fail("", err)
+					}
+					pdfOpts.Margin = m
+				}
+				if pages != "" {
+					if _, err := bidi.ParsePageRanges(pages); err != nil {
+						fail("", err)
+					}
+				}
 
-				fmt.Println("Launching browser...")
-				launchResult, err := browser.Launch(browser.LaunchOptions{Headless: headless})
+				client, closePage := openPage(url)
+				defer closePage()
+
+				fmt.Println("Printing to PDF...")
+				base64Data, err := client.PrintToPDF("", pdfOpts)
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error launching browser: %v\n", err)
-					os.Exit(1)
+					fail("printing to PDF", err)
 				}
-				defer waitAndClose(launchResult)
 
-				fmt.Println("Connecting to BiDi...")
-				conn, err := bidi.Connect(launchResult.WebSocketURL)
+				// Decode base64 to PDF bytes
+				pdfData, err := base64.StdEncoding.DecodeString(base64Data)
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error connecting: %v\n", err)
-					os.Exit(1)
+					fail("decoding PDF", err)
 				}
-				defer conn.Close()
-
-				client := bidi.NewClient(conn)
 
-				fmt.Printf("Navigating to %s...\n", url)
-				_, err = client.Navigate("", url)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error navigating: %v\n", err)
-					os.Exit(1)
+				// Save to file
+				if err := os.WriteFile(output, pdfData, 0644); err != nil {
+					fail("saving PDF", err)
 				}
 
-				doWaitOpen()
+				fmt.Printf("PDF saved to %s (%d bytes)\n", output, len(pdfData))
+				emit(fields{"path": output, "bytes": len(pdfData)})
+			})
+		},
+	}
+	pdfCmd.Flags().StringP("output", "o", "page.pdf", "Output file path")
+	pdfCmd.Flags().String("paper", "", "Paper size: Letter, Legal, Tabloid, Ledger or A0-A6")
+	pdfCmd.Flags().Float64("width", 0, "Page width in centimeters (overridden by --paper)")
+	pdfCmd.Flags().Float64("height", 0, "Page height in centimeters (overridden by --paper)")
+	pdfCmd.Flags().String("margin", "", "Margins in centimeters: CM or TOP,RIGHT,BOTTOM,LEFT")
+	pdfCmd.Flags().Bool("landscape", false, "Print in landscape orientation")
+	pdfCmd.Flags().Float64("scale", 0, "Page scale from 0.1 to 2 (default 1)")
+	pdfCmd.Flags().Bool("background", false, "Print background graphics")
+	pdfCmd.Flags().String("pages", "", "Page ranges to print, e.g. 1-3,5")
+	rootCmd.AddCommand(pdfCmd)
+
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "eval [url] [expression]",
+		Short: "Navigate to a URL and evaluate a JavaScript expression",
+		Example: `  clicker eval https://example.com "document.title"
+  # Prints: Example Domain
+
+  clicker eval --session work "location.href"
+  # Evaluates in the page of a running daemon (see 'clicker daemon')`,
+		Annotations: sessionCommand,
+		Args:        pageArgs(1, false),
+		Run: func(cmd *cobra.Command, args []string) {
+			process.WithCleanup(func() {
+				url, args := splitURL(args)
+				expression := args[0]
+
+				client, closePage := openPage(url)
+				defer closePage()
 
 				fmt.Printf("Evaluating: %s\n", expression)
 				result, err := client.Evaluate("", expression)
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error evaluating: %v\n", err)
-					os.Exit(1)
+					fail("evaluating", err)
 				}
 
 				fmt.Printf("Result: %v\n", result)
+				emit(fields{"value": result})
 			})
 		},
 	})
@@ -397,48 +1017,54 @@ func main() {
 		Short: "Navigate to a URL and find an element by CSS selector",
 		Example: `  clicker find https://example.com "a"
   # Prints: tag=A, text="Learn more", box={x,y,w,h}`,
-		Args: cobra.ExactArgs(2),
+		Annotations: sessionCommand,
+		Args:        pageArgs(1, false),
 		Run: func(cmd *cobra.Command, args []string) {
 			process.WithCleanup(func() {
-				url := args[0]
-				selector := args[1]
+				url, args := splitURL(args)
+				selector := args[0]
 
-				fmt.Println("Launching browser...")
-				launchResult, err := browser.Launch(browser.LaunchOptions{Headless: headless})
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error launching browser: %v\n", err)
-					os.Exit(1)
-				}
-				defer waitAndClose(launchResult)
+				client, closePage := openPage(url)
+				defer closePage()
 
-				fmt.Println("Connecting to BiDi...")
-				conn, err := bidi.Connect(launchResult.WebSocketURL)
+				fmt.Printf("Finding element: %s\n", selector)
+				info, err := client.FindElement("", selector)
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error connecting: %v\n", err)
-					os.Exit(1)
+					fail("finding element", err)
 				}
-				defer conn.Close()
 
-				client := bidi.NewClient(conn)
+				fmt.Printf("Found: tag=%s, text=\"%s\", box={x:%.0f, y:%.0f, w:%.0f, h:%.0f}\n",
+					info.Tag, info.Text, info.Box.X, info.Box.Y, info.Box.Width, info.Box.Height)
+				emit(fields{"tag": info.Tag, "text": info.Text, "box": info.Box})
+			})
+		},
+	})
 
-				fmt.Printf("Navigating to %s...\n", url)
-				_, err = client.Navigate("", url)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error navigating: %v\n", err)
-					os.Exit(1)
-				}
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "tabs [url]",
+		Short: "Navigate to a URL and list open tabs and windows",
+		Example: `  clicker tabs https://example.com
+  # Prints: [0] <context-id> https://example.com/
+  # Popups opened by the page show up as additional entries`,
+		Annotations: sessionCommand,
+		Args:        pageArgs(0, false),
+		Run: func(cmd *cobra.Command, args []string) {
+			process.WithCleanup(func() {
+				url, _ := splitURL(args)
 
-				doWaitOpen()
+				client, closePage := openPage(url)
+				defer closePage()
 
-				fmt.Printf("Finding element: %s\n", selector)
-				info, err := client.FindElement("", selector)
+				contexts, err := client.ListContexts()
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error finding element: %v\n", err)
-					os.Exit(1)
+					fail("listing tabs", err)
 				}
 
-				fmt.Printf("Found: tag=%s, text=\"%s\", box={x:%.0f, y:%.0f, w:%.0f, h:%.0f}\n",
-					info.Tag, info.Text, info.Box.X, info.Box.Y, info.Box.Width, info.Box.Height)
+				fmt.Printf("Open tabs: %d\n", len(contexts))
+				for i, ctx := range contexts {
+					fmt.Printf("  [%d] %s %s\n", i, ctx.Context, ctx.URL)
+				}
+				emit(fields{"tabs": contexts})
 			})
 		},
 	})
@@ -451,54 +1077,75 @@ func main() {
   # Then clicks the link and navigates to the target page
 
   clicker click https://example.com "a" --timeout 5s
-  # Custom timeout for actionability checks`,
-		Args: cobra.ExactArgs(2),
+  # Custom timeout for actionability checks
+
+  clicker click https://example.com/reports "#export-csv" --download --download-dir ./out
+  # Waits for the download the click starts and prints where it was saved
+
+  clicker click https://example.com "a" --button right
+  # Right-click (also: middle)
+
+  clicker click https://example.com "a" --modifiers Control --count 2 --position 5,5
+  # Ctrl+double-click 5px from the element's top-left corner
+
+  clicker click --session work "a"
+  # Clicks in the current page of a running daemon (see 'clicker daemon')`,
+		Annotations: sessionCommand,
+		Args:        pageArgs(1, false),
 		Run: func(cmd *cobra.Command, args []string) {
 			process.WithCleanup(func() {
-				url := args[0]
-				selector := args[1]
+				url, args := splitURL(args)
+				selector := args[0]
 				timeout, _ := cmd.Flags().GetDuration("timeout")
-
-				fmt.Println("Launching browser...")
-				launchResult, err := browser.Launch(browser.LaunchOptions{Headless: headless})
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error launching browser: %v\n", err)
-					os.Exit(1)
+				waitDownload, _ := cmd.Flags().GetBool("download")
+				button, _ := cmd.Flags().GetString("button")
+				count, _ := cmd.Flags().GetInt("count")
+				modifiers, _ := cmd.Flags().GetString("modifiers")
+				position, _ := cmd.Flags().GetString("position")
+				delay, _ := cmd.Flags().GetDuration("delay")
+
+				clickOpts := bidi.ClickOptions{
+					Button:     button,
+					ClickCount: count,
+					Modifiers:  parseModifiers(modifiers),
+					Delay:      delay,
 				}
-				defer waitAndClose(launchResult)
-
-				fmt.Println("Connecting to BiDi...")
-				conn, err := bidi.Connect(launchResult.WebSocketURL)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error connecting: %v\n", err)
-					os.Exit(1)
+				if position != "" {
+					p, err := parsePoint(position)
+					if err != nil {
+						fail("", err)
+					}
+					clickOpts.Position = p
 				}
-				defer conn.Close()
-
-				client := bidi.NewClient(conn)
-
-				fmt.Printf("Navigating to %s...\n", url)
-				_, err = client.Navigate("", url)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error navigating: %v\n", err)
-					os.Exit(1)
+				// Validate before launching the browser
+				if _, err := bidi.ClickActions(0, 0, clickOpts); err != nil {
+					fail("", err)
 				}
 
-				doWaitOpen()
+				client, closePage := openPage(url)
+				defer closePage()
 
 				// Wait for element to be actionable (Visible, Stable, ReceivesEvents, Enabled)
 				fmt.Printf("Waiting for element to be actionable: %s\n", selector)
 				opts := features.WaitOptions{Timeout: timeout}
 				if err := features.WaitForClick(client, "", selector, opts); err != nil {
-					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-					os.Exit(1)
+					fail("", err)
 				}
 
 				fmt.Printf("Clicking element: %s\n", selector)
-				err = client.ClickElement("", selector)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error clicking: %v\n", err)
-					os.Exit(1)
+				if err := client.ClickElementWithOptions("", selector, clickOpts); err != nil {
+					fail("clicking", err)
+				}
+
+				if waitDownload {
+					fmt.Println("Waiting for download...")
+					download, err := client.WaitForDownload("", timeout)
+					if err != nil {
+						fail("waiting for download", err)
+					}
+					fmt.Printf("Download complete! Saved %s to %s (%d bytes)\n", download.SuggestedFilename, download.Path, download.Size)
+					emit(fields{"selector": selector, "download": download})
+					return
 				}
 
 				// TODO: Replace sleep with proper navigation wait (poll URL change or listen for BiDi events)
@@ -508,15 +1155,21 @@ func main() {
 				// Get current URL after click
 				currentURL, err := client.GetCurrentURL()
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error getting URL: %v\n", err)
-					os.Exit(1)
+					fail("getting URL", err)
 				}
 
 				fmt.Printf("Click complete! Current URL: %s\n", currentURL)
+				emit(fields{"selector": selector, "url": currentURL})
 			})
 		},
 	}
 	clickCmd.Flags().Duration("timeout", features.DefaultTimeout, "Timeout for actionability checks (e.g., 5s, 30s)")
+	clickCmd.Flags().Bool("download", false, "Wait for the download started by the click (timeout also applies)")
+	clickCmd.Flags().String("button", "left", "Mouse button: left, middle or right")
+	clickCmd.Flags().Int("count", 1, "Number of clicks (2 = double-click)")
+	clickCmd.Flags().String("modifiers", "", "Modifier keys to hold, e.g. Shift or Control+Alt")
+	clickCmd.Flags().String("position", "", "Click position X,Y relative to the element's top-left corner (default: center)")
+	clickCmd.Flags().Duration("delay", 0, "Time to hold the button down (e.g., 100ms)")
 	rootCmd.AddCommand(clickCmd)
 
 	typeCmd := &cobra.Command{
@@ -527,121 +1180,585 @@ func main() {
   # Then types "12345" into the input
 
   clicker type https://the-internet.herokuapp.com/inputs "input" "12345" --timeout 5s
-  # Custom timeout for actionability checks`,
-		Args: cobra.ExactArgs(3),
+  # Custom timeout for actionability checks
+
+  clicker type --session work "input" "12345"
+  # Types into the current page of a running daemon (see 'clicker daemon')`,
+		Annotations: sessionCommand,
+		Args:        pageArgs(2, false),
 		Run: func(cmd *cobra.Command, args []string) {
 			process.WithCleanup(func() {
-				url := args[0]
-				selector := args[1]
-				text := args[2]
+				url, args := splitURL(args)
+				selector := args[0]
+				text := args[1]
 				timeout, _ := cmd.Flags().GetDuration("timeout")
 
-				fmt.Println("Launching browser...")
-				launchResult, err := browser.Launch(browser.LaunchOptions{Headless: headless})
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error launching browser: %v\n", err)
-					os.Exit(1)
+				client, closePage := openPage(url)
+				defer closePage()
+
+				// Wait for element to be actionable (Visible, Stable, ReceivesEvents, Enabled, Editable)
+				fmt.Printf("Waiting for element to be actionable: %s\n", selector)
+				opts := features.WaitOptions{Timeout: timeout}
+				if err := features.WaitForType(client, "", selector, opts); err != nil {
+					fail("", err)
 				}
-				defer waitAndClose(launchResult)
 
-				fmt.Println("Connecting to BiDi...")
-				conn, err := bidi.Connect(launchResult.WebSocketURL)
+				fmt.Printf("Typing into element: %s\n", selector)
+				if err := client.TypeIntoElement("", selector, text); err != nil {
+					fail("typing", err)
+				}
+
+				// Get the resulting value
+				value, err := client.GetElementValue("", selector)
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error connecting: %v\n", err)
-					os.Exit(1)
+					fail("getting value", err)
 				}
-				defer conn.Close()
 
-				client := bidi.NewClient(conn)
+				fmt.Printf("Typed \"%s\", value is now: %s\n", text, value)
+				emit(fields{"selector": selector, "value": value})
+			})
+		},
+	}
+	typeCmd.Flags().Duration("timeout", features.DefaultTimeout, "Timeout for actionability checks (e.g., 5s, 30s)")
+	rootCmd.AddCommand(typeCmd)
 
-				fmt.Printf("Navigating to %s...\n", url)
-				_, err = client.Navigate("", url)
+	fillCmd := &cobra.Command{
+		Use:   "fill [url] [selector] [value]",
+		Short: "Navigate to a URL and replace the content of a text field",
+		Example: `  clicker fill https://the-internet.herokuapp.com/login "#username" tomsmith
+  # Clears the field, types the value and fires change
+
+  clicker fill https://example.com/form "#notes" ""
+  # Empties the field`,
+		Annotations: sessionCommand,
+		Args:        pageArgs(2, false),
+		Run: func(cmd *cobra.Command, args []string) {
+			process.WithCleanup(func() {
+				url, args := splitURL(args)
+				selector := args[0]
+				value := args[1]
+				timeout, _ := cmd.Flags().GetDuration("timeout")
+
+				client, closePage := openPage(url)
+				defer closePage()
+
+				fmt.Printf("Waiting for element to be actionable: %s\n", selector)
+				opts := features.WaitOptions{Timeout: timeout}
+				if err := features.WaitForType(client, "", selector, opts); err != nil {
+					fail("", err)
+				}
+
+				fmt.Printf("Filling element: %s\n", selector)
+				if err := client.Fill("", selector, value); err != nil {
+					fail("filling", err)
+				}
+
+				result, err := client.GetElementValue("", selector)
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error navigating: %v\n", err)
-					os.Exit(1)
+					fail("getting value", err)
 				}
 
-				doWaitOpen()
+				fmt.Printf("Value is now: %s\n", result)
+				emit(fields{"selector": selector, "value": result})
+			})
+		},
+	}
+	fillCmd.Flags().Duration("timeout", features.DefaultTimeout, "Timeout for actionability checks (e.g., 5s, 30s)")
+	rootCmd.AddCommand(fillCmd)
+
+	selectCmd := &cobra.Command{
+		Use:   "select [url] [selector] [options...]",
+		Short: "Navigate to a URL and select options of a <select>",
+		Example: `  clicker select https://the-internet.herokuapp.com/dropdown "#dropdown" 2
+  # Selects the option with value "2"
+
+  clicker select https://the-internet.herokuapp.com/dropdown "#dropdown" "Option 1" --by label
+  # Selects by visible text (also: --by index)`,
+		Annotations: sessionCommand,
+		Args:        pageArgs(2, true),
+		Run: func(cmd *cobra.Command, args []string) {
+			process.WithCleanup(func() {
+				url, args := splitURL(args)
+				selector := args[0]
+				by, _ := cmd.Flags().GetString("by")
+				timeout, _ := cmd.Flags().GetDuration("timeout")
+
+				var sel bidi.SelectBy
+				switch by {
+				case "value":
+					sel.Values = args[1:]
+				case "label":
+					sel.Labels = args[1:]
+				case "index":
+					for _, a := range args[1:] {
+						n, err := strconv.Atoi(a)
+						if err != nil {
+							fail("", errs.InvalidArgument("invalid index %q", a))
+						}
+						sel.Indexes = append(sel.Indexes, n)
+					}
+				default:
+					fail("", errs.InvalidArgument("unknown --by %q (expected value, label or index)", by))
+				}
+
+				client, closePage := openPage(url)
+				defer closePage()
 
-				// Wait for element to be actionable (Visible, Stable, ReceivesEvents, Enabled, Editable)
 				fmt.Printf("Waiting for element to be actionable: %s\n", selector)
 				opts := features.WaitOptions{Timeout: timeout}
-				if err := features.WaitForType(client, "", selector, opts); err != nil {
-					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-					os.Exit(1)
+				if err := features.WaitForSelect(client, "", selector, opts); err != nil {
+					fail("", err)
 				}
 
-				fmt.Printf("Typing into element: %s\n", selector)
-				err = client.TypeIntoElement("", selector, text)
+				selected, err := client.SelectOption("", selector, sel)
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error typing: %v\n", err)
-					os.Exit(1)
+					fail("selecting", err)
 				}
 
-				// Get the resulting value
-				value, err := client.GetElementValue("", selector)
+				fmt.Printf("Selected: %s\n", strings.Join(selected, ", "))
+				emit(fields{"selector": selector, "selected": selected})
+			})
+		},
+	}
+	selectCmd.Flags().String("by", "value", "Match options by value, label or index")
+	selectCmd.Flags().Duration("timeout", features.DefaultTimeout, "Timeout for actionability checks (e.g., 5s, 30s)")
+	rootCmd.AddCommand(selectCmd)
+
+	checkCmd := &cobra.Command{
+		Use:   "check [url] [selector]",
+		Short: "Navigate to a URL and check or uncheck a checkbox or radio button",
+		Example: `  clicker check https://the-internet.herokuapp.com/checkboxes "#checkboxes input"
+  # Checks the first checkbox (no-op if already checked)
+
+  clicker check https://the-internet.herokuapp.com/checkboxes "#checkboxes input:last-child" --uncheck`,
+		Annotations: sessionCommand,
+		Args:        pageArgs(1, false),
+		Run: func(cmd *cobra.Command, args []string) {
+			process.WithCleanup(func() {
+				url, args := splitURL(args)
+				selector := args[0]
+				uncheck, _ := cmd.Flags().GetBool("uncheck")
+				timeout, _ := cmd.Flags().GetDuration("timeout")
+
+				client, closePage := openPage(url)
+				defer closePage()
+
+				fmt.Printf("Waiting for element to be actionable: %s\n", selector)
+				opts := features.WaitOptions{Timeout: timeout}
+				if err := features.WaitForClick(client, "", selector, opts); err != nil {
+					fail("", err)
+				}
+
+				if err := client.SetChecked("", selector, !uncheck); err != nil {
+					fail("", err)
+				}
+
+				checked, err := client.IsChecked("", selector)
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error getting value: %v\n", err)
-					os.Exit(1)
+					fail("reading state", err)
 				}
 
-				fmt.Printf("Typed \"%s\", value is now: %s\n", text, value)
+				fmt.Printf("Checked: %v\n", checked)
+				emit(fields{"selector": selector, "checked": checked})
 			})
 		},
 	}
-	typeCmd.Flags().Duration("timeout", features.DefaultTimeout, "Timeout for actionability checks (e.g., 5s, 30s)")
-	rootCmd.AddCommand(typeCmd)
+	checkCmd.Flags().Bool("uncheck", false, "Uncheck instead of check")
+	checkCmd.Flags().Duration("timeout", features.DefaultTimeout, "Timeout for actionability checks (e.g., 5s, 30s)")
+	rootCmd.AddCommand(checkCmd)
+
+	hoverCmd := &cobra.Command{
+		Use:   "hover [url] [selector]",
+		Short: "Navigate to a URL and move the mouse over an element",
+		Example: `  clicker hover https://the-internet.herokuapp.com/hovers ".figure"
+  # Waits for the element to be visible, stable and receive events, then hovers it`,
+		Annotations: sessionCommand,
+		Args:        pageArgs(1, false),
+		Run: func(cmd *cobra.Command, args []string) {
+			process.WithCleanup(func() {
+				url, args := splitURL(args)
+				selector := args[0]
+				timeout, _ := cmd.Flags().GetDuration("timeout")
 
-	rootCmd.AddCommand(&cobra.Command{
-		Use:   "check-actionable [url] [selector]",
-		Short: "Check actionability of an element (Visible, Stable, ReceivesEvents, Enabled, Editable)",
-		Example: `  clicker check-actionable https://example.com "a"
-  # Output:
-  # Checking actionability for selector: a
-  # ✓ Visible: true
-  # ✓ Stable: true
-  # ✓ ReceivesEvents: true
-  # ✓ Enabled: true
-  # ✗ Editable: false`,
-		Args: cobra.ExactArgs(2),
+				client, closePage := openPage(url)
+				defer closePage()
+
+				fmt.Printf("Waiting for element to be actionable: %s\n", selector)
+				opts := features.WaitOptions{Timeout: timeout}
+				if err := features.WaitForHover(client, "", selector, opts); err != nil {
+					fail("", err)
+				}
+
+				if err := client.Hover("", selector); err != nil {
+					fail("hovering", err)
+				}
+
+				fmt.Printf("Hovering over element: %s\n", selector)
+				emit(fields{"selector": selector})
+			})
+		},
+	}
+	hoverCmd.Flags().Duration("timeout", features.DefaultTimeout, "Timeout for actionability checks (e.g., 5s, 30s)")
+	rootCmd.AddCommand(hoverCmd)
+
+	dragCmd := &cobra.Command{
+		Use:   "drag [url] [source] [target]",
+		Short: "Navigate to a URL and drag one element onto another",
+		Example: `  clicker drag https://the-internet.herokuapp.com/drag_and_drop "#column-a" "#column-b"
+  # Presses on #column-a, moves to #column-b in steps and releases`,
+		Annotations: sessionCommand,
+		Args:        pageArgs(2, false),
 		Run: func(cmd *cobra.Command, args []string) {
 			process.WithCleanup(func() {
-				url := args[0]
-				selector := args[1]
+				url, args := splitURL(args)
+				source := args[0]
+				target := args[1]
+				timeout, _ := cmd.Flags().GetDuration("timeout")
 
-				fmt.Println("Launching browser...")
-				launchResult, err := browser.Launch(browser.LaunchOptions{Headless: headless})
+				client, closePage := openPage(url)
+				defer closePage()
+
+				opts := features.WaitOptions{Timeout: timeout}
+				for _, selector := range []string{source, target} {
+					fmt.Printf("Waiting for element to be actionable: %s\n", selector)
+					if err := features.WaitForHover(client, "", selector, opts); err != nil {
+						fail("", err)
+					}
+				}
+
+				fmt.Printf("Dragging %s onto %s\n", source, target)
+				if err := client.DragAndDrop("", source, target); err != nil {
+					fail("dragging", err)
+				}
+
+				fmt.Println("Drag complete!")
+				emit(fields{"source": source, "target": target})
+			})
+		},
+	}
+	dragCmd.Flags().Duration("timeout", features.DefaultTimeout, "Timeout for actionability checks (e.g., 5s, 30s)")
+	rootCmd.AddCommand(dragCmd)
+
+	pressCmd := &cobra.Command{
+		Use:   "press [url] [keys...]",
+		Short: "Navigate to a URL and press keys or key chords",
+		Example: `  clicker press https://example.com "Control+A" "Control+C"
+  # Presses each chord in turn
+
+  clicker press https://the-internet.herokuapp.com/key_presses Enter --selector "#target"
+  # Focuses #target first, then presses Enter
+
+Key names: Enter, Tab, Escape, Backspace, Delete, Space, ArrowUp/Down/Left/Right,
+Home, End, PageUp, PageDown, F1-F12, Shift, Control, Alt, Meta (aliases: Ctrl,
+Cmd, Option, Esc) or any single character. Use "+" to combine, e.g. Meta+Shift+K.`,
+		Annotations: sessionCommand,
+		Args:        pageArgs(1, true),
+		Run: func(cmd *cobra.Command, args []string) {
+			process.WithCleanup(func() {
+				url, args := splitURL(args)
+				chords := args
+				selector, _ := cmd.Flags().GetString("selector")
+				timeout, _ := cmd.Flags().GetDuration("timeout")
+
+				// Validate before launching the browser
+				for _, chord := range chords {
+					if _, err := bidi.ParseChord(chord); err != nil {
+						fail("", err)
+					}
+				}
+
+				client, closePage := openPage(url)
+				defer closePage()
+
+				if selector != "" {
+					fmt.Printf("Focusing element: %s\n", selector)
+					opts := features.WaitOptions{Timeout: timeout}
+					if err := features.WaitForClick(client, "", selector, opts); err != nil {
+						fail("", err)
+					}
+					if err := client.ClickElement("", selector); err != nil {
+						fail("focusing", err)
+					}
+				}
+
+				for _, chord := range chords {
+					fmt.Printf("Pressing %s\n", chord)
+					if err := client.PressChord("", chord); err != nil {
+						fail("pressing keys", err)
+					}
+				}
+
+				fmt.Println("Keys pressed!")
+				emit(fields{"keys": chords})
+			})
+		},
+	}
+	pressCmd.Flags().String("selector", "", "Click this element first to focus it")
+	pressCmd.Flags().Duration("timeout", features.DefaultTimeout, "Timeout for actionability checks (e.g., 5s, 30s)")
+	rootCmd.AddCommand(pressCmd)
+
+	scrollCmd := &cobra.Command{
+		Use:   "scroll [url]",
+		Short: "Navigate to a URL and scroll with the mouse wheel",
+		Example: `  clicker scroll https://example.com --dy 500
+  # Scrolls the page down 500px
+
+  clicker scroll https://example.com --selector ".list" --dy 200
+  # Scrolls with the pointer over .list`,
+		Annotations: sessionCommand,
+		Args:        pageArgs(0, false),
+		Run: func(cmd *cobra.Command, args []string) {
+			process.WithCleanup(func() {
+				url, _ := splitURL(args)
+				dx, _ := cmd.Flags().GetInt("dx")
+				dy, _ := cmd.Flags().GetInt("dy")
+				selector, _ := cmd.Flags().GetString("selector")
+				timeout, _ := cmd.Flags().GetDuration("timeout")
+
+				client, closePage := openPage(url)
+				defer closePage()
+
+				var err error
+				if selector != "" {
+					opts := features.WaitOptions{Timeout: timeout}
+					if err := features.WaitForHover(client, "", selector, opts); err != nil {
+						fail("", err)
+					}
+					err = client.ScrollElement("", selector, dx, dy)
+				} else {
+					err = client.Scroll("", 0, 0, dx, dy)
+				}
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error launching browser: %v\n", err)
-					os.Exit(1)
+					fail("scrolling", err)
+				}
+
+				// Scrolling is asynchronous; give the page a moment to settle
+				time.Sleep(200 * time.Millisecond)
+
+				pos, err := client.Evaluate("", "`${window.scrollX},${window.scrollY}`")
+				if err != nil {
+					fail("reading scroll position", err)
+				}
+				fmt.Printf("Scrolled! Page position: %v\n", pos)
+				if p, err := parsePoint(fmt.Sprint(pos)); err == nil {
+					emit(fields{"x": p.X, "y": p.Y})
+				} else {
+					emit(fields{"position": pos})
+				}
+			})
+		},
+	}
+	scrollCmd.Flags().Int("dx", 0, "Horizontal scroll in pixels")
+	scrollCmd.Flags().Int("dy", 0, "Vertical scroll in pixels (positive scrolls down)")
+	scrollCmd.Flags().String("selector", "", "Scroll with the pointer over this element")
+	scrollCmd.Flags().Duration("timeout", features.DefaultTimeout, "Timeout for actionability checks (e.g., 5s, 30s)")
+	rootCmd.AddCommand(scrollCmd)
+
+	runCmd := &cobra.Command{
+		Use:   "run [file]",
+		Short: "Run a YAML or JSON script of steps in one browser session",
+		Long: `Run a script of steps in one browser session.
+
+A script is a YAML or JSON file with a list of steps, or an object with
+name, timeout, continueOnError and steps. Each step has one action:
+
+  navigate: URL
+  click: SELECTOR                      (or {selector})
+  type: {selector, text, clear}        (clear replaces the content)
+  press: KEYS                          (or {keys, selector}), e.g. Control+A
+  wait: SELECTOR                       (or {selector} / {url} / {time: 2s})
+  assert: {selector, text, equals, value, visible, url, title, eval}
+  screenshot: PATH                     (or {path, fullPage, selector})
+  eval: EXPRESSION
+
+and optionally name, timeout and continueOnError. Assertions retry until the
+step times out. ${VAR} and ${VAR:-default} are replaced from the environment.
+
+Example:
+
+  name: Login
+  steps:
+    - navigate: https://the-internet.herokuapp.com/login
+    - type: {selector: "#username", text: "${USERNAME:-tomsmith}"}
+    - type: {selector: "#password", text: "${PASSWORD}"}
+    - click: "button[type=submit]"
+    - assert: {selector: ".flash", text: "You logged into"}
+    - screenshot: logged-in.png
+
+The exit status is 1 if any step failed.`,
+		Example: `  clicker run smoke.yaml
+  # Prints PASS/FAIL per step
+
+  clicker run smoke.yaml --report junit --report-file results.xml
+  # Writes a JUnit XML report for CI
+
+  clicker run smoke.json --report json
+  # Prints the JSON report on stdout (progress goes to stderr)`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			process.WithCleanup(func() {
+				path := args[0]
+				timeout, _ := cmd.Flags().GetDuration("timeout")
+				continueOnError, _ := cmd.Flags().GetBool("continue-on-error")
+				reportFormat, _ := cmd.Flags().GetString("report")
+				reportFile, _ := cmd.Flags().GetString("report-file")
+
+				if reportFormat != "" && reportFormat != "json" && reportFormat != "junit" {
+					fail("", errs.InvalidArgument("unknown --report %q (expected junit or json)", reportFormat))
+				}
+				if jsonOutput && reportFormat != "" && reportFile == "" {
+					fail("", errs.InvalidArgument("--json prints the report on stdout; use --report-file to also write a %s report", reportFormat))
+				}
+
+				script, err := runner.Load(path, os.LookupEnv)
+				if err != nil {
+					fail("", err)
+				}
+
+				// Keep stdout clean when the report goes there
+				progress := io.Writer(os.Stdout)
+				if reportFormat != "" && reportFile == "" {
+					progress = os.Stderr
+				}
+
+				fmt.Fprintln(progress, "Launching browser...")
+				opts := launchOptions()
+				launchResult, err := browser.Start(opts)
+				if err != nil {
+					fail("launching browser", err)
 				}
 				defer waitAndClose(launchResult)
 
-				fmt.Println("Connecting to BiDi...")
 				conn, err := bidi.Connect(launchResult.WebSocketURL)
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error connecting: %v\n", err)
-					os.Exit(1)
+					fail("connecting", err)
 				}
 				defer conn.Close()
 
 				client := bidi.NewClient(conn)
+				if err := launchResult.Watch(client); err != nil {
+					fail("watching browser", err)
+				}
+				if err := browser.ApplyEmulation(client, "", opts); err != nil {
+					fail("applying emulation", err)
+				}
+				if err := browser.PrepareSession(client, opts); err != nil {
+					fail("preparing session", err)
+				}
 
-				fmt.Printf("Navigating to %s...\n", url)
-				_, err = client.Navigate("", url)
+				r := runner.New(client, runner.Options{
+					Timeout:         timeout,
+					ContinueOnError: continueOnError,
+					BaseDir:         filepath.Dir(path),
+					Progress:        progress,
+					BeforeNavigate: func(url string) error {
+						return browser.GrantGeolocation(client, opts, url)
+					},
+				})
+				report := r.Run(script)
+				fmt.Fprintf(progress, "\n%s: %s\n", report.Name, report.Summary())
+
+				if reportFormat != "" {
+					if err := writeRunReport(report, reportFormat, reportFile); err != nil {
+						fail("writing report", err)
+					}
+					if reportFile != "" {
+						fmt.Fprintf(progress, "Report saved to %s\n", reportFile)
+					}
+				}
+
+				if !report.OK() {
+					// os.Exit skips deferred calls, so close the browser first
+					conn.Close()
+					waitAndClose(launchResult)
+					if jsonOutput {
+						failWith(fields{"report": report}, "", &errs.StepFailedError{Script: report.Name, Summary: report.Summary()})
+					}
+					os.Exit(errs.CodeStepFailed.ExitCode())
+				}
+				emit(fields{"report": report})
+			})
+		},
+	}
+	runCmd.Flags().Duration("timeout", runner.DefaultTimeout, "Default per-step timeout (the script and steps can override it)")
+	runCmd.Flags().Bool("continue-on-error", false, "Keep running steps after a failure")
+	runCmd.Flags().String("report", "", "Write a report: junit or json")
+	runCmd.Flags().String("report-file", "", "File for the report (default: stdout)")
+	rootCmd.AddCommand(runCmd)
+
+	uploadCmd := &cobra.Command{
+		Use:   "upload [url] [selector] [files...]",
+		Short: "Navigate to a URL and set the files of a file input",
+		Example: `  clicker upload https://the-internet.herokuapp.com/upload "#file-upload" ./report.pdf
+  # Waits for the file input to be enabled, then selects report.pdf
+
+  clicker upload https://example.com/form "input[type=file]" a.png b.png
+  # Selects several files (the input must have the multiple attribute)`,
+		Annotations: sessionCommand,
+		Args:        pageArgs(2, true),
+		Run: func(cmd *cobra.Command, args []string) {
+			process.WithCleanup(func() {
+				url, args := splitURL(args)
+				selector := args[0]
+				files := args[1:]
+				timeout, _ := cmd.Flags().GetDuration("timeout")
+
+				client, closePage := openPage(url)
+				defer closePage()
+
+				// File inputs are often hidden, so only wait for existence and Enabled
+				fmt.Printf("Waiting for file input: %s\n", selector)
+				opts := features.WaitOptions{Timeout: timeout}
+				if err := features.WaitForUpload(client, "", selector, opts); err != nil {
+					fail("", err)
+				}
+
+				fmt.Printf("Setting %d file(s) on %s\n", len(files), selector)
+				if err := client.SetFiles("", selector, files); err != nil {
+					fail("uploading", err)
+				}
+
+				// Read back the selected file names
+				names, err := client.Evaluate("", fmt.Sprintf(
+					`Array.from(document.querySelector(%q)?.files || []).map(f => f.name).join(', ')`, selector))
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error navigating: %v\n", err)
-					os.Exit(1)
+					fail("reading files", err)
 				}
 
-				doWaitOpen()
+				fmt.Printf("Upload complete! Selected files: %v\n", names)
+				emit(fields{"selector": selector, "files": files})
+			})
+		},
+	}
+	uploadCmd.Flags().Duration("timeout", features.DefaultTimeout, "Timeout for actionability checks (e.g., 5s, 30s)")
+	rootCmd.AddCommand(uploadCmd)
+
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "check-actionable [url] [selector]",
+		Short: "Check actionability of an element (Visible, Stable, ReceivesEvents, Enabled, Editable)",
+		Example: `  clicker check-actionable https://example.com "a"
+  # Output:
+  # Checking actionability for selector: a
+  # ✓ Visible: true
+  # ✓ Stable: true
+  # ✓ ReceivesEvents: true
+  # ✓ Enabled: true
+  # ✗ Editable: false`,
+		Annotations: sessionCommand,
+		Args:        pageArgs(1, false),
+		Run: func(cmd *cobra.Command, args []string) {
+			process.WithCleanup(func() {
+				url, args := splitURL(args)
+				selector := args[0]
+
+				client, closePage := openPage(url)
+				defer closePage()
 
 				fmt.Printf("\nChecking actionability for selector: %s\n", selector)
 
 				result, err := features.CheckAll(client, "", selector)
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-					os.Exit(1)
+					fail("", err)
 				}
 
 				// Print results with checkmarks
@@ -650,10 +1767,215 @@ func main() {
 				printCheck("ReceivesEvents", result.ReceivesEvents)
 				printCheck("Enabled", result.Enabled)
 				printCheck("Editable", result.Editable)
+				emit(fields{"selector": selector, "actionability": result})
 			})
 		},
 	})
 
+	replCmd := &cobra.Command{
+		Use:   "repl [url]",
+		Short: "Interactive shell for a browser session",
+		Long: `Interactive shell for a browser session, e.g. to try out selectors.
+
+Launches a browser (or attaches to a daemon with --session), optionally
+navigates to url, and reads commands:
+
+  go <url>                 Navigate the current tab
+  click <selector>         Wait until the element is actionable and click it
+  type <selector> <text>   Click the element and type text
+  find <selector>          Show the tag, text and box of the first match
+  eval <js>                Evaluate a JavaScript expression
+  shot [file]              Save a screenshot
+  tabs                     List open tabs
+  events [on|off]          Show or hide browser events
+  {"method": ...}          Send a raw BiDi command and print the result
+
+Console messages, page loads, tabs, dialogs and downloads are printed as
+they happen. Tab completes command names; Up/Down browse the history, which
+is kept in the cache directory.`,
+		Example: `  clicker repl https://example.com
+  clicker> find h1
+  clicker> click a
+  clicker> eval document.title
+
+  clicker repl --session work
+  # Attaches to a running daemon (see 'clicker daemon')`,
+		Annotations: map[string]string{"session": "attach", "output": "text"},
+		Args:        cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			process.WithCleanup(func() {
+				url := ""
+				if len(args) > 0 {
+					url = args[0]
+				}
+				timeout, _ := cmd.Flags().GetDuration("timeout")
+
+				client, closePage := openPage(url)
+				defer closePage()
+
+				historyFile := ""
+				if cacheDir, err := paths.GetCacheDir(); err == nil && os.MkdirAll(cacheDir, 0755) == nil {
+					historyFile = filepath.Join(cacheDir, "repl_history")
+				}
+
+				if err := repl.New(client, os.Stdin, os.Stdout, historyFile, timeout).Run(); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				}
+			})
+		},
+	}
+	replCmd.Flags().Duration("timeout", 5*time.Second, "Timeout for actionability checks (e.g., 5s, 30s)")
+	rootCmd.AddCommand(replCmd)
+
+	daemonCmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Keep a browser session running in the background",
+		Long: `Keep a browser session running in the background, behind a local Unix socket.
+
+Commands given --session <name> attach to the daemon's browser instead of
+launching one, and leave out the URL argument: they act on the page the
+session is on. The browser keeps running between commands until the daemon
+is stopped. Launch flags (--headless, --device, --dialog, --pace...) are
+taken from 'daemon start'; the session name defaults to "default".`,
+		Example: `  clicker daemon start --session work --headless
+  clicker navigate --session work https://example.com
+  clicker click --session work "a"
+  clicker eval --session work "document.title"
+  clicker screenshot --session work -o page.png
+  clicker daemon stop --session work`,
+		Annotations: sessionCommand,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	daemonStartCmd := &cobra.Command{
+		Use:         "start",
+		Short:       "Launch a browser and keep it running in the background",
+		Annotations: sessionCommand,
+		Args:        cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			name := daemonSessionName()
+			timeout, _ := cmd.Flags().GetDuration("timeout")
+
+			executable, err := os.Executable()
+			if err != nil {
+				fail("", err)
+			}
+
+			// The daemon process launches the browser with the same flags
+			serveArgs := []string{"daemon", "serve", "--session", name}
+			cmd.Root().PersistentFlags().Visit(func(f *pflag.Flag) {
+				if f.Name == "session" || f.Name == "json" {
+					return
+				}
+				// Repeatable flags are passed once per value
+				if sv, ok := f.Value.(pflag.SliceValue); ok {
+					for _, v := range sv.GetSlice() {
+						serveArgs = append(serveArgs, "--"+f.Name+"="+v)
+					}
+					return
+				}
+				serveArgs = append(serveArgs, "--"+f.Name+"="+f.Value.String())
+			})
+
+			fmt.Printf("Starting daemon for session %q...\n", name)
+			status, err := daemon.Start(name, executable, serveArgs, timeout)
+			if err != nil {
+				fail("starting daemon", err)
+			}
+
+			logPath, _ := daemon.LogPath(name)
+			fmt.Printf("Daemon running (pid %d)\n", status.PID)
+			fmt.Printf("  Socket: %s\n", status.Socket)
+			fmt.Printf("  Log: %s\n", logPath)
+			fmt.Printf("Attach with --session %s, e.g. clicker navigate --session %s https://example.com\n", name, name)
+			emit(fields{"session": name, "pid": status.PID, "socket": status.Socket, "log": logPath})
+		},
+	}
+	daemonStartCmd.Flags().Duration("timeout", 60*time.Second, "How long to wait for the browser to start")
+	daemonCmd.AddCommand(daemonStartCmd)
+
+	daemonCmd.AddCommand(&cobra.Command{
+		Use:         "stop",
+		Short:       "Close the session's browser and stop its daemon",
+		Annotations: sessionCommand,
+		Args:        cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			name := daemonSessionName()
+			if err := daemon.Stop(name, 10*time.Second); err != nil {
+				fail("", err)
+			}
+			fmt.Printf("Daemon for session %q stopped\n", name)
+			emit(fields{"session": name})
+		},
+	})
+
+	daemonCmd.AddCommand(&cobra.Command{
+		Use:         "status",
+		Short:       "Show running daemons (or one, with --session)",
+		Annotations: sessionCommand,
+		Args:        cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if session == "" {
+				list, err := daemon.List()
+				if err != nil {
+					fail("", err)
+				}
+				if len(list) == 0 {
+					fmt.Println("No daemons running")
+				}
+				for _, s := range list {
+					fmt.Printf("%-20s pid %-8d up %s\n", s.Name, s.PID, time.Since(s.Started).Round(time.Second))
+				}
+				if list == nil {
+					list = []daemon.Status{}
+				}
+				emit(fields{"sessions": list})
+				return
+			}
+
+			client, status, err := daemon.Attach(session)
+			if err != nil {
+				fail("", err)
+			}
+			defer client.Close()
+
+			fmt.Printf("Session: %s\n", status.Name)
+			fmt.Printf("  PID: %d\n", status.PID)
+			fmt.Printf("  Up: %s (since %s)\n", time.Since(status.Started).Round(time.Second), status.Started.Format(time.RFC3339))
+			fmt.Printf("  Socket: %s\n", status.Socket)
+			fmt.Printf("  Headless: %v\n", status.Options.Headless)
+			fmt.Printf("  Other clients attached: %d\n", status.Clients-1)
+
+			contexts, err := client.ListContexts()
+			if err != nil {
+				fail("listing tabs", err)
+			}
+			fmt.Printf("  Open tabs: %d\n", len(contexts))
+			for i, ctx := range contexts {
+				fmt.Printf("    [%d] %s %s\n", i, ctx.Context, ctx.URL)
+			}
+			emit(fields{"status": status, "tabs": contexts})
+		},
+	})
+
+	daemonCmd.AddCommand(&cobra.Command{
+		Use:         "serve",
+		Short:       "Run a daemon in the foreground (used by 'daemon start')",
+		Hidden:      true,
+		Annotations: sessionCommand,
+		Args:        cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			process.WithCleanup(func() {
+				if err := daemon.Serve(daemonSessionName(), launchOptions()); err != nil {
+					fail("", err)
+				}
+			})
+		},
+	})
+	rootCmd.AddCommand(daemonCmd)
+
 	serveCmd := &cobra.Command{
 		Use:   "serve",
 		Short: "Start WebSocket proxy server for browser automation",
@@ -664,7 +1986,10 @@ func main() {
   # Starts server on port 8080
 
   clicker serve --headless
-  # Starts server with headless browser`,
+  # Starts server with headless browser
+
+  clicker serve --device "Pixel 7"
+  # Every session emulates a Pixel 7`,
 		Run: func(cmd *cobra.Command, args []string) {
 			process.WithCleanup(func() {
 				port, _ := cmd.Flags().GetInt("port")
@@ -672,7 +1997,7 @@ func main() {
 				fmt.Printf("Starting Clicker proxy server on port %d...\n", port)
 
 				// Create router to manage browser sessions
-				router := proxy.NewRouter(headless)
+				router := proxy.NewRouter(launchOptions())
 
 				server := proxy.NewServer(
 					proxy.WithPort(port),
@@ -682,11 +2007,11 @@ func main() {
 				)
 
 				if err := server.Start(); err != nil {
-					fmt.Fprintf(os.Stderr, "Error starting server: %v\n", err)
-					os.Exit(1)
+					fail("starting server", err)
 				}
 
 				fmt.Printf("Server listening on ws://localhost:%d\n", port)
+				emit(fields{"port": port, "url": fmt.Sprintf("ws://localhost:%d", port)})
 				fmt.Println("Press Ctrl+C to stop...")
 
 				// Wait for signal
@@ -716,12 +2041,30 @@ with LLM agents like Claude Code.
 
 The server provides browser automation tools:
   - browser_launch: Start a browser session
+  - browser_set_viewport: Resize the viewport
   - browser_navigate: Go to a URL
+  - browser_back / browser_forward: Move through history
+  - browser_reload: Reload the page
   - browser_click: Click an element
+  - browser_hover: Move the mouse over an element
+  - browser_drag: Drag an element onto another
+  - browser_press: Press a key or key chord
+  - browser_scroll: Scroll with the mouse wheel
   - browser_type: Type into an element
+  - browser_fill: Replace the content of a text field
+  - browser_select: Select options of a <select>
+  - browser_check: Check or uncheck a checkbox or radio button
+  - browser_upload: Set files on a file input
+  - browser_wait_for_download: Wait for a download to finish
   - browser_screenshot: Capture the page
+  - browser_pdf: Print the page to PDF
   - browser_find: Find element info
-  - browser_quit: Close the browser`,
+  - browser_handle_dialog: Accept or dismiss a dialog
+  - browser_quit: Close the browser
+  - browser_tabs_list: List open tabs
+  - browser_tab_new: Open a new tab
+  - browser_tab_switch: Switch the active tab
+  - browser_tab_close: Close a tab`,
 		Example: `  # Run directly (for testing)
   clicker mcp
 
@@ -734,8 +2077,18 @@ The server provides browser automation tools:
   # Disable screenshot file saving (inline only)
   clicker mcp --screenshot-dir ""
 
+  # Allow browser_upload to read files from ./fixtures
+  clicker mcp --upload-dir ./fixtures
+
+  # Save downloads to ./downloads
+  clicker mcp --download-dir ./downloads
+
+  # Survive browser crashes in long sessions
+  clicker mcp --auto-recover --recover-storage
+
   # Test with echo
   echo '{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"capabilities":{}}}' | clicker mcp`,
+		Annotations: textOnly,
 		Run: func(cmd *cobra.Command, args []string) {
 			process.WithCleanup(func() {
 				var screenshotDir string
@@ -754,8 +2107,20 @@ The server provides browser automation tools:
 					}
 				}
 
+				uploadDir, _ := cmd.Flags().GetString("upload-dir")
+				autoRecover, _ := cmd.Flags().GetBool("auto-recover")
+				recoverStorage, _ := cmd.Flags().GetBool("recover-storage")
+				opts := launchOptions()
+
 				server := mcp.NewServer(version, mcp.ServerOptions{
-					ScreenshotDir: screenshotDir,
+					ScreenshotDir:  screenshotDir,
+					UploadDir:      uploadDir,
+					DownloadDir:    downloadDir,
+					AutoRecover:    autoRecover,
+					RecoverStorage: recoverStorage,
+					Browser:        opts.Browser,
+					ConnectURL:     opts.ConnectURL,
+					Capabilities:   opts.Capabilities,
 				})
 				defer server.Close()
 
@@ -767,6 +2132,9 @@ The server provides browser automation tools:
 		},
 	}
 	mcpCmd.Flags().String("screenshot-dir", "", "Directory for saving screenshots (default: ~/Pictures/Vibium, use \"\" to disable)")
+	mcpCmd.Flags().String("upload-dir", "", "Directory browser_upload may read files from (default: uploads disabled)")
+	mcpCmd.Flags().Bool("auto-recover", false, "Relaunch the browser and reopen the last URL when it crashes")
+	mcpCmd.Flags().Bool("recover-storage", false, "Like --auto-recover, and also restore cookies and localStorage")
 	rootCmd.AddCommand(mcpCmd)
 
 	recordCmd := &cobra.Command{
@@ -797,34 +2165,33 @@ Requires FFmpeg to be installed and available in PATH.`,
 
 				// Check FFmpeg availability
 				if !recording.IsFFmpegAvailable() {
-					fmt.Fprintf(os.Stderr, "Error: FFmpeg is not installed or not in PATH\n")
-					fmt.Fprintf(os.Stderr, "Please install FFmpeg: https://ffmpeg.org/download.html\n")
-					os.Exit(1)
+					fail("", fmt.Errorf("FFmpeg is not installed or not in PATH (install it from https://ffmpeg.org/download.html)"))
 				}
 
 				fmt.Println("Launching browser...")
-				launchResult, err := browser.Launch(browser.LaunchOptions{Headless: headless})
+				launchResult, err := browser.Start(launchOptions())
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error launching browser: %v\n", err)
-					os.Exit(1)
+					fail("launching browser", err)
 				}
 				defer waitAndClose(launchResult)
 
 				fmt.Println("Connecting to BiDi...")
 				conn, err := bidi.Connect(launchResult.WebSocketURL)
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error connecting: %v\n", err)
-					os.Exit(1)
+					fail("connecting", err)
 				}
 				defer conn.Close()
 
 				client := bidi.NewClient(conn)
+				if err := launchResult.Watch(client); err != nil {
+					fail("watching browser", err)
+				}
+				applyEmulation(client, url)
 
 				fmt.Printf("Navigating to %s...\n", url)
 				_, err = client.Navigate("", url)
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error navigating: %v\n", err)
-					os.Exit(1)
+					fail("navigating", err)
 				}
 
 				doWaitOpen()
@@ -840,18 +2207,16 @@ Requires FFmpeg to be installed and available in PATH.`,
 					if err != nil {
 						return "", err
 					}
-					
+
 					data, err := client.CaptureScreenshot("")
 					if err != nil {
 						return "", err
 					}
-					
+
 					fmt.Printf("[screenshot] Captured %d bytes\n", len(data))
 					return data, nil
 				}
 
-
-
 				// Create recorder
 				recorder := recording.New(screenshotFn, recording.Options{
 					FPS:        fps,
@@ -861,8 +2226,7 @@ Requires FFmpeg to be installed and available in PATH.`,
 
 				fmt.Printf("Starting recording (duration: %ds, fps: %d, format: %s)...\n", duration, fps, format)
 				if err := recorder.Start(); err != nil {
-					fmt.Fprintf(os.Stderr, "Error starting recording: %v\n", err)
-					os.Exit(1)
+					fail("starting recording", err)
 				}
 
 				// Record for specified duration
@@ -871,11 +2235,11 @@ Requires FFmpeg to be installed and available in PATH.`,
 				fmt.Println("Stopping recording and encoding video...")
 				outputPath, err := recorder.Stop()
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error stopping recording: %v\n", err)
-					os.Exit(1)
+					fail("stopping recording", err)
 				}
 
 				fmt.Printf("Recording saved to: %s\n", outputPath)
+				emit(fields{"path": outputPath})
 			})
 		},
 	}
@@ -888,8 +2252,16 @@ Requires FFmpeg to be installed and available in PATH.`,
 	rootCmd.Version = version
 	rootCmd.SetVersionTemplate("Clicker v{{.Version}}\n")
 
-	if err := rootCmd.Execute(); err != nil {
+	if cmd, err := rootCmd.ExecuteC(); err != nil {
+		// Usage errors are found before PersistentPreRun
+		if commandName == "" {
+			commandName = nameOf(cmd)
+		}
+		err = &errs.InvalidArgumentError{Err: err}
+		if jsonOutput {
+			fail("", err)
+		}
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(errs.CodeOf(err).ExitCode())
 	}
 }
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	errs "github.com/vibium/clicker/internal/errors"
+	"github.com/vibium/clicker/internal/process"
+)
+
+var (
+	// jsonOutput is set by --json.
+	jsonOutput bool
+
+	// stdout is the real standard output. With --json, os.Stdout is pointed
+	// at stderr so progress messages stay out of the JSON document.
+	stdout = os.Stdout
+
+	// commandName is the running command, e.g. "daemon start".
+	commandName string
+)
+
+// textOnly marks commands whose output cannot be JSON (interactive or
+// protocol streams), so --json is rejected for them.
+var textOnly = map[string]string{"output": "text"}
+
+// fields make up the JSON document of a command run with --json.
+type fields map[string]interface{}
+
+// setupOutput prepares output for cmd: with --json, everything printed to
+// stdout goes to stderr and only the final document is written to stdout.
+func setupOutput(cmd *cobra.Command) {
+	commandName = nameOf(cmd)
+	if !jsonOutput {
+		return
+	}
+	if cmd.Annotations["output"] == "text" {
+		fail("", fmt.Errorf("'%s' does not support --json", cmd.CommandPath()))
+	}
+	os.Stdout = os.Stderr
+}
+
+// nameOf returns the name of cmd without the program name, e.g. "daemon start".
+func nameOf(cmd *cobra.Command) string {
+	return strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+}
+
+// emit writes the JSON document of a successful command with --json, e.g.
+// {"ok":true,"command":"eval","value":"Example Domain"}. Without --json it
+// does nothing: commands print their own text.
+func emit(r fields) {
+	if !jsonOutput {
+		return
+	}
+	r["ok"] = true
+	r["command"] = commandName
+	writeJSON(r)
+}
+
+// fail reports err and exits with the status of its code (see
+// errs.Code.ExitCode). what names the step that failed, e.g. "clicking", and
+// may be empty. With --json the error is written to stdout as
+// {"ok":false,"command":...,"error":{"code":...,"message":...,"step":...}}.
+func fail(what string, err error) {
+	failWith(fields{}, what, err)
+}
+
+// failWith is fail with extra fields for the JSON document, e.g. a partial
+// report. The fields are not printed without --json.
+func failWith(r fields, what string, err error) {
+	if jsonOutput {
+		e := map[string]string{"code": string(errs.CodeOf(err)), "message": err.Error()}
+		if what != "" {
+			e["step"] = what
+		}
+		r["ok"] = false
+		r["command"] = commandName
+		r["error"] = e
+		writeJSON(r)
+	} else if what != "" {
+		fmt.Fprintf(os.Stderr, "Error %s: %v\n", what, err)
+	} else {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+
+	// os.Exit skips deferred calls, so don't leave browsers behind
+	process.KillAll()
+	os.Exit(errs.CodeOf(err).ExitCode())
+}
+
+// writeJSON writes one JSON document to the real stdout.
+func writeJSON(v interface{}) {
+	enc := json.NewEncoder(stdout)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing JSON: %v\n", err)
+	}
+}
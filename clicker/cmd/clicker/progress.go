@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/vibium/clicker/internal/browser"
+)
+
+// progressBarWidth is the width of the download bar, in characters.
+const progressBarWidth = 30
+
+// installProgress reports browser.Install progress. With --json it writes
+// one JSON event per line to stderr, e.g.
+// {"event":"progress","stage":"downloading","name":"chrome","bytes":1024,"total":4096};
+// otherwise it prints messages, with a download bar when stdout is a
+// terminal and a line every 10% when it is not (CI logs).
+func installProgress() func(browser.InstallProgress) {
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stderr)
+		return func(p browser.InstallProgress) {
+			enc.Encode(struct {
+				Event string `json:"event"`
+				browser.InstallProgress
+			}{"progress", p})
+		}
+	}
+
+	info, err := os.Stdout.Stat()
+	tty := err == nil && info.Mode()&os.ModeCharDevice != 0
+
+	var started bool
+	var current string // stage and name of the last message
+	var lastStep int64 // last 10% step printed without a terminal
+	var bar bool       // a bar is on the current line
+	endBar := func() {
+		if bar {
+			fmt.Println()
+			bar = false
+		}
+	}
+
+	return func(p browser.InstallProgress) {
+		if !started && p.Stage != browser.StageWaiting {
+			started = true
+			fmt.Printf("Installing Chrome for Testing v%s...\n", p.Version)
+		}
+
+		if key := p.Stage + " " + p.Name; key != current {
+			current = key
+			lastStep = 0
+			endBar()
+			switch p.Stage {
+			case browser.StageWaiting:
+				fmt.Println("Waiting for another install to finish...")
+			case browser.StageDownloading:
+				if p.Resumed > 0 {
+					fmt.Printf("Downloading %s (resuming at %s)...\n", p.Name, formatBytes(p.Resumed))
+				} else {
+					fmt.Printf("Downloading %s...\n", p.Name)
+				}
+			case browser.StageExtracting:
+				fmt.Printf("Extracting %s...\n", p.Name)
+			}
+		}
+
+		if p.Stage != browser.StageDownloading || p.Total <= 0 {
+			return
+		}
+		percent := p.Bytes * 100 / p.Total
+		if tty {
+			filled := int(percent) * progressBarWidth / 100
+			fmt.Printf("\r  [%s%s] %3d%%  %s / %s", strings.Repeat("=", filled), strings.Repeat(" ", progressBarWidth-filled), percent, formatBytes(p.Bytes), formatBytes(p.Total))
+			bar = true
+			if p.Bytes == p.Total {
+				endBar()
+			}
+		} else if step := percent / 10; step > lastStep {
+			lastStep = step
+			fmt.Printf("  %d%%  %s / %s\n", percent, formatBytes(p.Bytes), formatBytes(p.Total))
+		}
+	}
+}
+
+// formatBytes formats a size in MB, e.g. "67.1 MB".
+func formatBytes(n int64) string {
+	return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+}
@@ -2,17 +2,30 @@ package bidi
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	errs "github.com/vibium/clicker/internal/errors"
 )
 
 // BrowsingContextInfo represents a browsing context in the tree.
 type BrowsingContextInfo struct {
-	Context  string                `json:"context"`
-	URL      string                `json:"url"`
-	Children []BrowsingContextInfo `json:"children,omitempty"`
-	Parent   string                `json:"parent,omitempty"`
+	Context        string                `json:"context"`
+	URL            string                `json:"url"`
+	Children       []BrowsingContextInfo `json:"children,omitempty"`
+	Parent         string                `json:"parent,omitempty"`
+	OriginalOpener string                `json:"originalOpener,omitempty"`
 }
 
+// Browsing context types accepted by CreateContext.
+const (
+	ContextTypeTab    = "tab"
+	ContextTypeWindow = "window"
+)
+
 // GetTreeResult represents the result of browsingContext.getTree.
 type GetTreeResult struct {
 	Contexts []BrowsingContextInfo `json:"contexts"`
@@ -33,6 +46,120 @@ func (c *Client) GetTree() (*GetTreeResult, error) {
 	return &result, nil
 }
 
+// ListContexts returns the top-level browsing contexts (tabs and windows).
+func (c *Client) ListContexts() ([]BrowsingContextInfo, error) {
+	msg, err := c.SendCommand("browsingContext.getTree", map[string]interface{}{
+		"maxDepth": 0,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result GetTreeResult
+	if err := json.Unmarshal(msg.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse browsingContext.getTree result: %w", err)
+	}
+
+	return result.Contexts, nil
+}
+
+// CreateContextResult represents the result of browsingContext.create.
+type CreateContextResult struct {
+	Context string `json:"context"`
+}
+
+// CreateContext opens a new tab or window and returns its context ID.
+// contextType must be ContextTypeTab or ContextTypeWindow.
+func (c *Client) CreateContext(contextType string) (string, error) {
+	if contextType == "" {
+		contextType = ContextTypeTab
+	}
+	if contextType != ContextTypeTab && contextType != ContextTypeWindow {
+		return "", errs.InvalidArgument("invalid context type %q (expected %q or %q)", contextType, ContextTypeTab, ContextTypeWindow)
+	}
+
+	msg, err := c.SendCommand("browsingContext.create", map[string]interface{}{
+		"type": contextType,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var result CreateContextResult
+	if err := json.Unmarshal(msg.Result, &result); err != nil {
+		return "", fmt.Errorf("failed to parse browsingContext.create result: %w", err)
+	}
+
+	return result.Context, nil
+}
+
+// CloseContext closes a top-level browsing context.
+func (c *Client) CloseContext(context string) error {
+	if context == "" {
+		return errs.InvalidArgument("context is required")
+	}
+
+	_, err := c.SendCommand("browsingContext.close", map[string]interface{}{
+		"context": context,
+	})
+	return err
+}
+
+// ActivateContext brings a top-level browsing context to the foreground.
+func (c *Client) ActivateContext(context string) error {
+	if context == "" {
+		return errs.InvalidArgument("context is required")
+	}
+
+	_, err := c.SendCommand("browsingContext.activate", map[string]interface{}{
+		"context": context,
+	})
+	return err
+}
+
+// WaitForNewContext runs trigger, e.g. a click on a link with
+// target="_blank", and waits for the new top-level browsing context it
+// opens, such as a popup or a tab. The subscription is made before trigger
+// runs, so a context created while it runs is not missed.
+func (c *Client) WaitForNewContext(timeout time.Duration, trigger func() error) (*BrowsingContextInfo, error) {
+	if err := c.Subscribe("browsingContext.contextCreated"); err != nil {
+		return nil, fmt.Errorf("failed to subscribe to context events: %w", err)
+	}
+
+	// Buffered events of contexts that already exist don't count
+	tree, err := c.GetTree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get browsing contexts: %w", err)
+	}
+	existing := make(map[string]bool)
+	for _, ctx := range tree.Contexts {
+		existing[ctx.Context] = true
+	}
+
+	if err := trigger(); err != nil {
+		return nil, err
+	}
+
+	var info BrowsingContextInfo
+	isNewTopLevel := func(ev *Event) bool {
+		var ctx BrowsingContextInfo
+		if err := json.Unmarshal(ev.Params, &ctx); err != nil {
+			return false
+		}
+		return ctx.Parent == "" && !existing[ctx.Context]
+	}
+
+	ev, err := c.WaitForEvent("browsingContext.contextCreated", timeout, isNewTopLevel)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(ev.Params, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse browsingContext.contextCreated event: %w", err)
+	}
+
+	return &info, nil
+}
+
 // NavigationInfo represents the result of a navigation.
 type NavigationInfo struct {
 	Navigation string `json:"navigation"`
@@ -68,6 +195,11 @@ func (c *Client) Navigate(context, url string) (*NavigateResult, error) {
 
 	msg, err := c.SendCommand("browsingContext.navigate", params)
 	if err != nil {
+		// The browser rejects URLs it cannot load, e.g. net::ERR_NAME_NOT_RESOLVED
+		var protocolErr *errs.ProtocolError
+		if errors.As(err, &protocolErr) {
+			return nil, &errs.NavigationFailedError{URL: url, Cause: err}
+		}
 		return nil, err
 	}
 
@@ -79,6 +211,179 @@ func (c *Client) Navigate(context, url string) (*NavigateResult, error) {
 	return &result, nil
 }
 
+// resolveContext returns context, or the first top-level context if it is empty.
+func (c *Client) resolveContext(context string) (string, error) {
+	if context != "" {
+		return context, nil
+	}
+
+	tree, err := c.GetTree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get browsing context: %w", err)
+	}
+	if len(tree.Contexts) == 0 {
+		return "", fmt.Errorf("no browsing contexts available")
+	}
+	return tree.Contexts[0].Context, nil
+}
+
+// Reload reloads the page in a browsing context and waits for it to load.
+// If ignoreCache is true, cached resources are bypassed.
+// If context is empty, it uses the first available context.
+func (c *Client) Reload(context string, ignoreCache bool) (*NavigateResult, error) {
+	context, err := c.resolveContext(context)
+	if err != nil {
+		return nil, err
+	}
+
+	params := map[string]interface{}{
+		"context": context,
+		"wait":    "complete",
+	}
+	if ignoreCache {
+		params["ignoreCache"] = true
+	}
+
+	msg, err := c.SendCommand("browsingContext.reload", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result NavigateResult
+	if err := json.Unmarshal(msg.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse browsingContext.reload result: %w", err)
+	}
+
+	return &result, nil
+}
+
+// History events tell that a history traversal settled: a new document
+// loaded, or the URL changed within the document.
+const (
+	EventLoad              = "browsingContext.load"
+	EventFragmentNavigated = "browsingContext.fragmentNavigated"
+	EventHistoryUpdated    = "browsingContext.historyUpdated"
+)
+
+// HistoryEvents are the events a history traversal waits for.
+var HistoryEvents = []string{EventLoad, EventFragmentNavigated, EventHistoryUpdated}
+
+// HistorySettleTimeout caps how long a history traversal waits for the page
+// to settle.
+const HistorySettleTimeout = 10 * time.Second
+
+// HistoryFallbackTimeout replaces HistorySettleTimeout on browsers without
+// browsingContext.historyUpdated, where going back to an entry made by
+// pushState sends no event and the wait always runs out.
+const HistoryFallbackTimeout = 2 * time.Second
+
+// TraverseHistory moves delta steps through the session history
+// (negative goes back, positive goes forward) and waits for the page to
+// settle, so the URL read next is the new one.
+// If context is empty, it uses the first available context.
+func (c *Client) TraverseHistory(context string, delta int) error {
+	context, err := c.resolveContext(context)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Subscribe(EventLoad, EventFragmentNavigated); err != nil {
+		return fmt.Errorf("failed to subscribe to navigation events: %w", err)
+	}
+	// Not every browser has historyUpdated yet; don't ask again once it failed
+	settle := HistorySettleTimeout
+	c.eventsMu.Lock()
+	noHistoryUpdated := c.noHistoryUpdated
+	c.eventsMu.Unlock()
+	if !noHistoryUpdated && c.Subscribe(EventHistoryUpdated) != nil {
+		noHistoryUpdated = true
+		c.eventsMu.Lock()
+		c.noHistoryUpdated = true
+		c.eventsMu.Unlock()
+	}
+	if noHistoryUpdated {
+		settle = HistoryFallbackTimeout
+	}
+
+	inContext := func(ev *Event) bool {
+		var p struct {
+			Context string `json:"context"`
+		}
+		return json.Unmarshal(ev.Params, &p) == nil && p.Context == context
+	}
+	// Events of earlier navigations must not end the wait
+	for _, method := range HistoryEvents {
+		for c.takeEvent(method, inContext) != nil {
+		}
+	}
+
+	_, err = c.SendCommand("browsingContext.traverseHistory", map[string]interface{}{
+		"context": context,
+		"delta":   delta,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = c.waitForEvents(HistoryEvents, settle, inContext)
+	var timeout *errs.TimeoutError
+	if errors.As(err, &timeout) {
+		return nil
+	}
+	return err
+}
+
+// Back navigates one step back in the session history.
+func (c *Client) Back(context string) error {
+	return c.TraverseHistory(context, -1)
+}
+
+// Forward navigates one step forward in the session history.
+func (c *Client) Forward(context string) error {
+	return c.TraverseHistory(context, 1)
+}
+
+// Stop stops loading the current page, like the browser's stop button.
+// BiDi has no dedicated command for this, so it calls window.stop().
+func (c *Client) Stop(context string) error {
+	context, err := c.resolveContext(context)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.Evaluate(context, "window.stop()")
+	return err
+}
+
+// SetViewport sets the viewport size and device pixel ratio of a browsing context.
+// A width or height of 0 resets the viewport to the window size; a
+// devicePixelRatio of 0 leaves the pixel ratio unchanged.
+// If context is empty, it uses the first available context.
+func (c *Client) SetViewport(context string, width, height int, devicePixelRatio float64) error {
+	context, err := c.resolveContext(context)
+	if err != nil {
+		return err
+	}
+
+	params := map[string]interface{}{
+		"context": context,
+	}
+	if width > 0 && height > 0 {
+		params["viewport"] = map[string]interface{}{
+			"width":  width,
+			"height": height,
+		}
+	} else {
+		params["viewport"] = nil
+	}
+	if devicePixelRatio > 0 {
+		params["devicePixelRatio"] = devicePixelRatio
+	}
+
+	_, err = c.SendCommand("browsingContext.setViewport", params)
+	return err
+}
+
 // GetCurrentURL returns the URL of the first browsing context.
 func (c *Client) GetCurrentURL() (string, error) {
 	tree, err := c.GetTree()
@@ -93,29 +398,104 @@ func (c *Client) GetCurrentURL() (string, error) {
 
 // CaptureScreenshotResult represents the result of browsingContext.captureScreenshot.
 type CaptureScreenshotResult struct {
-	Data string `json:"data"` // Base64-encoded PNG
+	Data string `json:"data"` // Base64-encoded image
+}
+
+// ClipRectangle is a screenshot clip area in CSS pixels.
+type ClipRectangle struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// ScreenshotOptions configures CaptureScreenshotWithOptions.
+type ScreenshotOptions struct {
+	// FullPage captures the whole document instead of just the viewport.
+	FullPage bool
+	// ElementID clips the screenshot to the element with this sharedId.
+	ElementID string
+	// Clip clips the screenshot to a rectangle. Coordinates are relative to
+	// the viewport, or to the document when FullPage is set.
+	Clip *ClipRectangle
+	// Format is "png" (default), "jpeg" or "webp"; MIME types are accepted too.
+	Format string
+	// Quality is the JPEG/WebP quality from 1 to 100. 0 = browser default.
+	Quality int
+}
+
+// ScreenshotMimeType returns the MIME type for a screenshot format such as
+// "jpeg" or "image/webp". An empty format means PNG.
+func ScreenshotMimeType(format string) (string, error) {
+	switch strings.TrimPrefix(strings.ToLower(format), "image/") {
+	case "", "png":
+		return "image/png", nil
+	case "jpeg", "jpg":
+		return "image/jpeg", nil
+	case "webp":
+		return "image/webp", nil
+	default:
+		return "", errs.InvalidArgument("unsupported screenshot format %q (expected png, jpeg or webp)", format)
+	}
 }
 
 // CaptureScreenshot captures a screenshot of the viewport.
 // If context is empty, it uses the first available context.
 // Returns base64-encoded PNG data.
 func (c *Client) CaptureScreenshot(context string) (string, error) {
-	// If no context provided, get the first one from the tree
-	if context == "" {
-		tree, err := c.GetTree()
-		if err != nil {
-			return "", fmt.Errorf("failed to get browsing context: %w", err)
-		}
-		if len(tree.Contexts) == 0 {
-			return "", fmt.Errorf("no browsing contexts available")
-		}
-		context = tree.Contexts[0].Context
+	return c.CaptureScreenshotWithOptions(context, ScreenshotOptions{})
+}
+
+// CaptureScreenshotWithOptions captures a screenshot of the viewport, the full
+// page, an element or a rectangle, in PNG, JPEG or WebP format.
+// If context is empty, it uses the first available context.
+// Returns base64-encoded image data.
+func (c *Client) CaptureScreenshotWithOptions(context string, opts ScreenshotOptions) (string, error) {
+	context, err := c.resolveContext(context)
+	if err != nil {
+		return "", err
 	}
 
 	params := map[string]interface{}{
 		"context": context,
 	}
 
+	// Elements may be scrolled out of view, so clip them against the document
+	if opts.FullPage || opts.ElementID != "" {
+		params["origin"] = "document"
+	}
+
+	switch {
+	case opts.ElementID != "":
+		params["clip"] = map[string]interface{}{
+			"type":    "element",
+			"element": map[string]interface{}{"sharedId": opts.ElementID},
+		}
+	case opts.Clip != nil:
+		params["clip"] = map[string]interface{}{
+			"type":   "box",
+			"x":      opts.Clip.X,
+			"y":      opts.Clip.Y,
+			"width":  opts.Clip.Width,
+			"height": opts.Clip.Height,
+		}
+	}
+
+	mimeType, err := ScreenshotMimeType(opts.Format)
+	if err != nil {
+		return "", err
+	}
+	if mimeType != "image/png" || opts.Quality > 0 {
+		format := map[string]interface{}{"type": mimeType}
+		if opts.Quality > 0 {
+			if opts.Quality > 100 {
+				return "", errs.InvalidArgument("screenshot quality must be between 1 and 100")
+			}
+			format["quality"] = float64(opts.Quality) / 100
+		}
+		params["format"] = format
+	}
+
 	msg, err := c.SendCommand("browsingContext.captureScreenshot", params)
 	if err != nil {
 		return "", err
@@ -128,3 +508,168 @@ func (c *Client) CaptureScreenshot(context string) (string, error) {
 
 	return result.Data, nil
 }
+
+// PDFMargin holds page margins in centimeters.
+type PDFMargin struct {
+	Top    float64
+	Right  float64
+	Bottom float64
+	Left   float64
+}
+
+// PDFOptions configures PrintToPDF. Lengths are in centimeters.
+type PDFOptions struct {
+	// PageWidth and PageHeight set the paper size. 0 = browser default (US Letter).
+	PageWidth  float64
+	PageHeight float64
+	// Margin sets the page margins. nil = browser default (1cm).
+	Margin *PDFMargin
+	// Landscape prints in landscape orientation.
+	Landscape bool
+	// Scale is the page scale from 0.1 to 2. 0 = 1.
+	Scale float64
+	// Background prints background graphics.
+	Background bool
+	// PageRanges limits the printed pages, e.g. "1-3,5".
+	PageRanges string
+}
+
+// paperSizes maps paper names to their width and height in centimeters.
+var paperSizes = map[string][2]float64{
+	"letter":  {21.59, 27.94},
+	"legal":   {21.59, 35.56},
+	"tabloid": {27.94, 43.18},
+	"ledger":  {43.18, 27.94},
+	"a0":      {84.1, 118.9},
+	"a1":      {59.4, 84.1},
+	"a2":      {42.0, 59.4},
+	"a3":      {29.7, 42.0},
+	"a4":      {21.0, 29.7},
+	"a5":      {14.8, 21.0},
+	"a6":      {10.5, 14.8},
+}
+
+// PaperSize returns the width and height in centimeters of a named paper
+// size such as "A4" or "Letter".
+func PaperSize(name string) (float64, float64, error) {
+	size, ok := paperSizes[strings.ToLower(name)]
+	if !ok {
+		return 0, 0, errs.InvalidArgument("unknown paper size %q (expected Letter, Legal, Tabloid, Ledger or A0-A6)", name)
+	}
+	return size[0], size[1], nil
+}
+
+// ParsePageRanges splits a page range list such as "1-3,5" into the values
+// expected by browsingContext.print.
+func ParsePageRanges(ranges string) ([]interface{}, error) {
+	var result []interface{}
+	for _, part := range strings.Split(ranges, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if n, err := strconv.Atoi(part); err == nil {
+			if n < 1 {
+				return nil, errs.InvalidArgument("invalid page range %q: pages start at 1", part)
+			}
+			result = append(result, n)
+			continue
+		}
+		bounds := strings.SplitN(part, "-", 2)
+		if len(bounds) != 2 {
+			return nil, errs.InvalidArgument("invalid page range %q", part)
+		}
+		for _, b := range bounds {
+			if b = strings.TrimSpace(b); b != "" {
+				if _, err := strconv.Atoi(b); err != nil {
+					return nil, errs.InvalidArgument("invalid page range %q", part)
+				}
+			}
+		}
+		result = append(result, part)
+	}
+	return result, nil
+}
+
+// PrintResult represents the result of browsingContext.print.
+type PrintResult struct {
+	Data string `json:"data"`
+}
+
+// PrintToPDF prints the page to PDF.
+// If context is empty, it uses the first available context.
+// Returns base64-encoded PDF data.
+func (c *Client) PrintToPDF(context string, opts PDFOptions) (string, error) {
+	context, err := c.resolveContext(context)
+	if err != nil {
+		return "", err
+	}
+
+	params, err := PrintParams(context, opts)
+	if err != nil {
+		return "", err
+	}
+
+	msg, err := c.SendCommand("browsingContext.print", params)
+	if err != nil {
+		return "", err
+	}
+
+	var result PrintResult
+	if err := json.Unmarshal(msg.Result, &result); err != nil {
+		return "", fmt.Errorf("failed to parse browsingContext.print result: %w", err)
+	}
+
+	return result.Data, nil
+}
+
+// PrintParams builds browsingContext.print parameters from PDFOptions.
+func PrintParams(context string, opts PDFOptions) (map[string]interface{}, error) {
+	params := map[string]interface{}{
+		"context":    context,
+		"background": opts.Background,
+	}
+
+	if opts.Landscape {
+		params["orientation"] = "landscape"
+	} else {
+		params["orientation"] = "portrait"
+	}
+
+	if opts.Scale != 0 {
+		if opts.Scale < 0.1 || opts.Scale > 2 {
+			return nil, errs.InvalidArgument("pdf scale must be between 0.1 and 2")
+		}
+		params["scale"] = opts.Scale
+	}
+
+	if opts.PageWidth > 0 || opts.PageHeight > 0 {
+		page := map[string]interface{}{}
+		if opts.PageWidth > 0 {
+			page["width"] = opts.PageWidth
+		}
+		if opts.PageHeight > 0 {
+			page["height"] = opts.PageHeight
+		}
+		params["page"] = page
+	}
+
+	if opts.Margin != nil {
+		params["margin"] = map[string]interface{}{
+			"top":    opts.Margin.Top,
+			"right":  opts.Margin.Right,
+			"bottom": opts.Margin.Bottom,
+			"left":   opts.Margin.Left,
+		}
+	}
+
+	if opts.PageRanges != "" {
+		ranges, err := ParsePageRanges(opts.PageRanges)
+		if err != nil {
+			return nil, err
+		}
+		params["pageRanges"] = ranges
+	}
+
+	return params, nil
+}
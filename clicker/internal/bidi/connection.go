@@ -2,7 +2,9 @@ package bidi
 
 import (
 	"fmt"
+	"net"
 	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 	errs "github.com/vibium/clicker/internal/errors"
@@ -10,9 +12,17 @@ import (
 
 // Connection represents a WebSocket connection.
 type Connection struct {
-	conn   *websocket.Conn
-	mu     sync.Mutex
-	closed bool
+	conn     *websocket.Conn
+	mu       sync.Mutex
+	closed   bool
+	incoming chan received
+	done     chan struct{}
+}
+
+// received is a single message (or read error) produced by the read loop.
+type received struct {
+	msg string
+	err error
 }
 
 // Connect establishes a WebSocket connection to the given URL.
@@ -22,9 +32,74 @@ func Connect(url string) (*Connection, error) {
 		return nil, &errs.ConnectionError{URL: url, Cause: err}
 	}
 
-	return &Connection{
-		conn: conn,
-	}, nil
+	return newConnection(conn), nil
+}
+
+// ConnectUnix establishes a WebSocket connection over a Unix domain socket,
+// such as the one a clicker daemon listens on.
+func ConnectUnix(socketPath string) (*Connection, error) {
+	dialer := websocket.Dialer{
+		NetDial: func(network, addr string) (net.Conn, error) {
+			return net.Dial("unix", socketPath)
+		},
+		HandshakeTimeout: 10 * time.Second,
+	}
+
+	conn, _, err := dialer.Dial("ws://localhost/", nil)
+	if err != nil {
+		return nil, &errs.ConnectionError{URL: "unix:" + socketPath, Cause: err}
+	}
+
+	return newConnection(conn), nil
+}
+
+// newConnection wraps an open WebSocket and starts its read loop.
+func newConnection(conn *websocket.Conn) *Connection {
+	c := &Connection{
+		conn:     conn,
+		incoming: make(chan received, 64),
+		done:     make(chan struct{}),
+	}
+	go c.readLoop()
+
+	return c
+}
+
+// readLoop reads messages from the WebSocket and queues them for Receive.
+// A background reader lets callers wait for messages with a timeout without
+// putting the underlying connection into a failed state.
+func (c *Connection) readLoop() {
+	defer close(c.incoming)
+
+	for {
+		msgType, msg, err := c.conn.ReadMessage()
+		if err != nil {
+			c.deliver(received{err: err})
+			return
+		}
+
+		if msgType != websocket.TextMessage {
+			err := fmt.Errorf("expected text message, got type %d", msgType)
+			if !c.deliver(received{err: err}) {
+				return
+			}
+			continue
+		}
+
+		if !c.deliver(received{msg: string(msg)}) {
+			return
+		}
+	}
+}
+
+// deliver queues a message for Receive. Returns false once the connection is closed.
+func (c *Connection) deliver(r received) bool {
+	select {
+	case c.incoming <- r:
+		return true
+	case <-c.done:
+		return false
+	}
 }
 
 // Send sends a text message over the WebSocket.
@@ -42,20 +117,46 @@ func (c *Connection) Send(msg string) error {
 // Receive receives a text message from the WebSocket.
 // Blocks until a message is received.
 func (c *Connection) Receive() (string, error) {
-	if c.closed {
+	if c.isClosed() {
 		return "", fmt.Errorf("connection closed")
 	}
 
-	msgType, msg, err := c.conn.ReadMessage()
-	if err != nil {
-		return "", err
+	r, ok := <-c.incoming
+	if !ok {
+		return "", fmt.Errorf("connection closed")
 	}
+	return r.msg, r.err
+}
 
-	if msgType != websocket.TextMessage {
-		return "", fmt.Errorf("expected text message, got type %d", msgType)
+// ReceiveTimeout is like Receive but gives up after the timeout.
+// Returns ErrReceiveTimeout if no message arrived in time.
+func (c *Connection) ReceiveTimeout(timeout time.Duration) (string, error) {
+	if c.isClosed() {
+		return "", fmt.Errorf("connection closed")
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case r, ok := <-c.incoming:
+		if !ok {
+			return "", fmt.Errorf("connection closed")
+		}
+		return r.msg, r.err
+	case <-timer.C:
+		return "", ErrReceiveTimeout
 	}
+}
 
-	return string(msg), nil
+// ErrReceiveTimeout is returned by ReceiveTimeout when no message arrives in time.
+var ErrReceiveTimeout = fmt.Errorf("timeout waiting for message")
+
+// isClosed reports whether Close has been called.
+func (c *Connection) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
 }
 
 // Close closes the WebSocket connection.
@@ -68,6 +169,7 @@ func (c *Connection) Close() error {
 	}
 
 	c.closed = true
+	close(c.done)
 
 	// Send close message
 	c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
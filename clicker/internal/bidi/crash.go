@@ -0,0 +1,138 @@
+package bidi
+
+import (
+	"encoding/json"
+	"fmt"
+
+	errs "github.com/vibium/clicker/internal/errors"
+)
+
+// EventContextDestroyed is sent when a browsing context goes away: closed by
+// a command, by the user or by the page, or because its renderer crashed.
+const EventContextDestroyed = "browsingContext.contextDestroyed"
+
+// ContextDestroyedParams are the parameters of browsingContext.contextDestroyed.
+type ContextDestroyedParams struct {
+	Context        string `json:"context"`
+	Parent         string `json:"parent,omitempty"`
+	URL            string `json:"url"`
+	OriginalOpener string `json:"originalOpener,omitempty"` // set for popups opened by a page
+}
+
+// SetCrashCheck makes the client report crashes as *errs.BrowserCrashedError.
+// When the connection is lost, check is asked why (see
+// browser.LaunchResult.CrashError); a nil answer keeps the connection error.
+// Commands that fail on a top-level tab destroyed without a
+// browsingContext.close from this client report a crash too: the browser's
+// if check finds one, else the tab's renderer. Popups are the exception, as
+// the page that opened them may close them, which leaves the error as is
+// ("no such frame").
+func (c *Client) SetCrashCheck(check func() error) error {
+	if err := c.Subscribe(EventContextDestroyed); err != nil {
+		return err
+	}
+
+	c.eventsMu.Lock()
+	c.crashCheck = check
+	c.eventsMu.Unlock()
+	return nil
+}
+
+// connError returns the crash that caused a connection failure, or err
+// described by what.
+func (c *Client) connError(what string, err error) error {
+	c.eventsMu.Lock()
+	check := c.crashCheck
+	c.eventsMu.Unlock()
+
+	if check != nil {
+		if crash := check(); crash != nil {
+			return crash
+		}
+	}
+	return fmt.Errorf("%s: %w", what, err)
+}
+
+// commandResult is responseResult for a command sent with params: errors on
+// a page that went away because the browser or its renderer crashed are
+// reported as the crash.
+func (c *Client) commandResult(msg *Message, params interface{}) (*Message, error) {
+	msg, err := responseResult(msg)
+	if err != nil {
+		if context := contextParam(params); context != "" && c.isGone(context) {
+			c.eventsMu.Lock()
+			check := c.crashCheck
+			c.eventsMu.Unlock()
+			if crash := check(); crash != nil {
+				return nil, crash
+			}
+			// The browser is still running, so only the tab's renderer died
+			return nil, &errs.BrowserCrashedError{ExitCode: -1, Reason: "renderer crashed"}
+		}
+	}
+	return msg, err
+}
+
+// trackClose remembers a context this client asked to close, so its
+// contextDestroyed event is not taken for a crash.
+func (c *Client) trackClose(method string, params interface{}) {
+	if method != "browsingContext.close" {
+		return
+	}
+	context := contextParam(params)
+	if context == "" {
+		return
+	}
+
+	c.eventsMu.Lock()
+	defer c.eventsMu.Unlock()
+	if c.closing == nil {
+		c.closing = make(map[string]bool)
+	}
+	c.closing[context] = true
+}
+
+// onContextDestroyed records a top-level context that went away without
+// this client closing it, which means its renderer or the browser crashed.
+// Popups are left out, since their opener may have closed them.
+func (c *Client) onContextDestroyed(p *ContextDestroyedParams) {
+	c.eventsMu.Lock()
+	defer c.eventsMu.Unlock()
+
+	if c.closing[p.Context] {
+		delete(c.closing, p.Context)
+		return
+	}
+	if c.crashCheck == nil || p.Parent != "" || p.OriginalOpener != "" {
+		return
+	}
+	if c.gone == nil {
+		c.gone = make(map[string]bool)
+	}
+	c.gone[p.Context] = true
+}
+
+func (c *Client) isGone(context string) bool {
+	c.eventsMu.Lock()
+	defer c.eventsMu.Unlock()
+	return c.gone[context]
+}
+
+// contextParam returns the "context" parameter of a command, if any.
+func contextParam(params interface{}) string {
+	if m, ok := params.(map[string]interface{}); ok {
+		context, _ := m["context"].(string)
+		return context
+	}
+
+	// Typed parameters
+	data, err := json.Marshal(params)
+	if err != nil {
+		return ""
+	}
+	var p struct {
+		Context string `json:"context"`
+	}
+	json.Unmarshal(data, &p)
+	return p.Context
+}
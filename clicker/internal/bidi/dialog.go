@@ -0,0 +1,284 @@
+package bidi
+
+import (
+	"encoding/json"
+	"strings"
+
+	errs "github.com/vibium/clicker/internal/errors"
+	"github.com/vibium/clicker/internal/log"
+)
+
+// Dialog events.
+const (
+	EventUserPromptOpened = "browsingContext.userPromptOpened"
+	EventUserPromptClosed = "browsingContext.userPromptClosed"
+)
+
+// Dialog policies decide what happens to dialogs nobody handles explicitly.
+const (
+	DialogAccept  = "accept"  // accept alerts, confirms and prompts (with their default text)
+	DialogDismiss = "dismiss" // dismiss them
+	DialogFail    = "fail"    // leave them open and fail the blocked command
+)
+
+// maxRecordedDialogs caps the dialogs kept for TakeDialogs.
+const maxRecordedDialogs = 32
+
+// ParseDialogPolicy validates a dialog policy. An empty policy means dismiss.
+func ParseDialogPolicy(policy string) (string, error) {
+	switch strings.ToLower(policy) {
+	case "", DialogDismiss:
+		return DialogDismiss, nil
+	case DialogAccept:
+		return DialogAccept, nil
+	case DialogFail:
+		return DialogFail, nil
+	default:
+		return "", errs.InvalidArgument("unknown dialog policy %q (expected accept, dismiss or fail)", policy)
+	}
+}
+
+// UserPromptOpenedParams are the parameters of browsingContext.userPromptOpened.
+type UserPromptOpenedParams struct {
+	Context      string `json:"context"`
+	Handler      string `json:"handler"` // how the browser handles it; "ignore" leaves it to us
+	Message      string `json:"message"`
+	Type         string `json:"type"` // "alert", "confirm", "prompt" or "beforeunload"
+	DefaultValue string `json:"defaultValue,omitempty"`
+}
+
+// UserPromptClosedParams are the parameters of browsingContext.userPromptClosed.
+type UserPromptClosedParams struct {
+	Context  string `json:"context"`
+	Accepted bool   `json:"accepted"`
+	Type     string `json:"type"`
+	UserText string `json:"userText,omitempty"`
+}
+
+// Dialog describes a JavaScript dialog the client saw.
+type Dialog struct {
+	Context      string `json:"context"`
+	Type         string `json:"type"`
+	Message      string `json:"message"`
+	DefaultValue string `json:"defaultValue,omitempty"`
+	// Outcome is "accepted", "dismissed", or "open" while the dialog waits.
+	Outcome string `json:"outcome"`
+}
+
+// dialogSafeMethods are commands that still work while a dialog is open.
+var dialogSafeMethods = map[string]bool{
+	"browsingContext.handleUserPrompt": true,
+	"browsingContext.getTree":          true,
+	"browsingContext.create":           true,
+	"browsingContext.activate":         true,
+	"browsingContext.close":            true,
+	"session.subscribe":                true,
+	"session.unsubscribe":              true,
+	"session.status":                   true,
+	"session.end":                      true,
+	"browser.close":                    true,
+}
+
+// SetDialogPolicy sets how the client handles dialogs that open while it
+// runs commands (see DialogAccept, DialogDismiss and DialogFail) and
+// subscribes to dialog events. The browser must have been launched with
+// unhandledPromptBehavior "ignore" so dialogs are left to the client.
+func (c *Client) SetDialogPolicy(policy string) error {
+	policy, err := ParseDialogPolicy(policy)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Subscribe(EventUserPromptOpened, EventUserPromptClosed); err != nil {
+		return err
+	}
+
+	c.eventsMu.Lock()
+	c.dialogPolicy = policy
+	c.eventsMu.Unlock()
+	return nil
+}
+
+// DialogPolicy returns the current dialog policy, or "" if dialogs are not
+// handled by the client.
+func (c *Client) DialogPolicy() string {
+	c.eventsMu.Lock()
+	defer c.eventsMu.Unlock()
+	return c.dialogPolicy
+}
+
+// OpenDialog returns the dialog currently blocking the page under the fail
+// policy, or nil.
+func (c *Client) OpenDialog() *Dialog {
+	c.eventsMu.Lock()
+	defer c.eventsMu.Unlock()
+
+	if c.openDialog == nil {
+		return nil
+	}
+	d := *c.openDialog
+	return &d
+}
+
+// TakeDialogs returns the dialogs seen since the last call and forgets them.
+func (c *Client) TakeDialogs() []Dialog {
+	c.eventsMu.Lock()
+	defer c.eventsMu.Unlock()
+
+	dialogs := c.dialogs
+	c.dialogs = nil
+	return dialogs
+}
+
+// HandleUserPrompt accepts or dismisses the open dialog. text is typed into
+// prompt() dialogs when accepting; empty keeps the default value.
+// If context is empty, it uses the context of the open dialog, or the first
+// available context.
+func (c *Client) HandleUserPrompt(context string, accept bool, text string) error {
+	if context == "" {
+		if d := c.OpenDialog(); d != nil {
+			context = d.Context
+		}
+	}
+	context, err := c.resolveContext(context)
+	if err != nil {
+		return err
+	}
+
+	params := map[string]interface{}{
+		"context": context,
+		"accept":  accept,
+	}
+	if text != "" {
+		params["userText"] = text
+	}
+
+	_, err = c.SendCommand("browsingContext.handleUserPrompt", params)
+
+	// The dialog is gone either way if the browser says there is none
+	if err == nil || strings.Contains(err.Error(), "no such alert") {
+		outcome := "dismissed"
+		if accept {
+			outcome = "accepted"
+		}
+		c.eventsMu.Lock()
+		if c.openDialog != nil && c.openDialog.Context == context {
+			c.openDialog = nil
+		}
+		c.markDialogs(context, outcome)
+		c.eventsMu.Unlock()
+	}
+	return err
+}
+
+// checkOpenDialog fails commands that an open dialog would block forever.
+func (c *Client) checkOpenDialog(method string) error {
+	if dialogSafeMethods[method] {
+		return nil
+	}
+
+	c.eventsMu.Lock()
+	defer c.eventsMu.Unlock()
+
+	if d := c.openDialog; d != nil {
+		return &errs.UnexpectedDialogError{Type: d.Type, Message: d.Message, Context: d.Context}
+	}
+	return nil
+}
+
+// observeEvent applies the dialog policy to dialog events and keeps track of
+// destroyed contexts. It returns an UnexpectedDialogError when a dialog opens
+// under the fail policy, and no other error.
+func (c *Client) observeEvent(msg *Message) error {
+	switch msg.Method {
+	case EventUserPromptOpened:
+		var p UserPromptOpenedParams
+		if err := json.Unmarshal(msg.Params, &p); err != nil {
+			return nil
+		}
+		return c.onDialogOpened(&p)
+
+	case EventUserPromptClosed:
+		var p UserPromptClosedParams
+		if err := json.Unmarshal(msg.Params, &p); err != nil {
+			return nil
+		}
+		outcome := "dismissed"
+		if p.Accepted {
+			outcome = "accepted"
+		}
+		c.eventsMu.Lock()
+		if c.openDialog != nil && c.openDialog.Context == p.Context {
+			c.openDialog = nil
+		}
+		c.markDialogs(p.Context, outcome)
+		c.eventsMu.Unlock()
+
+	case EventContextDestroyed:
+		var p ContextDestroyedParams
+		if err := json.Unmarshal(msg.Params, &p); err != nil {
+			return nil
+		}
+		c.onContextDestroyed(&p)
+	}
+	return nil
+}
+
+// onDialogOpened records a dialog and handles it according to the policy.
+func (c *Client) onDialogOpened(p *UserPromptOpenedParams) error {
+	c.eventsMu.Lock()
+	policy := c.dialogPolicy
+	c.eventsMu.Unlock()
+
+	d := Dialog{
+		Context:      p.Context,
+		Type:         p.Type,
+		Message:      p.Message,
+		DefaultValue: p.DefaultValue,
+		Outcome:      "open",
+	}
+
+	// The browser handles it itself, or nobody asked the client to
+	if (p.Handler != "" && p.Handler != "ignore") || policy == "" {
+		c.recordDialog(d)
+		return nil
+	}
+
+	if policy == DialogFail {
+		c.eventsMu.Lock()
+		c.openDialog = &d
+		c.eventsMu.Unlock()
+		c.recordDialog(d)
+		return &errs.UnexpectedDialogError{Type: d.Type, Message: d.Message, Context: d.Context}
+	}
+
+	c.recordDialog(d)
+
+	// This runs inside some other command, which must not fail for it. The
+	// dialog may already be gone, handled by the page or another client.
+	if err := c.HandleUserPrompt(p.Context, policy == DialogAccept, ""); err != nil && !strings.Contains(err.Error(), "no such alert") {
+		log.Warn("failed to handle dialog", "context", p.Context, "type", p.Type, "error", err)
+	}
+	return nil
+}
+
+// recordDialog keeps d for TakeDialogs.
+func (c *Client) recordDialog(d Dialog) {
+	c.eventsMu.Lock()
+	defer c.eventsMu.Unlock()
+
+	c.dialogs = append(c.dialogs, d)
+	if len(c.dialogs) > maxRecordedDialogs {
+		c.dialogs = c.dialogs[len(c.dialogs)-maxRecordedDialogs:]
+	}
+}
+
+// markDialogs sets the outcome of open dialogs in context.
+// The caller must hold eventsMu.
+func (c *Client) markDialogs(context, outcome string) {
+	for i := range c.dialogs {
+		if c.dialogs[i].Context == context && c.dialogs[i].Outcome == "open" {
+			c.dialogs[i].Outcome = outcome
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package bidi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Download events.
+const (
+	EventDownloadWillBegin = "browsingContext.downloadWillBegin"
+	EventDownloadEnd       = "browsingContext.downloadEnd"
+)
+
+// DownloadWillBeginParams are the parameters of browsingContext.downloadWillBegin.
+type DownloadWillBeginParams struct {
+	Context           string `json:"context"`
+	Navigation        string `json:"navigation"`
+	URL               string `json:"url"`
+	SuggestedFilename string `json:"suggestedFilename"`
+}
+
+// DownloadEndParams are the parameters of browsingContext.downloadEnd.
+type DownloadEndParams struct {
+	Context    string `json:"context"`
+	Navigation string `json:"navigation"`
+	URL        string `json:"url"`
+	Status     string `json:"status"` // "complete" or "canceled"
+	Filepath   string `json:"filepath,omitempty"`
+}
+
+// Download describes a finished download.
+type Download struct {
+	Context           string `json:"context"`
+	URL               string `json:"url"`
+	SuggestedFilename string `json:"suggestedFilename"`
+	Path              string `json:"path"`
+	Size              int64  `json:"size"`
+}
+
+// CompleteDownload builds a Download from a downloadEnd event and the
+// filename suggested when it began. Browsers that do not report the saved
+// path fall back to dir/suggestedFilename.
+// Returns an error if the download was canceled or the file is missing.
+func CompleteDownload(end *DownloadEndParams, suggestedFilename, dir string) (*Download, error) {
+	if end.Status != "complete" {
+		return nil, fmt.Errorf("download of %s was %s", end.URL, end.Status)
+	}
+
+	path := end.Filepath
+	if path == "" && dir != "" && suggestedFilename != "" {
+		path = filepath.Join(dir, filepath.Base(suggestedFilename))
+	}
+	if path == "" {
+		return nil, fmt.Errorf("download of %s finished but the browser did not report where it was saved (set a download directory)", end.URL)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("downloaded file not found: %w", err)
+	}
+
+	return &Download{
+		Context:           end.Context,
+		URL:               end.URL,
+		SuggestedFilename: suggestedFilename,
+		Path:              path,
+		Size:              info.Size(),
+	}, nil
+}
+
+// SetDownloadDir makes the browser save downloads to dir without prompting
+// and subscribes to download events, so WaitForDownload sees downloads that
+// start before it is called. dir must be an absolute path.
+func (c *Client) SetDownloadDir(dir string) error {
+	c.eventsMu.Lock()
+	c.downloadDir = dir
+	c.eventsMu.Unlock()
+
+	if err := c.Subscribe(EventDownloadWillBegin, EventDownloadEnd); err != nil {
+		return err
+	}
+
+	_, err := c.SendCommand("browser.setDownloadBehavior", map[string]interface{}{
+		"downloadBehavior": map[string]interface{}{
+			"type":              "allowed",
+			"destinationFolder": dir,
+		},
+	})
+	return err
+}
+
+// WaitForDownload waits for the next download to finish and returns where it
+// was saved. If context is non-empty, only downloads started by that context
+// count. Downloads that began while earlier commands ran are considered first.
+func (c *Client) WaitForDownload(context string, timeout time.Duration) (*Download, error) {
+	if err := c.Subscribe(EventDownloadWillBegin, EventDownloadEnd); err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+
+	ev, err := c.WaitForEvent(EventDownloadWillBegin, timeout, func(ev *Event) bool {
+		var p DownloadWillBeginParams
+		return json.Unmarshal(ev.Params, &p) == nil && (context == "" || p.Context == context)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var begin DownloadWillBeginParams
+	if err := json.Unmarshal(ev.Params, &begin); err != nil {
+		return nil, fmt.Errorf("failed to parse %s event: %w", EventDownloadWillBegin, err)
+	}
+
+	ev, err = c.WaitForEvent(EventDownloadEnd, time.Until(deadline), func(ev *Event) bool {
+		var p DownloadEndParams
+		return json.Unmarshal(ev.Params, &p) == nil && p.Navigation == begin.Navigation
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var end DownloadEndParams
+	if err := json.Unmarshal(ev.Params, &end); err != nil {
+		return nil, fmt.Errorf("failed to parse %s event: %w", EventDownloadEnd, err)
+	}
+
+	c.eventsMu.Lock()
+	dir := c.downloadDir
+	c.eventsMu.Unlock()
+
+	return CompleteDownload(&end, begin.SuggestedFilename, dir)
+}
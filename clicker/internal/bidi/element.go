@@ -110,6 +110,58 @@ func (c *Client) FindElement(context, selector string) (*ElementInfo, error) {
 	return &info, nil
 }
 
+// GetElementSharedID finds an element by CSS selector and returns its BiDi
+// sharedId, which commands like input.setFiles and screenshot clipping use
+// to reference the node.
+// If context is empty, it uses the first available context.
+func (c *Client) GetElementSharedID(context, selector string) (string, error) {
+	context, err := c.resolveContext(context)
+	if err != nil {
+		return "", err
+	}
+
+	params := map[string]interface{}{
+		"functionDeclaration": `(selector) => document.querySelector(selector)`,
+		"target":              map[string]interface{}{"context": context},
+		"arguments": []map[string]interface{}{
+			{"type": "string", "value": selector},
+		},
+		"awaitPromise":    false,
+		"resultOwnership": "root",
+	}
+
+	msg, err := c.SendCommand("script.callFunction", params)
+	if err != nil {
+		return "", err
+	}
+
+	var callResult struct {
+		Type   string          `json:"type"`
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(msg.Result, &callResult); err != nil {
+		return "", fmt.Errorf("failed to parse script.callFunction result: %w", err)
+	}
+
+	if callResult.Type == "exception" {
+		return "", fmt.Errorf("script exception: %s", string(callResult.Result))
+	}
+
+	var node struct {
+		Type     string `json:"type"`
+		SharedID string `json:"sharedId"`
+	}
+	if err := json.Unmarshal(callResult.Result, &node); err != nil {
+		return "", fmt.Errorf("failed to parse remote value: %w", err)
+	}
+
+	if node.Type != "node" || node.SharedID == "" {
+		return "", &errs.ElementNotFoundError{Selector: selector, Context: context}
+	}
+
+	return node.SharedID, nil
+}
+
 // GetElementCenter returns the center coordinates of an element's bounding box.
 func (info *ElementInfo) GetCenter() (float64, float64) {
 	return info.Box.X + info.Box.Width/2, info.Box.Y + info.Box.Height/2
@@ -0,0 +1,109 @@
+package bidi
+
+import (
+	"fmt"
+)
+
+// GeolocationCoordinates is a position reported to the page by the Geolocation API.
+type GeolocationCoordinates struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Accuracy  float64 `json:"accuracy,omitempty"` // meters, 0 = browser default
+}
+
+// SetGeolocationOverride overrides the position reported by the Geolocation API.
+// Passing nil coordinates removes the override.
+// If context is empty, it uses the first available context.
+func (c *Client) SetGeolocationOverride(context string, coords *GeolocationCoordinates) error {
+	context, err := c.resolveContext(context)
+	if err != nil {
+		return err
+	}
+
+	params := map[string]interface{}{
+		"contexts":    []string{context},
+		"coordinates": nil,
+	}
+	if coords != nil {
+		params["coordinates"] = coords
+	}
+
+	_, err = c.SendCommand("emulation.setGeolocationOverride", params)
+	return err
+}
+
+// SetLocaleOverride overrides the locale seen by the page (Intl, navigator.language).
+// An empty locale removes the override.
+// If context is empty, it uses the first available context.
+func (c *Client) SetLocaleOverride(context, locale string) error {
+	return c.setStringOverride("emulation.setLocaleOverride", "locale", context, locale)
+}
+
+// SetTimezoneOverride overrides the page's timezone, e.g. "Europe/Berlin".
+// An empty timezone removes the override.
+// If context is empty, it uses the first available context.
+func (c *Client) SetTimezoneOverride(context, timezone string) error {
+	return c.setStringOverride("emulation.setTimezoneOverride", "timezone", context, timezone)
+}
+
+// SetUserAgentOverride overrides the user agent sent and reported by the page.
+// An empty user agent removes the override.
+// If context is empty, it uses the first available context.
+func (c *Client) SetUserAgentOverride(context, userAgent string) error {
+	return c.setStringOverride("emulation.setUserAgentOverride", "userAgent", context, userAgent)
+}
+
+// SetTouchOverride makes the page see a touch screen with maxTouchPoints
+// (navigator.maxTouchPoints). 0 removes the override.
+// If context is empty, it uses the first available context.
+func (c *Client) SetTouchOverride(context string, maxTouchPoints int) error {
+	context, err := c.resolveContext(context)
+	if err != nil {
+		return err
+	}
+
+	params := map[string]interface{}{
+		"contexts":       []string{context},
+		"maxTouchPoints": nil,
+	}
+	if maxTouchPoints > 0 {
+		params["maxTouchPoints"] = maxTouchPoints
+	}
+
+	if _, err := c.SendCommand("emulation.setTouchOverride", params); err != nil {
+		return fmt.Errorf("emulation.setTouchOverride failed: %w", err)
+	}
+	return nil
+}
+
+// setStringOverride sends an emulation command that takes a single nullable string.
+func (c *Client) setStringOverride(method, key, context, value string) error {
+	context, err := c.resolveContext(context)
+	if err != nil {
+		return err
+	}
+
+	params := map[string]interface{}{
+		"contexts": []string{context},
+		key:        nil,
+	}
+	if value != "" {
+		params[key] = value
+	}
+
+	if _, err := c.SendCommand(method, params); err != nil {
+		return fmt.Errorf("%s failed: %w", method, err)
+	}
+	return nil
+}
+
+// SetPermission sets the state ("granted", "denied" or "prompt") of a
+// permission such as "geolocation" for an origin.
+func (c *Client) SetPermission(origin, name, state string) error {
+	_, err := c.SendCommand("permissions.setPermission", map[string]interface{}{
+		"descriptor": map[string]interface{}{"name": name},
+		"state":      state,
+		"origin":     origin,
+	})
+	return err
+}
@@ -0,0 +1,324 @@
+package bidi
+
+import (
+	"encoding/json"
+	"fmt"
+
+	errs "github.com/vibium/clicker/internal/errors"
+)
+
+// SelectBy picks the <option>s of a <select>. An option is selected if it
+// matches any entry of any list.
+type SelectBy struct {
+	Values  []string `json:"values,omitempty"`  // option values
+	Labels  []string `json:"labels,omitempty"`  // visible option text
+	Indexes []int    `json:"indexes,omitempty"` // zero-based option positions
+}
+
+// FormResult is what the form scripts report back.
+type FormResult struct {
+	Error    string   `json:"error,omitempty"`
+	Value    string   `json:"value,omitempty"`
+	Selected []string `json:"selected,omitempty"`
+	Kind     string   `json:"kind,omitempty"` // "checkbox", "radio" or "role" (for role=checkbox/switch)
+	Checked  bool     `json:"checked,omitempty"`
+}
+
+// clearScript empties an input, textarea or contenteditable element. It sets
+// the value through the native setter so frameworks that track the value
+// (such as React) see the change, then dispatches input (and change, if commit).
+const clearScript = `
+	(selector, commit) => {
+		const el = document.querySelector(selector);
+		if (!el) return JSON.stringify({ error: 'not found' });
+
+		el.focus();
+		if (el.isContentEditable) {
+			el.textContent = '';
+		} else if (el instanceof HTMLInputElement || el instanceof HTMLTextAreaElement) {
+			const proto = el instanceof HTMLInputElement ? HTMLInputElement.prototype : HTMLTextAreaElement.prototype;
+			Object.getOwnPropertyDescriptor(proto, 'value').set.call(el, '');
+		} else {
+			return JSON.stringify({ error: 'is not an <input>, <textarea> or contenteditable element' });
+		}
+
+		el.dispatchEvent(new InputEvent('input', { bubbles: true, inputType: 'deleteContentBackward' }));
+		if (commit) el.dispatchEvent(new Event('change', { bubbles: true }));
+		return JSON.stringify({ value: '' });
+	}
+`
+
+// changeScript dispatches change on an element, as leaving a field would.
+const changeScript = `
+	(selector) => {
+		const el = document.querySelector(selector);
+		if (!el) return JSON.stringify({ error: 'not found' });
+
+		el.dispatchEvent(new Event('change', { bubbles: true }));
+		return JSON.stringify({ value: el.isContentEditable ? el.textContent : String(el.value ?? '') });
+	}
+`
+
+// selectScript selects the options of a <select> matching a SelectBy and
+// dispatches input and change.
+const selectScript = `
+	(selector, by) => {
+		by = JSON.parse(by);
+		const el = document.querySelector(selector);
+		if (!el) return JSON.stringify({ error: 'not found' });
+		if (!(el instanceof HTMLSelectElement)) return JSON.stringify({ error: 'is not a <select>' });
+
+		const options = Array.from(el.options);
+		const matched = new Set();
+		const missing = [];
+		const find = (wanted, matches, kind) => {
+			for (const want of wanted || []) {
+				const i = options.findIndex((o, index) => matches(o, index, want));
+				if (i < 0) missing.push(kind + ' ' + JSON.stringify(want));
+				else matched.add(i);
+			}
+		};
+		find(by.values, (o, i, v) => o.value === v, 'value');
+		find(by.labels, (o, i, l) => o.label === l || o.text.trim() === l, 'label');
+		find(by.indexes, (o, i, n) => i === n, 'index');
+
+		if (missing.length) return JSON.stringify({ error: 'has no option with ' + missing.join(', ') });
+		if (!el.multiple && matched.size > 1) return JSON.stringify({ error: 'allows only one selected option' });
+		for (const i of matched) {
+			if (options[i].disabled) return JSON.stringify({ error: 'has option ' + JSON.stringify(options[i].value) + ' disabled' });
+		}
+
+		options.forEach((o, i) => { o.selected = matched.has(i); });
+		el.dispatchEvent(new Event('input', { bubbles: true }));
+		el.dispatchEvent(new Event('change', { bubbles: true }));
+		return JSON.stringify({ selected: options.filter(o => o.selected).map(o => o.value) });
+	}
+`
+
+// checkedStateScript reports whether a checkbox, radio button or ARIA
+// checkbox/switch is checked.
+const checkedStateScript = `
+	(selector) => {
+		const el = document.querySelector(selector);
+		if (!el) return JSON.stringify({ error: 'not found' });
+
+		if (el instanceof HTMLInputElement && (el.type === 'checkbox' || el.type === 'radio')) {
+			return JSON.stringify({ kind: el.type, checked: el.checked });
+		}
+		const role = el.getAttribute('role');
+		if (['checkbox', 'switch', 'radio', 'menuitemcheckbox'].includes(role)) {
+			return JSON.stringify({ kind: role === 'radio' ? 'radio' : 'role', checked: el.getAttribute('aria-checked') === 'true' });
+		}
+		return JSON.stringify({ error: 'is not a checkbox or radio button' });
+	}
+`
+
+// formScriptParams builds script.callFunction params for a form script.
+func formScriptParams(context, script, selector string, args ...map[string]interface{}) map[string]interface{} {
+	arguments := append([]map[string]interface{}{
+		{"type": "string", "value": selector},
+	}, args...)
+
+	return map[string]interface{}{
+		"functionDeclaration": script,
+		"target":              map[string]interface{}{"context": context},
+		"arguments":           arguments,
+		"awaitPromise":        false,
+		"resultOwnership":     "root",
+	}
+}
+
+// ClearParams returns script.callFunction params that empty a text field.
+// commit also dispatches change, as if the user left the field.
+func ClearParams(context, selector string, commit bool) map[string]interface{} {
+	return formScriptParams(context, clearScript, selector,
+		map[string]interface{}{"type": "boolean", "value": commit})
+}
+
+// ChangeEventParams returns script.callFunction params that dispatch change
+// on an element.
+func ChangeEventParams(context, selector string) map[string]interface{} {
+	return formScriptParams(context, changeScript, selector)
+}
+
+// SelectOptionParams returns script.callFunction params that select the
+// options of a <select> matching by.
+func SelectOptionParams(context, selector string, by SelectBy) (map[string]interface{}, error) {
+	if len(by.Values) == 0 && len(by.Labels) == 0 && len(by.Indexes) == 0 {
+		return nil, fmt.Errorf("no options to select (give values, labels or indexes)")
+	}
+
+	data, err := json.Marshal(by)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal options: %w", err)
+	}
+
+	return formScriptParams(context, selectScript, selector,
+		map[string]interface{}{"type": "string", "value": string(data)}), nil
+}
+
+// CheckedStateParams returns script.callFunction params that read the
+// checked state of a checkbox or radio button.
+func CheckedStateParams(context, selector string) map[string]interface{} {
+	return formScriptParams(context, checkedStateScript, selector)
+}
+
+// ParseFormResult parses the result of script.callFunction with one of the
+// form scripts. A script that failed on the element returns an error naming it.
+func ParseFormResult(result json.RawMessage, context, selector string) (*FormResult, error) {
+	var callResult struct {
+		Type   string          `json:"type"`
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(result, &callResult); err != nil {
+		return nil, fmt.Errorf("failed to parse script.callFunction result: %w", err)
+	}
+
+	if callResult.Type == "exception" {
+		return nil, fmt.Errorf("script exception: %s", string(callResult.Result))
+	}
+
+	var remoteValue struct {
+		Type  string `json:"type"`
+		Value string `json:"value,omitempty"`
+	}
+	if err := json.Unmarshal(callResult.Result, &remoteValue); err != nil {
+		return nil, fmt.Errorf("failed to parse remote value: %w", err)
+	}
+
+	var data FormResult
+	if err := json.Unmarshal([]byte(remoteValue.Value), &data); err != nil {
+		return nil, fmt.Errorf("failed to parse form result: %w", err)
+	}
+
+	switch data.Error {
+	case "":
+		return &data, nil
+	case "not found":
+		return nil, &errs.ElementNotFoundError{Selector: selector, Context: context}
+	default:
+		return nil, fmt.Errorf("element %s %s", selector, data.Error)
+	}
+}
+
+// callFormScript runs a form script built by one of the *Params functions.
+func (c *Client) callFormScript(params map[string]interface{}, context, selector string) (*FormResult, error) {
+	msg, err := c.SendCommand("script.callFunction", params)
+	if err != nil {
+		return nil, err
+	}
+	return ParseFormResult(msg.Result, context, selector)
+}
+
+// Clear empties an <input>, <textarea> or contenteditable element and
+// dispatches input and change.
+// If context is empty, it uses the first available context.
+func (c *Client) Clear(context, selector string) error {
+	context, err := c.resolveContext(context)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.callFormScript(ClearParams(context, selector, true), context, selector)
+	return err
+}
+
+// Fill replaces the content of a text field: it clicks the field to focus
+// it, clears it, types value and dispatches change.
+// If context is empty, it uses the first available context.
+func (c *Client) Fill(context, selector, value string) error {
+	context, err := c.resolveContext(context)
+	if err != nil {
+		return err
+	}
+
+	if err := c.ClickElement(context, selector); err != nil {
+		return fmt.Errorf("failed to click element: %w", err)
+	}
+
+	if _, err := c.callFormScript(ClearParams(context, selector, value == ""), context, selector); err != nil {
+		return err
+	}
+	if value == "" {
+		return nil
+	}
+
+	if err := c.TypeText(context, value); err != nil {
+		return err
+	}
+
+	_, err = c.callFormScript(ChangeEventParams(context, selector), context, selector)
+	return err
+}
+
+// SelectOption selects the options of a <select> matching by, deselecting
+// the others, and dispatches input and change. It returns the values of the
+// selected options.
+// If context is empty, it uses the first available context.
+func (c *Client) SelectOption(context, selector string, by SelectBy) ([]string, error) {
+	context, err := c.resolveContext(context)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := SelectOptionParams(context, selector, by)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.callFormScript(params, context, selector)
+	if err != nil {
+		return nil, err
+	}
+	return result.Selected, nil
+}
+
+// IsChecked reports whether a checkbox or radio button is checked.
+// If context is empty, it uses the first available context.
+func (c *Client) IsChecked(context, selector string) (bool, error) {
+	context, err := c.resolveContext(context)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := c.callFormScript(CheckedStateParams(context, selector), context, selector)
+	if err != nil {
+		return false, err
+	}
+	return result.Checked, nil
+}
+
+// SetChecked checks or unchecks a checkbox or radio button by clicking it,
+// so the page sees the same click, input and change events as from a user.
+// It does nothing if the element is already in the wanted state.
+// If context is empty, it uses the first available context.
+func (c *Client) SetChecked(context, selector string, checked bool) error {
+	context, err := c.resolveContext(context)
+	if err != nil {
+		return err
+	}
+
+	state, err := c.callFormScript(CheckedStateParams(context, selector), context, selector)
+	if err != nil {
+		return err
+	}
+	if state.Checked == checked {
+		return nil
+	}
+	if state.Kind == "radio" && !checked {
+		return fmt.Errorf("cannot uncheck radio button %s (check another option instead)", selector)
+	}
+
+	if err := c.ClickElement(context, selector); err != nil {
+		return fmt.Errorf("failed to click element: %w", err)
+	}
+
+	state, err = c.callFormScript(CheckedStateParams(context, selector), context, selector)
+	if err != nil {
+		return err
+	}
+	if state.Checked != checked {
+		return fmt.Errorf("clicking %s did not change its checked state", selector)
+	}
+	return nil
+}
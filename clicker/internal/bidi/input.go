@@ -2,6 +2,12 @@ package bidi
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	errs "github.com/vibium/clicker/internal/errors"
 )
 
 // PerformActions executes a sequence of input actions.
@@ -28,7 +34,12 @@ func (c *Client) PerformActions(context string, actions []map[string]interface{}
 }
 
 // Click performs a mouse click at the specified coordinates.
+// With pacing enabled (see SetPacing), the pointer travels there first.
 func (c *Client) Click(context string, x, y float64) error {
+	if c.Pacer() != nil {
+		return c.ClickWithOptions(context, x, y, ClickOptions{})
+	}
+
 	actions := []map[string]interface{}{
 		{
 			"type": "pointer",
@@ -71,6 +82,10 @@ func (c *Client) ClickElement(context, selector string) error {
 
 // DoubleClick performs a double-click at the specified coordinates.
 func (c *Client) DoubleClick(context string, x, y float64) error {
+	if c.Pacer() != nil {
+		return c.ClickWithOptions(context, x, y, ClickOptions{ClickCount: 2})
+	}
+
 	actions := []map[string]interface{}{
 		{
 			"type": "pointer",
@@ -110,6 +125,10 @@ func (c *Client) DoubleClick(context string, x, y float64) error {
 
 // MoveMouse moves the mouse to the specified coordinates.
 func (c *Client) MoveMouse(context string, x, y float64) error {
+	if p := c.Pacer(); p != nil {
+		return c.PerformActions(context, p.MoveActions(x, y))
+	}
+
 	actions := []map[string]interface{}{
 		{
 			"type": "pointer",
@@ -132,7 +151,12 @@ func (c *Client) MoveMouse(context string, x, y float64) error {
 }
 
 // TypeText types a string of text using keyboard events.
+// With pacing enabled (see SetPacing), keystrokes are spaced out.
 func (c *Client) TypeText(context, text string) error {
+	if p := c.Pacer(); p != nil {
+		return c.PerformActions(context, p.TypeActions(text))
+	}
+
 	// Build key actions for each character
 	keyActions := make([]map[string]interface{}, 0, len(text)*2)
 	for _, char := range text {
@@ -171,7 +195,13 @@ func (c *Client) TypeIntoElement(context, selector, text string) error {
 }
 
 // PressKey presses a single key (for special keys like Enter, Tab, etc).
+// Named keys are resolved with KeyValue; see PressChord for combinations.
 func (c *Client) PressKey(context, key string) error {
+	value, err := KeyValue(key)
+	if err != nil {
+		return err
+	}
+
 	actions := []map[string]interface{}{
 		{
 			"type": "key",
@@ -179,11 +209,11 @@ func (c *Client) PressKey(context, key string) error {
 			"actions": []map[string]interface{}{
 				{
 					"type":  "keyDown",
-					"value": key,
+					"value": value,
 				},
 				{
 					"type":  "keyUp",
-					"value": key,
+					"value": value,
 				},
 			},
 		},
@@ -217,3 +247,360 @@ func (c *Client) GetElementValue(context, selector string) (string, error) {
 
 	return fmt.Sprintf("%v", result), nil
 }
+
+// SetFiles sets the files of an <input type="file"> element using
+// input.setFiles. Paths are resolved to absolute paths and must exist.
+// An empty paths list clears the selection.
+// If context is empty, it uses the first available context.
+func (c *Client) SetFiles(context, selector string, paths []string) error {
+	context, err := c.resolveContext(context)
+	if err != nil {
+		return err
+	}
+
+	files := make([]string, 0, len(paths))
+	for _, p := range paths {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return fmt.Errorf("invalid file path %q: %w", p, err)
+		}
+		info, err := os.Stat(abs)
+		if err != nil {
+			return fmt.Errorf("cannot upload %q: %w", p, err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("cannot upload %q: is a directory", p)
+		}
+		files = append(files, abs)
+	}
+
+	// input.setFiles only accepts file inputs, so fail with a clear message
+	kind, err := c.Evaluate(context, fmt.Sprintf(
+		`(() => { const el = document.querySelector(%q); return el ? el.tagName.toLowerCase() + ':' + (el.type || '') + ':' + el.multiple : ''; })()`,
+		selector))
+	if err != nil {
+		return err
+	}
+	parts := strings.SplitN(fmt.Sprintf("%v", kind), ":", 3)
+	if len(parts) != 3 {
+		return &errs.ElementNotFoundError{Selector: selector, Context: context}
+	}
+	if parts[0] != "input" || parts[1] != "file" {
+		return fmt.Errorf("element %s is not an <input type=\"file\">", selector)
+	}
+	if len(files) > 1 && parts[2] != "true" {
+		return fmt.Errorf("element %s does not accept multiple files", selector)
+	}
+
+	sharedID, err := c.GetElementSharedID(context, selector)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.SendCommand("input.setFiles", map[string]interface{}{
+		"context": context,
+		"element": map[string]interface{}{"sharedId": sharedID},
+		"files":   files,
+	})
+	return err
+}
+
+// Point is a position in CSS pixels.
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// ClickOptions configures ClickWithOptions and ClickElementWithOptions.
+type ClickOptions struct {
+	// Button is "left" (default), "middle" or "right".
+	Button string
+	// ClickCount is the number of clicks, e.g. 2 for a double-click. 0 = 1.
+	ClickCount int
+	// Modifiers are keys held during the click, e.g. "Shift" or "Control".
+	Modifiers []string
+	// Position is relative to the element's top-left corner.
+	// nil = the element's center. Ignored for coordinate clicks.
+	Position *Point
+	// Delay is the time between pointerDown and pointerUp.
+	Delay time.Duration
+}
+
+// dragSteps is the number of intermediate pointer moves in DragAndDrop, so
+// pages that track mousemove (and HTML drag and drop) see a real drag.
+const dragSteps = 10
+
+// MouseButton returns the WebDriver button number for "left", "middle" or "right".
+func MouseButton(name string) (int, error) {
+	switch strings.ToLower(name) {
+	case "", "left":
+		return 0, nil
+	case "middle":
+		return 1, nil
+	case "right":
+		return 2, nil
+	default:
+		return 0, errs.InvalidArgument("unknown mouse button %q (expected left, middle or right)", name)
+	}
+}
+
+// mouseSource wraps pointer actions in a mouse input source.
+func mouseSource(actions []map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "pointer",
+		"id":   "mouse",
+		"parameters": map[string]interface{}{
+			"pointerType": "mouse",
+		},
+		"actions": actions,
+	}
+}
+
+// pointerMove returns a pointerMove action to viewport coordinates.
+func pointerMove(x, y float64) map[string]interface{} {
+	return map[string]interface{}{
+		"type":     "pointerMove",
+		"x":        int(x),
+		"y":        int(y),
+		"duration": 0,
+	}
+}
+
+// pause returns a pause action.
+func pause(d time.Duration) map[string]interface{} {
+	return map[string]interface{}{
+		"type":     "pause",
+		"duration": int(d / time.Millisecond),
+	}
+}
+
+// withModifiers holds modifier keys down around pointer actions. Input
+// sources advance in lockstep, so each side pauses while the other acts.
+func withModifiers(pointerActions []map[string]interface{}, modifiers []string) ([]map[string]interface{}, error) {
+	if len(modifiers) == 0 {
+		return []map[string]interface{}{mouseSource(pointerActions)}, nil
+	}
+
+	keys := make([]string, 0, len(modifiers))
+	for _, m := range modifiers {
+		v, err := KeyValue(m)
+		if err != nil {
+			return nil, err
+		}
+		if !IsModifierKey(v) {
+			return nil, errs.InvalidArgument("%q is not a modifier key (expected Shift, Control, Alt or Meta)", m)
+		}
+		keys = append(keys, v)
+	}
+
+	var keyActions, mouseActions []map[string]interface{}
+	for _, k := range keys {
+		keyActions = append(keyActions, map[string]interface{}{"type": "keyDown", "value": k})
+		mouseActions = append(mouseActions, pause(0))
+	}
+	for _, a := range pointerActions {
+		keyActions = append(keyActions, pause(0))
+		mouseActions = append(mouseActions, a)
+	}
+	for i := len(keys) - 1; i >= 0; i-- {
+		keyActions = append(keyActions, map[string]interface{}{"type": "keyUp", "value": keys[i]})
+	}
+
+	return []map[string]interface{}{
+		{
+			"type":    "key",
+			"id":      "keyboard",
+			"actions": keyActions,
+		},
+		mouseSource(mouseActions),
+	}, nil
+}
+
+// ClickActions builds input.performActions sources that click at x, y.
+func ClickActions(x, y float64, opts ClickOptions) ([]map[string]interface{}, error) {
+	hold := func() time.Duration { return opts.Delay }
+	gap := func() time.Duration { return 0 }
+	return clickActions([]map[string]interface{}{pointerMove(x, y)}, opts, hold, gap)
+}
+
+// clickActions appends the presses of a click to the approach moves. hold
+// gives the time each press lasts and gap the time between presses.
+func clickActions(approach []map[string]interface{}, opts ClickOptions, hold, gap func() time.Duration) ([]map[string]interface{}, error) {
+	button, err := MouseButton(opts.Button)
+	if err != nil {
+		return nil, err
+	}
+	count := opts.ClickCount
+	if count <= 0 {
+		count = 1
+	}
+
+	actions := approach
+	for i := 0; i < count; i++ {
+		if d := gap(); i > 0 && d > 0 {
+			actions = append(actions, pause(d))
+		}
+		actions = append(actions, map[string]interface{}{"type": "pointerDown", "button": button})
+		if d := hold(); d > 0 {
+			actions = append(actions, pause(d))
+		}
+		actions = append(actions, map[string]interface{}{"type": "pointerUp", "button": button})
+	}
+
+	return withModifiers(actions, opts.Modifiers)
+}
+
+// DragActions builds input.performActions sources that press the left
+// button at from, move to to in steps, and release it there.
+func DragActions(from, to Point, steps int) []map[string]interface{} {
+	if steps < 1 {
+		steps = 1
+	}
+
+	actions := []map[string]interface{}{
+		pointerMove(from.X, from.Y),
+		{"type": "pointerDown", "button": 0},
+	}
+	for i := 1; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		actions = append(actions, pointerMove(from.X+(to.X-from.X)*t, from.Y+(to.Y-from.Y)*t))
+	}
+	actions = append(actions, map[string]interface{}{"type": "pointerUp", "button": 0})
+
+	return []map[string]interface{}{mouseSource(actions)}
+}
+
+// KeyChordActions builds input.performActions sources that press a chord
+// such as "Control+A": keys go down in order and come up in reverse.
+func KeyChordActions(chord string) ([]map[string]interface{}, error) {
+	keys, err := ParseChord(chord)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := make([]map[string]interface{}, 0, len(keys)*2)
+	for _, k := range keys {
+		actions = append(actions, map[string]interface{}{"type": "keyDown", "value": k})
+	}
+	for i := len(keys) - 1; i >= 0; i-- {
+		actions = append(actions, map[string]interface{}{"type": "keyUp", "value": keys[i]})
+	}
+
+	return []map[string]interface{}{
+		{
+			"type":    "key",
+			"id":      "keyboard",
+			"actions": actions,
+		},
+	}, nil
+}
+
+// WheelActions builds input.performActions sources that scroll the mouse
+// wheel by deltaX, deltaY pixels with the pointer at x, y.
+func WheelActions(x, y float64, deltaX, deltaY int) []map[string]interface{} {
+	return []map[string]interface{}{
+		{
+			"type": "wheel",
+			"id":   "wheel",
+			"actions": []map[string]interface{}{
+				{
+					"type":     "scroll",
+					"x":        int(x),
+					"y":        int(y),
+					"deltaX":   deltaX,
+					"deltaY":   deltaY,
+					"duration": 0,
+				},
+			},
+		},
+	}
+}
+
+// ClickWithOptions clicks at the specified coordinates with a button,
+// click count, held modifiers and press delay. opts.Position is ignored.
+func (c *Client) ClickWithOptions(context string, x, y float64, opts ClickOptions) error {
+	var actions []map[string]interface{}
+	var err error
+	if p := c.Pacer(); p != nil {
+		actions, err = p.ClickActions(x, y, opts)
+	} else {
+		actions, err = ClickActions(x, y, opts)
+	}
+	if err != nil {
+		return err
+	}
+	return c.PerformActions(context, actions)
+}
+
+// ClickElementWithOptions finds an element and clicks it with opts, at its
+// center or at opts.Position relative to its top-left corner.
+func (c *Client) ClickElementWithOptions(context, selector string, opts ClickOptions) error {
+	info, err := c.FindElement(context, selector)
+	if err != nil {
+		return err
+	}
+
+	x, y := info.GetCenter()
+	if opts.Position != nil {
+		x, y = info.Box.X+opts.Position.X, info.Box.Y+opts.Position.Y
+	}
+	return c.ClickWithOptions(context, x, y, opts)
+}
+
+// Hover moves the mouse over the center of an element.
+func (c *Client) Hover(context, selector string) error {
+	info, err := c.FindElement(context, selector)
+	if err != nil {
+		return err
+	}
+
+	x, y := info.GetCenter()
+	return c.MoveMouse(context, x, y)
+}
+
+// DragAndDrop drags the element matching source onto the element matching
+// target, moving the mouse in several steps between their centers.
+func (c *Client) DragAndDrop(context, source, target string) error {
+	src, err := c.FindElement(context, source)
+	if err != nil {
+		return err
+	}
+	dst, err := c.FindElement(context, target)
+	if err != nil {
+		return err
+	}
+
+	fromX, fromY := src.GetCenter()
+	toX, toY := dst.GetCenter()
+	if p := c.Pacer(); p != nil {
+		return c.PerformActions(context, p.DragActions(Point{fromX, fromY}, Point{toX, toY}))
+	}
+	return c.PerformActions(context, DragActions(Point{fromX, fromY}, Point{toX, toY}, dragSteps))
+}
+
+// PressChord presses a key chord such as "Enter", "Control+A" or
+// "Meta+Shift+K". See KeyValue for key names.
+func (c *Client) PressChord(context, chord string) error {
+	actions, err := KeyChordActions(chord)
+	if err != nil {
+		return err
+	}
+	return c.PerformActions(context, actions)
+}
+
+// Scroll turns the mouse wheel by deltaX, deltaY pixels with the pointer at x, y.
+func (c *Client) Scroll(context string, x, y float64, deltaX, deltaY int) error {
+	return c.PerformActions(context, WheelActions(x, y, deltaX, deltaY))
+}
+
+// ScrollElement turns the mouse wheel over the center of an element, which
+// scrolls the element if it is scrollable and the page otherwise.
+func (c *Client) ScrollElement(context, selector string, deltaX, deltaY int) error {
+	info, err := c.FindElement(context, selector)
+	if err != nil {
+		return err
+	}
+
+	x, y := info.GetCenter()
+	return c.Scroll(context, x, y, deltaX, deltaY)
+}
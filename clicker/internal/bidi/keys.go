@@ -0,0 +1,132 @@
+package bidi
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	errs "github.com/vibium/clicker/internal/errors"
+)
+
+// namedKeys maps key names to the codepoints WebDriver uses for them.
+// Lookups are case-insensitive.
+var namedKeys = map[string]string{
+	"unidentified": "\uE000",
+	"cancel":       "\uE001",
+	"help":         "\uE002",
+	"backspace":    "\uE003",
+	"tab":          "\uE004",
+	"clear":        "\uE005",
+	"return":       "\uE006",
+	"enter":        "\uE007",
+	"shift":        "\uE008",
+	"control":      "\uE009",
+	"alt":          "\uE00A",
+	"pause":        "\uE00B",
+	"escape":       "\uE00C",
+	"space":        "\uE00D",
+	"pageup":       "\uE00E",
+	"pagedown":     "\uE00F",
+	"end":          "\uE010",
+	"home":         "\uE011",
+	"arrowleft":    "\uE012",
+	"arrowup":      "\uE013",
+	"arrowright":   "\uE014",
+	"arrowdown":    "\uE015",
+	"insert":       "\uE016",
+	"delete":       "\uE017",
+	"numpad0":      "\uE01A",
+	"numpad1":      "\uE01B",
+	"numpad2":      "\uE01C",
+	"numpad3":      "\uE01D",
+	"numpad4":      "\uE01E",
+	"numpad5":      "\uE01F",
+	"numpad6":      "\uE020",
+	"numpad7":      "\uE021",
+	"numpad8":      "\uE022",
+	"numpad9":      "\uE023",
+	"f1":           "\uE031",
+	"f2":           "\uE032",
+	"f3":           "\uE033",
+	"f4":           "\uE034",
+	"f5":           "\uE035",
+	"f6":           "\uE036",
+	"f7":           "\uE037",
+	"f8":           "\uE038",
+	"f9":           "\uE039",
+	"f10":          "\uE03A",
+	"f11":          "\uE03B",
+	"f12":          "\uE03C",
+	"meta":         "\uE03D",
+
+	// Common aliases
+	"ctrl":    "\uE009",
+	"option":  "\uE00A",
+	"cmd":     "\uE03D",
+	"command": "\uE03D",
+	"esc":     "\uE00C",
+	"del":     "\uE017",
+	"up":      "\uE013",
+	"down":    "\uE015",
+	"left":    "\uE012",
+	"right":   "\uE014",
+	"plus":    "+",
+}
+
+// modifierKeys are the keys held down for the rest of a chord.
+var modifierKeys = map[string]bool{
+	"\uE008": true, // Shift
+	"\uE009": true, // Control
+	"\uE00A": true, // Alt
+	"\uE03D": true, // Meta
+}
+
+// KeyValue returns the WebDriver key value for a key name such as "Enter",
+// "ArrowLeft" or "F5", or for a single character such as "a".
+func KeyValue(name string) (string, error) {
+	if v, ok := namedKeys[strings.ToLower(name)]; ok {
+		return v, nil
+	}
+	if utf8.RuneCountInString(name) == 1 {
+		return name, nil
+	}
+	return "", errs.InvalidArgument("unknown key %q", name)
+}
+
+// ParseChord parses a key chord such as "Control+A" or "Meta+Shift+K" into
+// WebDriver key values, in the order they are pressed. A literal plus is
+// written as "Plus" or as a trailing "+", e.g. "Control++".
+func ParseChord(chord string) ([]string, error) {
+	if chord == "" {
+		return nil, fmt.Errorf("empty key chord")
+	}
+
+	var names []string
+	if strings.HasSuffix(chord, "++") || chord == "+" {
+		names = append(strings.Split(strings.TrimSuffix(chord, "++"), "+"), "+")
+		if chord == "+" {
+			names = []string{"+"}
+		}
+	} else {
+		names = strings.Split(chord, "+")
+	}
+
+	keys := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, errs.InvalidArgument("invalid key chord %q", chord)
+		}
+		v, err := KeyValue(name)
+		if err != nil {
+			return nil, errs.InvalidArgument("invalid key chord %q: %w", chord, err)
+		}
+		keys = append(keys, v)
+	}
+	return keys, nil
+}
+
+// IsModifierKey reports whether a key value is Shift, Control, Alt or Meta.
+func IsModifierKey(value string) bool {
+	return modifierKeys[value]
+}
@@ -0,0 +1,227 @@
+package bidi
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Pacing configures human-like input: the pointer travels to its target on
+// an eased, slightly curved path, buttons are held briefly, and keystrokes
+// are spaced out. Zero durations mean no delay.
+type Pacing struct {
+	// MoveDuration is how long the pointer takes to reach a target.
+	MoveDuration time.Duration
+	// MoveSteps is the number of pointer moves along the path (minimum 1).
+	MoveSteps int
+	// ClickDelay is how long a mouse button is held down.
+	ClickDelay time.Duration
+	// KeyDelay is the time between keystrokes.
+	KeyDelay time.Duration
+	// Jitter varies each delay randomly by up to this fraction (0 to 1),
+	// e.g. 0.3 turns an 80ms key delay into 56-104ms.
+	Jitter float64
+	// Seed seeds the random number generator so runs can be reproduced.
+	// 0 picks a seed from the clock; see Pacer.Seed.
+	Seed int64
+}
+
+// DefaultPacing returns pacing that resembles a quick but human user.
+func DefaultPacing() Pacing {
+	return Pacing{
+		MoveDuration: 250 * time.Millisecond,
+		MoveSteps:    20,
+		ClickDelay:   60 * time.Millisecond,
+		KeyDelay:     80 * time.Millisecond,
+		Jitter:       0.3,
+	}
+}
+
+// Pacer builds paced input actions. It remembers where the pointer is, so
+// each path starts where the previous one ended. It is safe for concurrent use.
+type Pacer struct {
+	opts Pacing
+	seed int64
+
+	mu  sync.Mutex
+	rng *rand.Rand
+	pos Point
+}
+
+// NewPacer creates a Pacer for opts.
+func NewPacer(opts Pacing) *Pacer {
+	if opts.MoveSteps < 1 {
+		opts.MoveSteps = 1
+	}
+	opts.Jitter = math.Max(0, math.Min(1, opts.Jitter))
+
+	seed := opts.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	return &Pacer{
+		opts: opts,
+		seed: seed,
+		rng:  rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Seed returns the seed in use, to reproduce a run with Pacing.Seed.
+func (p *Pacer) Seed() int64 {
+	return p.seed
+}
+
+// Options returns the pacing options.
+func (p *Pacer) Options() Pacing {
+	return p.opts
+}
+
+// vary applies jitter to d. The caller must hold mu.
+func (p *Pacer) vary(d time.Duration) time.Duration {
+	if d <= 0 || p.opts.Jitter == 0 {
+		return d
+	}
+	f := 1 + p.opts.Jitter*(2*p.rng.Float64()-1)
+	return time.Duration(float64(d) * f)
+}
+
+// easeInOut maps linear progress t in [0, 1] to eased progress: slow start,
+// fast middle, slow end.
+func easeInOut(t float64) float64 {
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	return 1 - math.Pow(-2*t+2, 3)/2
+}
+
+// path returns pointer moves from the current position to `to` along a
+// cubic Bézier curve with eased timing, and makes `to` the current position.
+// The caller must hold mu.
+func (p *Pacer) path(to Point) []map[string]interface{} {
+	from := p.pos
+	p.pos = to
+
+	dx, dy := to.X-from.X, to.Y-from.Y
+	dist := math.Hypot(dx, dy)
+	if dist < 1 {
+		return []map[string]interface{}{pointerMove(to.X, to.Y)}
+	}
+
+	// Control points sit a third and two thirds along the line, pushed
+	// sideways by up to a fifth of the distance so the path bows a little.
+	nx, ny := -dy/dist, dx/dist
+	bow := func() float64 { return (p.rng.Float64()*2 - 1) * dist / 5 }
+	b1, b2 := bow(), bow()
+	c1 := Point{from.X + dx/3 + nx*b1, from.Y + dy/3 + ny*b1}
+	c2 := Point{from.X + 2*dx/3 + nx*b2, from.Y + 2*dy/3 + ny*b2}
+
+	steps := p.opts.MoveSteps
+	stepDuration := p.opts.MoveDuration / time.Duration(steps)
+
+	actions := make([]map[string]interface{}, 0, steps)
+	for i := 1; i <= steps; i++ {
+		t := easeInOut(float64(i) / float64(steps))
+		u := 1 - t
+		x := u*u*u*from.X + 3*u*u*t*c1.X + 3*u*t*t*c2.X + t*t*t*to.X
+		y := u*u*u*from.Y + 3*u*u*t*c1.Y + 3*u*t*t*c2.Y + t*t*t*to.Y
+		if i == steps {
+			x, y = to.X, to.Y
+		}
+		move := pointerMove(x, y)
+		move["duration"] = int(p.vary(stepDuration) / time.Millisecond)
+		actions = append(actions, move)
+	}
+	return actions
+}
+
+// MoveActions builds input.performActions sources that move the pointer to x, y.
+func (p *Pacer) MoveActions(x, y float64) []map[string]interface{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return []map[string]interface{}{mouseSource(p.path(Point{x, y}))}
+}
+
+// ClickActions builds input.performActions sources that move the pointer to
+// x, y and click there. opts.Delay, if set, overrides Pacing.ClickDelay.
+func (p *Pacer) ClickActions(x, y float64, opts ClickOptions) ([]map[string]interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	approach := p.path(Point{x, y})
+	hold := func() time.Duration {
+		if opts.Delay > 0 {
+			return opts.Delay
+		}
+		return p.vary(p.opts.ClickDelay)
+	}
+	return clickActions(approach, opts, hold, func() time.Duration { return p.vary(p.opts.ClickDelay) })
+}
+
+// DragActions builds input.performActions sources that move the pointer to
+// from, press the left button, travel to `to` and release it there.
+func (p *Pacer) DragActions(from, to Point) []map[string]interface{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	actions := p.path(from)
+	actions = append(actions,
+		map[string]interface{}{"type": "pointerDown", "button": 0},
+		pause(p.vary(p.opts.ClickDelay)),
+	)
+	actions = append(actions, p.path(to)...)
+	actions = append(actions,
+		pause(p.vary(p.opts.ClickDelay)),
+		map[string]interface{}{"type": "pointerUp", "button": 0},
+	)
+	return []map[string]interface{}{mouseSource(actions)}
+}
+
+// TypeActions builds input.performActions sources that type text with
+// Pacing.KeyDelay between keystrokes.
+func (p *Pacer) TypeActions(text string) []map[string]interface{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	runes := []rune(text)
+	actions := make([]map[string]interface{}, 0, len(runes)*3)
+	for i, char := range runes {
+		if i > 0 && p.opts.KeyDelay > 0 {
+			actions = append(actions, pause(p.vary(p.opts.KeyDelay)))
+		}
+		actions = append(actions,
+			map[string]interface{}{"type": "keyDown", "value": string(char)},
+			map[string]interface{}{"type": "keyUp", "value": string(char)},
+		)
+	}
+
+	return []map[string]interface{}{
+		{
+			"type":    "key",
+			"id":      "keyboard",
+			"actions": actions,
+		},
+	}
+}
+
+// SetPacing enables paced input for Click, ClickWithOptions, MoveMouse,
+// Hover, DragAndDrop and TypeText. nil turns pacing off.
+func (c *Client) SetPacing(opts *Pacing) {
+	c.eventsMu.Lock()
+	defer c.eventsMu.Unlock()
+
+	if opts == nil {
+		c.pacer = nil
+		return
+	}
+	c.pacer = NewPacer(*opts)
+}
+
+// Pacer returns the client's pacer, or nil if input is not paced.
+func (c *Client) Pacer() *Pacer {
+	c.eventsMu.Lock()
+	defer c.eventsMu.Unlock()
+	return c.pacer
+}
@@ -46,6 +46,10 @@ type Message struct {
 	Result json.RawMessage `json:"result,omitempty"`
 	Error  json.RawMessage `json:"error,omitempty"`
 
+	// ErrorMessage is the "message" of an error response, which BiDi sends
+	// next to an "error" code string.
+	ErrorMessage string `json:"message,omitempty"`
+
 	// Event fields
 	Method string          `json:"method,omitempty"`
 	Params json.RawMessage `json:"params,omitempty"`
@@ -80,6 +84,9 @@ func (m *Message) GetError() (*ErrorData, error) {
 		if err := json.Unmarshal(m.Error, &errStr); err != nil {
 			return nil, err
 		}
+		if m.ErrorMessage != "" {
+			return &ErrorData{Error: errStr, Message: m.ErrorMessage}, nil
+		}
 		return &ErrorData{Error: errStr, Message: errStr}, nil
 	}
 	return &errData, nil
@@ -3,12 +3,52 @@ package bidi
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	errs "github.com/vibium/clicker/internal/errors"
 )
 
+// maxBufferedEvents caps how many unconsumed events the client keeps around.
+const maxBufferedEvents = 256
+
 // Client is a BiDi client that wraps a WebSocket connection.
 type Client struct {
 	conn    *Connection
 	verbose bool
+
+	// Events received while waiting for command responses are buffered
+	// so WaitForEvent can still observe them.
+	eventsMu   sync.Mutex
+	events     []*Event
+	subscribed map[string]bool
+
+	// noHistoryUpdated is set once subscribing to
+	// browsingContext.historyUpdated failed. See TraverseHistory.
+	noHistoryUpdated bool
+
+	// downloadDir is where the browser saves downloads, if set.
+	downloadDir string
+
+	// Dialog handling state, see SetDialogPolicy.
+	dialogPolicy string
+	openDialog   *Dialog
+	dialogs      []Dialog
+
+	// pacer paces pointer and keyboard input, if set. See SetPacing.
+	pacer *Pacer
+
+	// responses holds responses that arrived while a nested command (such as
+	// handling a dialog) was waiting; abandoned lists commands whose
+	// responses nobody waits for anymore.
+	responses map[int64]*Message
+	abandoned map[int64]bool
+
+	// Crash detection state, see SetCrashCheck.
+	crashCheck func() error
+	closing    map[string]bool // contexts being closed by this client
+	gone       map[string]bool // contexts that went away on their own
 }
 
 // NewClient creates a new BiDi client from a WebSocket connection.
@@ -23,6 +63,10 @@ func (c *Client) SetVerbose(verbose bool) {
 
 // SendCommand sends a BiDi command and waits for the response.
 func (c *Client) SendCommand(method string, params interface{}) (*Message, error) {
+	if err := c.checkOpenDialog(method); err != nil {
+		return nil, err
+	}
+
 	cmd := NewCommand(method, params)
 
 	data, err := cmd.Marshal()
@@ -34,15 +78,21 @@ func (c *Client) SendCommand(method string, params interface{}) (*Message, error
 		fmt.Printf("       --> %s\n", string(data))
 	}
 
+	c.trackClose(method, params)
 	if err := c.conn.Send(string(data)); err != nil {
-		return nil, fmt.Errorf("failed to send command: %w", err)
+		return nil, c.connError("failed to send command", err)
 	}
 
 	// Wait for response with matching ID
 	for {
+		// A nested command may already have received our response
+		if msg := c.takeResponse(cmd.ID); msg != nil {
+			return c.commandResult(msg, params)
+		}
+
 		resp, err := c.conn.Receive()
 		if err != nil {
-			return nil, fmt.Errorf("failed to receive response: %w", err)
+			return nil, c.connError("failed to receive response", err)
 		}
 
 		if c.verbose {
@@ -56,24 +106,247 @@ func (c *Client) SendCommand(method string, params interface{}) (*Message, error
 
 		// Check if this is the response we're waiting for
 		if msg.ID != nil && *msg.ID == cmd.ID {
-			if msg.IsError() {
-				errData, _ := msg.GetError()
-				if errData != nil {
-					return nil, fmt.Errorf("BiDi error: %s - %s", errData.Error, errData.Message)
-				}
-				return nil, fmt.Errorf("BiDi error: %s", string(msg.Error))
-			}
-			return msg, nil
+			return c.commandResult(msg, params)
+		}
+
+		// Keep responses to other commands for whoever is waiting on them
+		if msg.ID != nil {
+			c.storeResponse(msg)
+			continue
 		}
 
-		// If it's an event, skip it for now (could be handled by event listener)
+		// If it's an event, buffer it for WaitForEvent
 		if msg.IsEvent() {
 			if c.verbose {
-				fmt.Printf("       (event, skipping)\n")
+				fmt.Printf("       (event, buffered)\n")
+			}
+			c.bufferEvent(msg)
+			if err := c.observeEvent(msg); err != nil {
+				// The command stays blocked until the dialog is handled
+				c.abandon(cmd.ID)
+				return nil, err
+			}
+			continue
+		}
+	}
+}
+
+// responseResult returns msg, or an error if it is an error response.
+func responseResult(msg *Message) (*Message, error) {
+	if msg.IsError() {
+		errData, _ := msg.GetError()
+		if errData != nil {
+			return nil, &errs.ProtocolError{BiDiCode: errData.Error, Message: errData.Message}
+		}
+		return nil, &errs.ProtocolError{BiDiCode: string(msg.Error)}
+	}
+	return msg, nil
+}
+
+// storeResponse keeps a response for a command other than the one being
+// awaited. Responses to abandoned commands are dropped.
+func (c *Client) storeResponse(msg *Message) {
+	c.eventsMu.Lock()
+	defer c.eventsMu.Unlock()
+
+	if c.abandoned[*msg.ID] {
+		delete(c.abandoned, *msg.ID)
+		return
+	}
+	if c.responses == nil {
+		c.responses = make(map[int64]*Message)
+	}
+	c.responses[*msg.ID] = msg
+}
+
+// takeResponse removes and returns the stored response for id, if any.
+func (c *Client) takeResponse(id int64) *Message {
+	c.eventsMu.Lock()
+	defer c.eventsMu.Unlock()
+
+	msg := c.responses[id]
+	delete(c.responses, id)
+	return msg
+}
+
+// abandon marks a command whose response will no longer be awaited.
+func (c *Client) abandon(id int64) {
+	c.eventsMu.Lock()
+	defer c.eventsMu.Unlock()
+
+	if c.abandoned == nil {
+		c.abandoned = make(map[int64]bool)
+	}
+	c.abandoned[id] = true
+}
+
+// bufferEvent stores an event so it can be consumed later by WaitForEvent.
+func (c *Client) bufferEvent(msg *Message) {
+	c.eventsMu.Lock()
+	defer c.eventsMu.Unlock()
+
+	c.events = append(c.events, &Event{Method: msg.Method, Params: msg.Params})
+	if len(c.events) > maxBufferedEvents {
+		c.events = c.events[len(c.events)-maxBufferedEvents:]
+	}
+}
+
+// takeEvent removes and returns the first buffered event matching method and match.
+func (c *Client) takeEvent(method string, match func(*Event) bool) *Event {
+	c.eventsMu.Lock()
+	defer c.eventsMu.Unlock()
+
+	for i, ev := range c.events {
+		if ev.Method == method && (match == nil || match(ev)) {
+			c.events = append(c.events[:i], c.events[i+1:]...)
+			return ev
+		}
+	}
+	return nil
+}
+
+// Subscribe subscribes the session to the given BiDi events.
+// Events that are already subscribed are skipped.
+func (c *Client) Subscribe(events ...string) error {
+	c.eventsMu.Lock()
+	if c.subscribed == nil {
+		c.subscribed = make(map[string]bool)
+	}
+	var pending []string
+	for _, ev := range events {
+		if !c.subscribed[ev] {
+			pending = append(pending, ev)
+		}
+	}
+	c.eventsMu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if _, err := c.SendCommand("session.subscribe", map[string]interface{}{
+		"events": pending,
+	}); err != nil {
+		return err
+	}
+
+	c.eventsMu.Lock()
+	for _, ev := range pending {
+		c.subscribed[ev] = true
+	}
+	c.eventsMu.Unlock()
+
+	return nil
+}
+
+// WaitForEvent waits for an event with the given method.
+// If match is non-nil, only events for which it returns true are accepted.
+// Events received earlier (e.g. while a command was in flight) are considered first.
+// The caller must have subscribed to the event beforehand.
+func (c *Client) WaitForEvent(method string, timeout time.Duration, match func(*Event) bool) (*Event, error) {
+	return c.waitForEvents([]string{method}, timeout, match)
+}
+
+// waitForEvents is WaitForEvent for an event with any of methods.
+func (c *Client) waitForEvents(methods []string, timeout time.Duration, match func(*Event) bool) (*Event, error) {
+	for _, method := range methods {
+		if ev := c.takeEvent(method, match); ev != nil {
+			return ev, nil
+		}
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, &errs.TimeoutError{Selector: strings.Join(methods, " or "), Timeout: timeout, Reason: "event not received"}
+		}
+
+		resp, err := c.conn.ReceiveTimeout(remaining)
+		if err == ErrReceiveTimeout {
+			continue
+		}
+		if err != nil {
+			return nil, c.connError("failed to receive event", err)
+		}
+
+		if c.verbose {
+			fmt.Printf("       <-- %s\n", resp)
+		}
+
+		msg, err := UnmarshalMessage([]byte(resp))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse event: %w", err)
+		}
+		if msg.ID != nil {
+			c.storeResponse(msg)
+			continue
+		}
+		if !msg.IsEvent() {
+			continue
+		}
+
+		ev := &Event{Method: msg.Method, Params: msg.Params}
+		matched := false
+		for _, method := range methods {
+			if ev.Method == method && (match == nil || match(ev)) {
+				matched = true
 			}
+		}
+
+		// A dialog blocking the page fails the wait, unless it is what we wait for
+		if err := c.observeEvent(msg); err != nil && !matched {
+			c.bufferEvent(msg)
+			return nil, err
+		}
+		if matched {
+			return ev, nil
+		}
+		c.bufferEvent(msg)
+	}
+}
+
+// TakeEvents waits up to timeout for a message from the browser, reads any
+// others already queued, and returns all buffered events oldest first,
+// removing them from the buffer. Dialog events are handled according to the
+// dialog policy; under the fail policy the dialog stays open and the next
+// command reports it.
+func (c *Client) TakeEvents(timeout time.Duration) ([]*Event, error) {
+	wait := timeout
+	for {
+		resp, err := c.conn.ReceiveTimeout(wait)
+		if err == ErrReceiveTimeout {
+			break
+		}
+		if err != nil {
+			return nil, c.connError("failed to receive event", err)
+		}
+		wait = time.Millisecond
+
+		if c.verbose {
+			fmt.Printf("       <-- %s\n", resp)
+		}
+
+		msg, err := UnmarshalMessage([]byte(resp))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse event: %w", err)
+		}
+		if msg.ID != nil {
+			c.storeResponse(msg)
 			continue
 		}
+		if msg.IsEvent() {
+			c.bufferEvent(msg)
+			c.observeEvent(msg)
+		}
 	}
+
+	c.eventsMu.Lock()
+	defer c.eventsMu.Unlock()
+
+	events := c.events
+	c.events = nil
+	return events, nil
 }
 
 // SessionStatusResult represents the result of session.status command.
@@ -0,0 +1,50 @@
+package bidi
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Cookie is a cookie as reported by storage.getCookies. It can be passed
+// back to SetCookie as is.
+type Cookie struct {
+	Name     string      `json:"name"`
+	Value    CookieValue `json:"value"`
+	Domain   string      `json:"domain"`
+	Path     string      `json:"path,omitempty"`
+	HTTPOnly bool        `json:"httpOnly"`
+	Secure   bool        `json:"secure"`
+	SameSite string      `json:"sameSite,omitempty"` // "strict", "lax" or "none"
+	Expiry   int64       `json:"expiry,omitempty"`   // seconds since the epoch, 0 = session cookie
+}
+
+// CookieValue is the value of a cookie.
+type CookieValue struct {
+	Type  string `json:"type"` // "string" or "base64"
+	Value string `json:"value"`
+}
+
+// GetCookies returns the cookies of the default user context.
+func (c *Client) GetCookies() ([]Cookie, error) {
+	msg, err := c.SendCommand("storage.getCookies", map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Cookies []Cookie `json:"cookies"`
+	}
+	if err := json.Unmarshal(msg.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse storage.getCookies result: %w", err)
+	}
+
+	return result.Cookies, nil
+}
+
+// SetCookie sets a cookie in the default user context.
+func (c *Client) SetCookie(cookie Cookie) error {
+	_, err := c.SendCommand("storage.setCookie", map[string]interface{}{
+		"cookie": cookie,
+	})
+	return err
+}
@@ -0,0 +1,56 @@
+package browser
+
+import (
+	"strings"
+
+	errs "github.com/vibium/clicker/internal/errors"
+)
+
+// Browsers Launch can start.
+const (
+	BrowserChrome  = "chrome"
+	BrowserFirefox = "firefox"
+)
+
+// ParseBrowser validates a browser name. An empty name means Chrome.
+func ParseBrowser(name string) (string, error) {
+	switch strings.ToLower(name) {
+	case "", BrowserChrome:
+		return BrowserChrome, nil
+	case BrowserFirefox:
+		return BrowserFirefox, nil
+	default:
+		return "", errs.InvalidArgument("unknown browser %q (expected chrome or firefox)", name)
+	}
+}
+
+// backend launches one kind of browser through its WebDriver driver.
+// Everything after session creation is plain WebDriver BiDi and shared.
+type backend interface {
+	// driverName names the driver in messages, e.g. "chromedriver".
+	driverName() string
+	// driverPath and executable find the driver and browser opts ask for.
+	driverPath(opts LaunchOptions) (string, error)
+	executable(opts LaunchOptions) (string, error)
+	// installHint tells how to get the driver or browser when missing.
+	installHint(opts LaunchOptions) string
+	// driverArgs returns the driver's arguments to listen on port.
+	driverArgs(port int) ([]string, error)
+	// capabilities returns browserName and the vendor options for a new
+	// session. An empty binary leaves the choice of binary to the driver.
+	capabilities(binary string, opts LaunchOptions) map[string]interface{}
+	// installExtensions loads the unpacked extensions in dirs into a new
+	// session, for browsers that cannot load them from capabilities.
+	installExtensions(baseURL, sessionID string, dirs []string) error
+	// orphanPatterns match the command lines of driver and browser
+	// processes that may escape killProcessTree.
+	orphanPatterns() []string
+}
+
+// backendFor returns the backend of a browser name (see ParseBrowser).
+func backendFor(browser string) backend {
+	if strings.EqualFold(browser, BrowserFirefox) {
+		return firefox{}
+	}
+	return chrome{}
+}
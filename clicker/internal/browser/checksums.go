@@ -0,0 +1,104 @@
+package browser
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+)
+
+// checksums maps file names in a SHA-256 manifest to their hex digests.
+type checksums map[string]string
+
+// loadChecksums reads a manifest in sha256sum format ("<hex>  <name>" per
+// line) from a file or an http(s) URL. Names are either the bare file name
+// ("chrome-linux64.zip") or its path below the download base
+// ("131.0.6778.85/linux64/chrome-linux64.zip"), so one manifest can cover
+// several versions. An empty src means no verification.
+func loadChecksums(src string) (checksums, error) {
+	if src == "" {
+		return nil, nil
+	}
+
+	var r io.ReadCloser
+	if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+		resp, err := http.Get(src)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch checksums: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to fetch checksums: HTTP %d", resp.StatusCode)
+		}
+		r = resp.Body
+	} else {
+		f, err := os.Open(src)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read checksums: %w", err)
+		}
+		r = f
+	}
+	defer r.Close()
+
+	sums := checksums{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 || len(fields[0]) != sha256.Size*2 {
+			return nil, fmt.Errorf("invalid checksums line %d: %q (expected \"<sha256>  <file>\")", line, text)
+		}
+		if _, err := hex.DecodeString(fields[0]); err != nil {
+			return nil, fmt.Errorf("invalid checksums line %d: %q (expected \"<sha256>  <file>\")", line, text)
+		}
+		// sha256sum marks binary mode with a leading *
+		sums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read checksums: %w", err)
+	}
+	return sums, nil
+}
+
+// verify checks the digest of the zip at relPath (version/platform/file).
+// Without a manifest everything passes; with one, files must be listed.
+func (c checksums) verify(relPath, digest string) error {
+	if c == nil {
+		return nil
+	}
+
+	want, ok := c[relPath]
+	if !ok {
+		want, ok = c[path.Base(relPath)]
+	}
+	if !ok {
+		return fmt.Errorf("no checksum for %s in the manifest", relPath)
+	}
+	if want != digest {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", relPath, want, digest)
+	}
+	return nil
+}
+
+// hashFile returns the hex SHA-256 digest of a file.
+func hashFile(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,141 @@
+package browser
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/vibium/clicker/internal/paths"
+)
+
+// chrome launches Chrome for Testing (or system Chrome) through chromedriver.
+type chrome struct{}
+
+func (chrome) driverName() string { return "chromedriver" }
+
+func (chrome) driverPath(opts LaunchOptions) (string, error) {
+	return paths.GetChromedriverPathFor(opts.ChromeVersion)
+}
+
+func (chrome) executable(opts LaunchOptions) (string, error) {
+	return paths.GetChromeExecutableFor(opts.ChromeVersion)
+}
+
+func (chrome) installHint(opts LaunchOptions) string {
+	version := opts.ChromeVersion
+	if version == "" {
+		version = os.Getenv(paths.ChromeVersionEnv)
+	}
+	if version != "" {
+		return fmt.Sprintf("Chrome for Testing %s is not installed; run 'clicker install --version %s' or see 'clicker browsers list'", version, version)
+	}
+	return "run 'clicker install' first"
+}
+
+func (chrome) driverArgs(port int) ([]string, error) {
+	return []string{fmt.Sprintf("--port=%d", port)}, nil
+}
+
+// Chrome loads extensions from flags at launch.
+func (chrome) installExtensions(baseURL, sessionID string, dirs []string) error { return nil }
+
+func (chrome) orphanPatterns() []string {
+	return []string{"chromedriver", "Chrome for Testing"}
+}
+
+// chromeDefaultArgs are the arguments Chrome always gets, unless dropped
+// with IgnoreDefaultArgs.
+var chromeDefaultArgs = []string{
+	"--no-first-run",
+	"--no-default-browser-check",
+	"--disable-infobars",
+	"--disable-blink-features=AutomationControlled",
+	"--disable-crash-reporter",
+	"--disable-background-networking",
+	"--disable-background-timer-throttling",
+	"--disable-backgrounding-occluded-windows",
+	"--disable-breakpad",
+	"--disable-component-extensions-with-background-pages",
+	"--disable-component-update",
+	"--disable-default-apps",
+	"--disable-dev-shm-usage",
+	"--disable-extensions",
+	"--disable-features=TranslateUI",
+	"--disable-hang-monitor",
+	"--disable-ipc-flooding-protection",
+	"--disable-popup-blocking",
+	"--disable-prompt-on-repost",
+	"--disable-renderer-backgrounding",
+	"--disable-sync",
+	"--enable-features=NetworkService,NetworkServiceInProcess",
+	"--force-color-profile=srgb",
+	"--metrics-recording-only",
+	"--password-store=basic",
+	"--use-mock-keychain",
+}
+
+// capabilities builds goog:chromeOptions.
+func (chrome) capabilities(binary string, opts LaunchOptions) map[string]interface{} {
+	ignore := append([]string{}, opts.IgnoreDefaultArgs...)
+	if len(opts.Extensions) > 0 {
+		ignore = append(ignore, "--disable-extensions")
+	}
+	// Copied, as appending must not touch chromeDefaultArgs
+	args := append([]string{}, withoutArgs(chromeDefaultArgs, ignore)...)
+
+	if opts.Headless {
+		args = append(args, "--headless=new")
+	}
+
+	if opts.Viewport != nil && opts.Viewport.Width > 0 && opts.Viewport.Height > 0 {
+		args = append(args, fmt.Sprintf("--window-size=%d,%d", opts.Viewport.Width, opts.Viewport.Height))
+	}
+	if opts.UserAgent != "" {
+		args = append(args, "--user-agent="+opts.UserAgent)
+	}
+	if opts.Touch {
+		args = append(args, "--touch-events=enabled")
+	}
+	if opts.Locale != "" {
+		args = append(args, "--lang="+opts.Locale)
+	}
+	if opts.UserDataDir != "" {
+		args = append(args, "--user-data-dir="+opts.UserDataDir)
+	}
+	if opts.ProxyServer != "" {
+		args = append(args, "--proxy-server="+opts.ProxyServer)
+		if hosts := proxyBypassList(opts.ProxyBypass); len(hosts) > 0 {
+			args = append(args, "--proxy-bypass-list="+strings.Join(hosts, ";"))
+		}
+	}
+	if len(opts.Extensions) > 0 {
+		dirs := strings.Join(opts.Extensions, ",")
+		args = append(args, "--load-extension="+dirs, "--disable-extensions-except="+dirs)
+	}
+	args = append(args, opts.Args...)
+
+	chromeOpts := map[string]interface{}{
+		"args":            args,
+		"excludeSwitches": []string{"enable-automation"},
+	}
+	if binary != "" {
+		chromeOpts["binary"] = binary
+	}
+	prefs := map[string]interface{}{}
+	if opts.Locale != "" {
+		prefs["intl.accept_languages"] = opts.Locale
+	}
+	if opts.DownloadDir != "" {
+		prefs["download.default_directory"] = opts.DownloadDir
+		prefs["download.prompt_for_download"] = false
+		prefs["download.directory_upgrade"] = true
+	}
+	if len(prefs) > 0 {
+		chromeOpts["prefs"] = prefs
+	}
+
+	return map[string]interface{}{
+		"browserName":        "chrome",
+		"goog:chromeOptions": chromeOpts,
+	}
+}
@@ -0,0 +1,81 @@
+package browser
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	errs "github.com/vibium/clicker/internal/errors"
+	"github.com/vibium/clicker/internal/log"
+	"github.com/vibium/clicker/internal/process"
+)
+
+// Start launches a browser, or attaches to opts.ConnectURL if it is set.
+func Start(opts LaunchOptions) (*LaunchResult, error) {
+	if opts.ConnectURL != "" {
+		return Connect(opts)
+	}
+	return Launch(opts)
+}
+
+// Connect attaches to an existing browser instead of launching one.
+// opts.ConnectURL is either a WebDriver endpoint (http:// or https://, e.g.
+// a chromedriver started elsewhere or a Selenium Grid), where a new session
+// with BiDi is created from opts and opts.Capabilities, or the webSocketUrl
+// of an existing BiDi session (ws:// or wss://), used as is.
+// Closing the result, or process.KillAll, ends the WebDriver session it
+// created, if any; it never kills processes.
+func Connect(opts LaunchOptions) (*LaunchResult, error) {
+	u, err := url.Parse(opts.ConnectURL)
+	if err != nil || u.Host == "" {
+		return nil, errs.InvalidArgument("invalid connect URL %q (expected http(s)://HOST:PORT or a ws(s):// BiDi URL)", opts.ConnectURL)
+	}
+
+	switch u.Scheme {
+	case "ws", "wss":
+		log.Debug("using existing BiDi session", "wsUrl", opts.ConnectURL)
+		return &LaunchResult{WebSocketURL: opts.ConnectURL}, nil
+	case "http", "https":
+	default:
+		return nil, errs.InvalidArgument("invalid connect URL %q (expected http(s)://HOST:PORT or a ws(s):// BiDi URL)", opts.ConnectURL)
+	}
+
+	if err := validateProxy(opts); err != nil {
+		return nil, err
+	}
+
+	baseURL := strings.TrimSuffix(opts.ConnectURL, "/")
+	if err := checkDriver(baseURL); err != nil {
+		return nil, &errs.ConnectionError{URL: baseURL, Cause: err}
+	}
+
+	sessionID, wsURL, err := createSession(baseURL, opts.ExecutablePath, opts)
+	if err != nil {
+		return nil, &errs.LaunchError{Cause: fmt.Errorf("failed to create session on %s: %w", baseURL, err)}
+	}
+	log.Info("connected to browser", "driver", baseURL, "sessionId", sessionID, "wsUrl", wsURL)
+
+	return &LaunchResult{
+		WebSocketURL: wsURL,
+		SessionID:    sessionID,
+		driverURL:    baseURL,
+		// Killing nothing ends a remote session, so end it on exit
+		forget: process.OnKill(func() { deleteSession(baseURL, sessionID) }),
+	}, nil
+}
+
+// checkDriver checks that a WebDriver endpoint answers /status.
+func checkDriver(baseURL string) error {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(baseURL + "/status")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET /status: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
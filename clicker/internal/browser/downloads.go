@@ -0,0 +1,48 @@
+package browser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/vibium/clicker/internal/bidi"
+	errs "github.com/vibium/clicker/internal/errors"
+	"github.com/vibium/clicker/internal/log"
+)
+
+// resolveDownloadDir makes dir absolute and creates it if needed.
+func resolveDownloadDir(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", errs.InvalidArgument("invalid download directory: %w", err)
+	}
+	if err := os.MkdirAll(abs, 0755); err != nil {
+		return "", fmt.Errorf("failed to create download directory: %w", err)
+	}
+	return abs, nil
+}
+
+// configureDownloads subscribes the client to download events so downloads
+// can be awaited, and points the browser at opts.DownloadDir if set.
+// Call it after connecting and before navigating.
+func configureDownloads(client *bidi.Client, opts LaunchOptions) error {
+	if opts.DownloadDir == "" {
+		// Tracking is best-effort: older browsers lack the download events
+		if err := client.Subscribe(bidi.EventDownloadWillBegin, bidi.EventDownloadEnd); err != nil {
+			log.Debug("download events not available", "error", err)
+		}
+		return nil
+	}
+
+	dir, err := resolveDownloadDir(opts.DownloadDir)
+	if err != nil {
+		return err
+	}
+
+	// The download prefs set at launch already cover browsers without
+	// browser.setDownloadBehavior
+	if err := client.SetDownloadDir(dir); err != nil {
+		log.Debug("download behavior not applied", "error", err)
+	}
+	return nil
+}
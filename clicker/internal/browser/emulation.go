@@ -0,0 +1,84 @@
+package browser
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/vibium/clicker/internal/bidi"
+	"github.com/vibium/clicker/internal/log"
+)
+
+// emulationEnv returns the environment variables that apply the launch-time
+// locale and timezone to the browser process.
+func emulationEnv(opts LaunchOptions) []string {
+	var env []string
+	if opts.Timezone != "" {
+		env = append(env, "TZ="+opts.Timezone)
+	}
+	if opts.Locale != "" {
+		// Chrome on Linux picks its UI language from LANGUAGE rather than --lang
+		env = append(env, "LANGUAGE="+strings.ReplaceAll(opts.Locale, "-", "_"))
+	}
+	return env
+}
+
+// ApplyEmulation applies the parts of opts that are set over BiDi rather than
+// at launch: viewport, geolocation, and the locale and timezone overrides.
+// Call it after connecting and before navigating.
+// If context is empty, it uses the first available context.
+func ApplyEmulation(client *bidi.Client, context string, opts LaunchOptions) error {
+	if vp := opts.Viewport; vp != nil {
+		if err := client.SetViewport(context, vp.Width, vp.Height, vp.DevicePixelRatio); err != nil {
+			return err
+		}
+	}
+
+	if geo := opts.Geolocation; geo != nil {
+		coords := &bidi.GeolocationCoordinates{
+			Latitude:  geo.Latitude,
+			Longitude: geo.Longitude,
+			Accuracy:  geo.Accuracy,
+		}
+		if err := client.SetGeolocationOverride(context, coords); err != nil {
+			return err
+		}
+	}
+
+	// Locale and timezone were already applied at launch, so older browsers
+	// without the emulation commands still get them.
+	if opts.Locale != "" {
+		if err := client.SetLocaleOverride(context, opts.Locale); err != nil {
+			log.Debug("locale override not applied", "error", err)
+		}
+	}
+	if opts.Timezone != "" {
+		if err := client.SetTimezoneOverride(context, opts.Timezone); err != nil {
+			log.Debug("timezone override not applied", "error", err)
+		}
+	}
+
+	return nil
+}
+
+// GrantGeolocation grants the geolocation permission to the origin of pageURL
+// when opts emulate a position, so the page reads it without a prompt.
+func GrantGeolocation(client *bidi.Client, opts LaunchOptions, pageURL string) error {
+	if opts.Geolocation == nil {
+		return nil
+	}
+
+	origin := originOf(pageURL)
+	if origin == "" {
+		return nil
+	}
+	return client.SetPermission(origin, "geolocation", "granted")
+}
+
+// originOf returns the scheme://host[:port] origin of an http(s) URL, or "".
+func originOf(pageURL string) string {
+	u, err := url.Parse(pageURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
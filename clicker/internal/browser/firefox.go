@@ -0,0 +1,139 @@
+package browser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vibium/clicker/internal/paths"
+)
+
+// firefox launches system Firefox through geckodriver. Neither is downloaded
+// by 'clicker install'.
+type firefox struct{}
+
+func (firefox) driverName() string { return "geckodriver" }
+
+func (firefox) driverPath(opts LaunchOptions) (string, error) { return paths.GetGeckodriverPath() }
+
+func (firefox) executable(opts LaunchOptions) (string, error) { return paths.GetFirefoxExecutable() }
+
+func (firefox) installHint(opts LaunchOptions) string {
+	return "install Firefox and geckodriver, and put geckodriver in PATH"
+}
+
+// driverArgs also picks the port of Firefox's BiDi server: geckodriver's
+// default (9222) would keep two Firefox sessions from running side by side.
+func (firefox) driverArgs(port int) ([]string, error) {
+	wsPort, err := findAvailablePort()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find available port: %w", err)
+	}
+	return []string{"--port", strconv.Itoa(port), "--websocket-port", strconv.Itoa(wsPort)}, nil
+}
+
+// orphanPatterns matches only the Firefox instances geckodriver started,
+// which run on a temporary rust_mozprofile profile, never the user's own.
+func (firefox) orphanPatterns() []string {
+	return []string{"geckodriver", "rust_mozprofile"}
+}
+
+// capabilities builds moz:firefoxOptions. Firefox has no command-line flags
+// for most launch options, so they become preferences.
+func (firefox) capabilities(binary string, opts LaunchOptions) map[string]interface{} {
+	var args []string
+	if opts.Headless {
+		args = append(args, "-headless")
+	}
+	if opts.Viewport != nil && opts.Viewport.Width > 0 && opts.Viewport.Height > 0 {
+		args = append(args, "-width", strconv.Itoa(opts.Viewport.Width), "-height", strconv.Itoa(opts.Viewport.Height))
+	}
+	if opts.UserDataDir != "" {
+		args = append(args, "-profile", opts.UserDataDir)
+	}
+	args = append(args, opts.Args...)
+
+	prefs := map[string]interface{}{}
+	if opts.UserAgent != "" {
+		prefs["general.useragent.override"] = opts.UserAgent
+	}
+	if opts.Touch {
+		prefs["dom.w3c_touch_events.enabled"] = 1
+	}
+	if opts.Locale != "" {
+		prefs["intl.accept_languages"] = opts.Locale
+		prefs["intl.locale.requested"] = opts.Locale
+	}
+	if opts.DownloadDir != "" {
+		prefs["browser.download.dir"] = opts.DownloadDir
+		prefs["browser.download.folderList"] = 2 // use browser.download.dir
+		prefs["browser.download.useDownloadDir"] = true
+		prefs["browser.download.always_ask_before_handling_new_types"] = false
+	}
+
+	firefoxOpts := map[string]interface{}{}
+	if len(args) > 0 {
+		firefoxOpts["args"] = args
+	}
+	if binary != "" {
+		firefoxOpts["binary"] = binary
+	}
+	if len(prefs) > 0 {
+		firefoxOpts["prefs"] = prefs
+	}
+
+	caps := map[string]interface{}{
+		"browserName":        "firefox",
+		"moz:firefoxOptions": firefoxOpts,
+	}
+	if opts.ProxyServer != "" {
+		caps["proxy"] = firefoxProxy(opts)
+	}
+	return caps
+}
+
+// firefoxProxy returns the standard WebDriver proxy capability, which
+// geckodriver turns into Firefox's network.proxy prefs.
+func firefoxProxy(opts LaunchOptions) map[string]interface{} {
+	proxy := map[string]interface{}{"proxyType": "manual"}
+
+	scheme, host, _ := parseProxy(opts.ProxyServer)
+	switch scheme {
+	case "socks4":
+		proxy["socksProxy"] = host
+		proxy["socksVersion"] = 4
+	case "socks5":
+		proxy["socksProxy"] = host
+		proxy["socksVersion"] = 5
+	default:
+		proxy["httpProxy"] = host
+		proxy["sslProxy"] = host
+	}
+	if hosts := proxyBypassList(opts.ProxyBypass); len(hosts) > 0 {
+		proxy["noProxy"] = hosts
+	}
+	return proxy
+}
+
+// installExtensions installs unpacked extensions as temporary add-ons through
+// geckodriver, as Firefox has no flag to load them.
+func (firefox) installExtensions(baseURL, sessionID string, dirs []string) error {
+	client := &http.Client{Timeout: 30 * time.Second}
+	for _, dir := range dirs {
+		body, _ := json.Marshal(map[string]interface{}{"path": dir, "temporary": true})
+		resp, err := client.Post(baseURL+"/session/"+sessionID+"/moz/addon/install", "application/json", bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to install extension %s: %w", dir, err)
+		}
+		out, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("failed to install extension %s: HTTP %d: %s", dir, resp.StatusCode, bytes.TrimSpace(out))
+		}
+	}
+	return nil
+}
@@ -7,10 +7,14 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
+	"time"
 
+	errs "github.com/vibium/clicker/internal/errors"
 	"github.com/vibium/clicker/internal/paths"
 )
 
@@ -19,6 +23,9 @@ const (
 	lastKnownGoodURL     = "https://googlechromelabs.github.io/chrome-for-testing/last-known-good-versions-with-downloads.json"
 )
 
+// MirrorEnv sets the default InstallOptions.Mirror.
+const MirrorEnv = "VIBIUM_DOWNLOAD_MIRROR"
+
 // VersionInfo represents the Chrome for Testing version information.
 type VersionInfo struct {
 	Version   string              `json:"version"`
@@ -36,73 +43,215 @@ type LastKnownGoodResponse struct {
 	Channels map[string]VersionInfo `json:"channels"`
 }
 
+// KnownGoodResponse represents the API response for all known good versions,
+// oldest first.
+type KnownGoodResponse struct {
+	Versions []VersionInfo `json:"versions"`
+}
+
+// channels maps InstallOptions.Channel to the channel names of the API.
+var channels = map[string]string{
+	"stable": "Stable",
+	"beta":   "Beta",
+	"dev":    "Dev",
+	"canary": "Canary",
+}
+
+// InstallOptions selects the Chrome for Testing version to install.
+type InstallOptions struct {
+	// Version is an exact version ("131.0.6778.85") or a prefix ("131"),
+	// which installs the newest matching version.
+	Version string
+	// Channel installs the latest version of stable (default), beta, dev
+	// or canary. It cannot be combined with Version.
+	Channel string
+
+	// Mirror replaces the Chrome for Testing download host, e.g. an internal
+	// artifact server. It must have the same layout:
+	// <Mirror>/<version>/<platform>/chrome-<platform>.zip, and for Channel
+	// or a Version prefix, the known-good-versions JSON files at its root.
+	// Empty = $VIBIUM_DOWNLOAD_MIRROR, else Google's servers.
+	Mirror string
+
+	// FromZip and DriverZip install Chrome and chromedriver from local zip
+	// files instead of downloading them. Version must be the exact version.
+	FromZip   string
+	DriverZip string
+
+	// Checksums is a SHA-256 manifest (file or URL, see loadChecksums)
+	// every zip must match.
+	Checksums string
+
+	// Progress, if set, is called as the install proceeds.
+	Progress func(InstallProgress)
+}
+
+// Install stages reported through InstallOptions.Progress.
+const (
+	StageWaiting     = "waiting"     // for another install to release the lock
+	StageDownloading = "downloading" // reported repeatedly as bytes arrive
+	StageExtracting  = "extracting"
+)
+
+// InstallProgress reports what Install is doing.
+type InstallProgress struct {
+	Stage   string `json:"stage"`
+	Version string `json:"version,omitempty"`
+	// Name is "chrome" or "chromedriver" when downloading or extracting.
+	Name string `json:"name,omitempty"`
+	// Bytes downloaded so far, including those of an interrupted download
+	// that was resumed, and the Total size (0 if unknown).
+	Bytes int64 `json:"bytes,omitempty"`
+	Total int64 `json:"total,omitempty"`
+	// Resumed is the number of bytes reused from an interrupted download.
+	Resumed int64 `json:"resumed,omitempty"`
+}
+
+// zipSource is a Chrome for Testing zip to install: a URL, or a local file.
+type zipSource struct {
+	name string // "chrome" or "chromedriver"
+	url  string
+	file string
+}
+
 // InstallResult contains the paths to installed binaries.
 type InstallResult struct {
 	ChromePath      string
 	ChromedriverPath string
 	Version         string
+	// AlreadyInstalled is set when the version was already completely
+	// installed, e.g. by a parallel install Install waited for.
+	AlreadyInstalled bool
 }
 
-// Install downloads and installs Chrome for Testing and chromedriver.
-// Returns paths to the installed binaries.
-func Install() (*InstallResult, error) {
-	// Check for skip environment variable
-	if os.Getenv("VIBIUM_SKIP_BROWSER_DOWNLOAD") == "1" {
-		return nil, fmt.Errorf("browser download skipped (VIBIUM_SKIP_BROWSER_DOWNLOAD=1)")
-	}
-
+// Install downloads and installs Chrome for Testing and chromedriver, next
+// to any versions already installed. Returns paths to the installed binaries.
+//
+// Installs hold a lock in the cache, download into resumable ".part" files,
+// and extract into a temporary directory that is renamed into place. The
+// paths.InstallMarker file, written last, marks the version as complete;
+// a version directory without it is reinstalled.
+func Install(opts InstallOptions) (*InstallResult, error) {
 	platform := paths.GetPlatformString()
+	progress := opts.Progress
+	if progress == nil {
+		progress = func(InstallProgress) {}
+	}
 
-	// Fetch latest stable version info
-	versionInfo, err := fetchLatestStableVersion()
+	sums, err := loadChecksums(opts.Checksums)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch version info: %w", err)
+		return nil, err
+	}
+
+	var version string
+	var sources []zipSource
+	if opts.FromZip != "" || opts.DriverZip != "" {
+		if opts.FromZip == "" || opts.DriverZip == "" {
+			return nil, errs.InvalidArgument("--from-zip and --driver-zip must be used together")
+		}
+		if opts.Version == "" || opts.Channel != "" || opts.Mirror != "" {
+			return nil, errs.InvalidArgument("--from-zip needs the exact --version of the zips, and no --channel or --mirror")
+		}
+		version = opts.Version
+		sources = []zipSource{{name: "chrome", file: opts.FromZip}, {name: "chromedriver", file: opts.DriverZip}}
+	} else {
+		// Check for skip environment variable
+		if os.Getenv("VIBIUM_SKIP_BROWSER_DOWNLOAD") == "1" {
+			return nil, fmt.Errorf("browser download skipped (VIBIUM_SKIP_BROWSER_DOWNLOAD=1)")
+		}
+
+		mirror := opts.Mirror
+		if mirror == "" {
+			mirror = os.Getenv(MirrorEnv)
+		}
+		mirror = strings.TrimSuffix(mirror, "/")
+
+		versionInfo, err := resolveVersion(opts, mirror)
+		if err != nil {
+			return nil, err
+		}
+		version = versionInfo.Version
+
+		for _, name := range []string{"chrome", "chromedriver"} {
+			url := downloadURL(versionInfo, name, platform, mirror)
+			if url == "" {
+				return nil, fmt.Errorf("no %s download available for platform %s", name, platform)
+			}
+			sources = append(sources, zipSource{name: name, url: url})
+		}
 	}
 
-	fmt.Printf("Installing Chrome for Testing v%s...\n", versionInfo.Version)
+	if !validVersionDir(version) {
+		return nil, errs.InvalidArgument("invalid version %q", version)
+	}
 
-	// Create version directory
 	cftDir, err := paths.GetChromeForTestingDir()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cache dir: %w", err)
 	}
 
-	versionDir := filepath.Join(cftDir, versionInfo.Version)
-	if err := os.MkdirAll(versionDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create version dir: %w", err)
+	unlock, err := lockInstall(cftDir, func(p InstallProgress) {
+		p.Version = version
+		progress(p)
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	// Download and extract Chrome
-	chromeURL := findDownloadURL(versionInfo.Downloads["chrome"], platform)
-	if chromeURL == "" {
-		return nil, fmt.Errorf("no Chrome download available for platform %s", platform)
+	defer unlock()
+
+	// Another install may have finished this version while we waited
+	if installed, err := paths.FindInstalledChrome(version); err == nil && installed.Version == version && installed.ChromedriverPath != "" {
+		return &InstallResult{
+			ChromePath:       installed.ChromePath,
+			ChromedriverPath: installed.ChromedriverPath,
+			Version:          version,
+			AlreadyInstalled: true,
+		}, nil
 	}
 
-	fmt.Printf("Downloading Chrome from %s...\n", chromeURL)
-	if err := downloadAndExtract(chromeURL, versionDir); err != nil {
-		return nil, fmt.Errorf("failed to install Chrome: %w", err)
+	// Only the lock holder uses the work directory, so any leftover is from
+	// an install that died
+	workDir := filepath.Join(cftDir, ".tmp-"+version)
+	os.RemoveAll(workDir)
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create version dir: %w", err)
 	}
-
-	// Download and extract chromedriver
-	chromedriverURL := findDownloadURL(versionInfo.Downloads["chromedriver"], platform)
-	if chromedriverURL == "" {
-		return nil, fmt.Errorf("no chromedriver download available for platform %s", platform)
+	defer os.RemoveAll(workDir)
+
+	downloadDir := filepath.Join(cftDir, ".downloads")
+	for _, src := range sources {
+		src := src
+		report := func(p InstallProgress) {
+			p.Version = version
+			p.Name = src.name
+			progress(p)
+		}
+		if err := installZip(src, workDir, downloadDir, version+"/"+platform, sums, report); err != nil {
+			return nil, fmt.Errorf("failed to install %s: %w", src.name, err)
+		}
 	}
 
-	fmt.Printf("Downloading chromedriver from %s...\n", chromedriverURL)
-	if err := downloadAndExtract(chromedriverURL, versionDir); err != nil {
-		return nil, fmt.Errorf("failed to install chromedriver: %w", err)
+	// Replace a partial install, then mark the version complete
+	versionDir := filepath.Join(cftDir, version)
+	if err := os.RemoveAll(versionDir); err != nil {
+		return nil, fmt.Errorf("failed to remove partial install: %w", err)
+	}
+	if err := os.Rename(workDir, versionDir); err != nil {
+		return nil, fmt.Errorf("failed to move install into place: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(versionDir, paths.InstallMarker), []byte(version+"\n"), 0644); err != nil {
+		return nil, fmt.Errorf("failed to mark install complete: %w", err)
 	}
 
 	// Get paths to installed binaries
-	chromePath, err := paths.GetChromeExecutable()
+	installed, err := paths.FindInstalledChrome(version)
 	if err != nil {
 		return nil, fmt.Errorf("Chrome installed but not found: %w", err)
 	}
-
-	chromedriverPath, err := paths.GetChromedriverPath()
-	if err != nil {
-		return nil, fmt.Errorf("chromedriver installed but not found: %w", err)
+	chromePath := installed.ChromePath
+	chromedriverPath := installed.ChromedriverPath
+	if chromedriverPath == "" {
+		return nil, fmt.Errorf("chromedriver installed but not found in %s", installed.Dir)
 	}
 
 	// Make executable on Unix
@@ -114,33 +263,159 @@ func Install() (*InstallResult, error) {
 	return &InstallResult{
 		ChromePath:       chromePath,
 		ChromedriverPath: chromedriverPath,
-		Version:          versionInfo.Version,
+		Version:          version,
 	}, nil
 }
 
-// fetchLatestStableVersion fetches the latest stable Chrome for Testing version.
-func fetchLatestStableVersion() (*VersionInfo, error) {
-	resp, err := http.Get(lastKnownGoodURL)
-	if err != nil {
-		return nil, err
+// installZip downloads src into downloadDir if needed, checks it against
+// sums and extracts it to destDir. relDir is the zip's directory below the
+// download base. A download that fails the check or cannot be extracted is
+// deleted; an interrupted one is kept to be resumed.
+func installZip(src zipSource, destDir, downloadDir, relDir string, sums checksums, progress func(InstallProgress)) error {
+	zipPath := src.file
+	file := filepath.Base(src.file)
+	if src.url != "" {
+		file = path.Base(src.url)
+		zipPath = filepath.Join(downloadDir, strings.ReplaceAll(relDir, "/", "-")+"-"+file)
+		if err := download(src.url, zipPath, progress); err != nil {
+			return err
+		}
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	err := func() error {
+		if sums != nil {
+			digest, err := hashFile(zipPath)
+			if err != nil {
+				return err
+			}
+			if err := sums.verify(relDir+"/"+file, digest); err != nil {
+				return err
+			}
+		}
+		progress(InstallProgress{Stage: StageExtracting})
+		return extractZip(zipPath, destDir)
+	}()
+	if src.url != "" {
+		os.Remove(zipPath)
+	}
+	return err
+}
+
+// downloadURL returns where to download name ("chrome" or "chromedriver")
+// of a version from: the mirror if set, else the URL the API lists.
+func downloadURL(versionInfo *VersionInfo, name, platform, mirror string) string {
+	if mirror != "" {
+		return fmt.Sprintf("%s/%s/%s/%s-%s.zip", mirror, versionInfo.Version, platform, name, platform)
 	}
+	return findDownloadURL(versionInfo.Downloads[name], platform)
+}
+
+// validVersionDir reports whether version can name a version directory.
+func validVersionDir(version string) bool {
+	return version != "" && version != "." && version != ".." && !strings.ContainsAny(version, `/\`)
+}
+
+// isFullVersion reports whether version has all four parts, e.g. 131.0.6778.85.
+func isFullVersion(version string) bool {
+	parts := strings.Split(version, ".")
+	if len(parts) != 4 {
+		return false
+	}
+	for _, p := range parts {
+		if _, err := strconv.Atoi(p); err != nil {
+			return false
+		}
+	}
+	return true
+}
 
+// resolveVersion finds the version opts ask for. With a mirror, the version
+// JSON files come from the mirror, and an exact version needs none.
+func resolveVersion(opts InstallOptions, mirror string) (*VersionInfo, error) {
+	if opts.Version != "" && opts.Channel != "" {
+		return nil, errs.InvalidArgument("--version and --channel cannot be used together")
+	}
+
+	if opts.Version != "" {
+		if mirror != "" && isFullVersion(opts.Version) {
+			return &VersionInfo{Version: opts.Version}, nil
+		}
+		return fetchVersion(metadataURL(knownGoodVersionsURL, mirror), opts.Version)
+	}
+
+	channel := strings.ToLower(opts.Channel)
+	if channel == "" {
+		channel = "stable"
+	}
+	name, ok := channels[channel]
+	if !ok {
+		return nil, errs.InvalidArgument("unknown channel %q (expected stable, beta, dev or canary)", opts.Channel)
+	}
+
+	versionInfo, err := fetchLatestVersion(metadataURL(lastKnownGoodURL, mirror), name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch version info: %w", err)
+	}
+	return versionInfo, nil
+}
+
+// fetchLatestVersion fetches the latest Chrome for Testing version of a
+// channel ("Stable", "Beta", "Dev" or "Canary").
+func fetchLatestVersion(url, channel string) (*VersionInfo, error) {
 	var data LastKnownGoodResponse
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+	if err := fetchJSON(url, &data); err != nil {
 		return nil, err
 	}
 
-	stable, ok := data.Channels["Stable"]
+	info, ok := data.Channels[channel]
 	if !ok {
-		return nil, fmt.Errorf("no Stable channel found")
+		return nil, fmt.Errorf("no %s channel found", channel)
+	}
+
+	return &info, nil
+}
+
+// fetchVersion fetches the newest Chrome for Testing version that is version
+// or starts with it.
+func fetchVersion(url, version string) (*VersionInfo, error) {
+	var data KnownGoodResponse
+	if err := fetchJSON(url, &data); err != nil {
+		return nil, fmt.Errorf("failed to fetch version info: %w", err)
+	}
+
+	var found *VersionInfo
+	for i, v := range data.Versions {
+		if paths.MatchVersion(v.Version, version) && (found == nil || paths.CompareVersions(v.Version, found.Version) > 0) {
+			found = &data.Versions[i]
+		}
+	}
+	if found == nil {
+		return nil, errs.InvalidArgument("no Chrome for Testing version %s (see %s)", version, url)
+	}
+	return found, nil
+}
+
+// metadataURL returns the URL of a version JSON file, on the mirror if set.
+func metadataURL(url, mirror string) string {
+	if mirror == "" {
+		return url
+	}
+	return mirror + "/" + path.Base(url)
+}
+
+// fetchJSON gets url and decodes its JSON body into v.
+func fetchJSON(url string, v interface{}) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
 	}
 
-	return &stable, nil
+	return json.NewDecoder(resp.Body).Decode(v)
 }
 
 // findDownloadURL finds the download URL for the given platform.
@@ -153,37 +428,115 @@ func findDownloadURL(downloads []Download, platform string) string {
 	return ""
 }
 
-// downloadAndExtract downloads a zip file and extracts it to the destination.
-func downloadAndExtract(url, destDir string) error {
-	// Download to temp file
-	resp, err := http.Get(url)
+// progressInterval is the least time between two progress reports of a
+// download.
+const progressInterval = 250 * time.Millisecond
+
+// download downloads url to dest through dest+".part". A ".part" left by an
+// interrupted download is resumed with an HTTP Range request when the
+// server supports it, else the download starts over.
+func download(url, dest string, progress func(InstallProgress)) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	part := dest + ".part"
+
+	var offset int64
+	if info, err := os.Stat(part); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	flags := os.O_WRONLY | os.O_CREATE
+	switch {
+	case offset > 0 && resp.StatusCode == http.StatusPartialContent:
+		if !strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", offset)) {
+			return restartDownload(url, dest, progress)
+		}
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusOK:
+		offset = 0
+		flags |= os.O_TRUNC
+	case offset > 0 && resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// The part is complete if the server says that is the whole size
+		if resp.Header.Get("Content-Range") == fmt.Sprintf("bytes */%d", offset) {
+			return os.Rename(part, dest)
+		}
+		return restartDownload(url, dest, progress)
+	default:
 		return fmt.Errorf("HTTP %d", resp.StatusCode)
 	}
 
-	tmpFile, err := os.CreateTemp("", "chrome-*.zip")
+	out, err := os.OpenFile(part, flags, 0644)
 	if err != nil {
 		return err
 	}
-	tmpPath := tmpFile.Name()
-	defer os.Remove(tmpPath)
 
-	if _, err := io.Copy(tmpFile, resp.Body); err != nil {
-		tmpFile.Close()
+	p := InstallProgress{Stage: StageDownloading, Bytes: offset, Resumed: offset}
+	if resp.ContentLength >= 0 {
+		p.Total = offset + resp.ContentLength
+	}
+	progress(p)
+
+	buf := make([]byte, 32*1024)
+	last := time.Now()
+	for {
+		n, readErr := resp.Body.Read(buf)
+		if n > 0 {
+			if _, err := out.Write(buf[:n]); err != nil {
+				out.Close()
+				return err
+			}
+			p.Bytes += int64(n)
+			if time.Since(last) >= progressInterval {
+				last = time.Now()
+				progress(p)
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			out.Close()
+			return fmt.Errorf("download interrupted after %d bytes (run install again to resume): %w", p.Bytes, readErr)
+		}
+	}
+	if err := out.Close(); err != nil {
 		return err
 	}
-	tmpFile.Close()
+	progress(p)
+
+	if p.Total > 0 && p.Bytes != p.Total {
+		return fmt.Errorf("download incomplete: got %d of %d bytes (run install again to resume)", p.Bytes, p.Total)
+	}
+	return os.Rename(part, dest)
+}
 
-	// Extract zip
-	return extractZip(tmpPath, destDir)
+// restartDownload discards the ".part" of dest, which cannot be resumed,
+// and downloads url from the start.
+func restartDownload(url, dest string, progress func(InstallProgress)) error {
+	if err := os.Remove(dest + ".part"); err != nil {
+		return err
+	}
+	return download(url, dest, progress)
 }
 
-// extractZip extracts a zip file to the destination directory.
+// extractZip extracts a zip file to the destination directory. Entries that
+// would land outside destDir (absolute or ".." paths, zip slip) and symlinks
+// pointing outside it are rejected, as are files written through a symlink
+// that leads out.
 func extractZip(zipPath, destDir string) error {
 	r, err := zip.OpenReader(zipPath)
 	if err != nil {
@@ -191,44 +544,178 @@ func extractZip(zipPath, destDir string) error {
 	}
 	defer r.Close()
 
-	for _, f := range r.File {
-		fpath := filepath.Join(destDir, f.Name)
+	destDir, err = filepath.Abs(destDir)
+	if err != nil {
+		return err
+	}
+	realDest, err := filepath.EvalSymlinks(destDir)
+	if err != nil {
+		return err
+	}
 
-		// Security check: prevent zip slip
-		if !strings.HasPrefix(fpath, filepath.Clean(destDir)+string(os.PathSeparator)) {
-			return fmt.Errorf("invalid file path: %s", fpath)
+	for _, f := range r.File {
+		fpath, err := zipEntryPath(destDir, f.Name)
+		if err != nil {
+			return err
 		}
 
 		if f.FileInfo().IsDir() {
-			os.MkdirAll(fpath, os.ModePerm)
+			if err := os.MkdirAll(fpath, 0755); err != nil {
+				return err
+			}
 			continue
 		}
 
-		if err := os.MkdirAll(filepath.Dir(fpath), os.ModePerm); err != nil {
+		if err := os.MkdirAll(filepath.Dir(fpath), 0755); err != nil {
 			return err
 		}
-
-		outFile, err := os.OpenFile(fpath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
+		// A symlink extracted earlier must not lead the file elsewhere
+		realDir, err := filepath.EvalSymlinks(filepath.Dir(fpath))
 		if err != nil {
 			return err
 		}
+		if !within(realDest, realDir) {
+			return fmt.Errorf("invalid file path in zip: %s (leads out through a symlink)", f.Name)
+		}
 
-		rc, err := f.Open()
-		if err != nil {
-			outFile.Close()
+		if f.Mode()&os.ModeSymlink != 0 {
+			if err := extractSymlink(f, destDir, fpath); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := extractFile(f, fpath); err != nil {
 			return err
 		}
+	}
+
+	return nil
+}
+
+// zipEntryPath returns where a zip entry goes below destDir, rejecting
+// absolute paths and paths that climb out with "..".
+func zipEntryPath(destDir, name string) (string, error) {
+	clean := filepath.FromSlash(name)
+	if filepath.IsAbs(clean) || filepath.VolumeName(clean) != "" || strings.HasPrefix(name, "/") {
+		return "", fmt.Errorf("invalid file path in zip: %s", name)
+	}
+	fpath := filepath.Join(destDir, clean)
+	if !within(destDir, fpath) || fpath == destDir {
+		return "", fmt.Errorf("invalid file path in zip: %s", name)
+	}
+	return fpath, nil
+}
 
-		_, err = io.Copy(outFile, rc)
+// extractSymlink creates the symlink of a zip entry, if its target stays
+// inside destDir.
+func extractSymlink(f *zip.File, destDir, fpath string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	target, err := io.ReadAll(io.LimitReader(rc, 4096))
+	rc.Close()
+	if err != nil {
+		return err
+	}
+
+	link := filepath.FromSlash(string(target))
+	if filepath.IsAbs(link) || !within(destDir, filepath.Join(filepath.Dir(fpath), link)) {
+		return fmt.Errorf("invalid symlink in zip: %s -> %s", f.Name, target)
+	}
+	os.Remove(fpath)
+	return os.Symlink(link, fpath)
+}
+
+// extractFile writes a regular zip entry to fpath.
+func extractFile(f *zip.File, fpath string) error {
+	outFile, err := os.OpenFile(fpath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	rc, err := f.Open()
+	if err != nil {
 		outFile.Close()
-		rc.Close()
+		return err
+	}
 
-		if err != nil {
-			return err
+	_, err = io.Copy(outFile, rc)
+	outFile.Close()
+	rc.Close()
+	return err
+}
+
+// within reports whether path is dir or below it.
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator))
+}
+
+// Prune removes installed Chrome for Testing versions but the newest keep,
+// and the one pinned selects (default: VIBIUM_CHROME_VERSION). It also removes what interrupted
+// installs left behind: partial version directories, which are returned
+// with the removed versions, and work and download files. It returns the
+// removed versions.
+func Prune(keep int, pinned string) ([]paths.InstalledChrome, error) {
+	if keep < 0 {
+		return nil, errs.InvalidArgument("--keep must be 0 or more")
+	}
+
+	cftDir, err := paths.GetChromeForTestingDir()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(cftDir); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	// Don't remove the files of an install in progress
+	unlock, err := lockInstall(cftDir, func(InstallProgress) {})
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	installed, err := paths.ListInstalledChrome()
+	if err != nil {
+		return nil, err
+	}
+	if pinned == "" {
+		pinned = os.Getenv(paths.ChromeVersionEnv)
+	}
+
+	var removed []paths.InstalledChrome
+	complete := map[string]bool{}
+	for i, c := range installed {
+		complete[c.Dir] = true
+		if i < keep || (pinned != "" && paths.MatchVersion(c.Version, pinned)) {
+			continue
 		}
+		if err := os.RemoveAll(c.Dir); err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %w", c.Dir, err)
+		}
+		removed = append(removed, c)
 	}
 
-	return nil
+	entries, err := os.ReadDir(cftDir)
+	if err != nil {
+		return removed, err
+	}
+	for _, entry := range entries {
+		dir := filepath.Join(cftDir, entry.Name())
+		if !entry.IsDir() || complete[dir] {
+			continue
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %w", dir, err)
+		}
+		if !strings.HasPrefix(entry.Name(), ".") {
+			removed = append(removed, paths.InstalledChrome{Version: entry.Name(), Dir: dir})
+		}
+	}
+	return removed, nil
 }
 
 // IsInstalled checks if Chrome for Testing is already installed.
@@ -0,0 +1,52 @@
+package browser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// installLockFile, in the Chrome for Testing cache, serializes installs:
+// parallel postinstall runs (npm workspaces, pip) would otherwise download
+// into and extract over the same files.
+const installLockFile = ".install.lock"
+
+// lockPollInterval is how often lockInstall retries a held lock.
+const lockPollInterval = 200 * time.Millisecond
+
+// lockInstall takes the install lock of cftDir, waiting for other installs
+// to finish. The OS releases the lock if the process dies, so a crashed
+// install never blocks the next one. Call the returned func to unlock.
+func lockInstall(cftDir string, progress func(InstallProgress)) (func(), error) {
+	if err := os.MkdirAll(cftDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache dir: %w", err)
+	}
+
+	f, err := os.OpenFile(filepath.Join(cftDir, installLockFile), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open install lock: %w", err)
+	}
+
+	waiting := false
+	for {
+		ok, err := tryLockFile(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", f.Name(), err)
+		}
+		if ok {
+			break
+		}
+		if !waiting {
+			waiting = true
+			progress(InstallProgress{Stage: StageWaiting})
+		}
+		time.Sleep(lockPollInterval)
+	}
+
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
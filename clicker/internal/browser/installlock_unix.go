@@ -0,0 +1,23 @@
+//go:build !windows
+
+package browser
+
+import (
+	"os"
+	"syscall"
+)
+
+// tryLockFile takes an exclusive lock on f without blocking. It returns
+// false if another process holds it.
+func tryLockFile(f *os.File) (bool, error) {
+	err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// unlockFile releases the lock tryLockFile took.
+func unlockFile(f *os.File) {
+	syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
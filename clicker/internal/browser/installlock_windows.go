@@ -0,0 +1,41 @@
+//go:build windows
+
+package browser
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	modkernel32      = syscall.NewLazyDLL("kernel32.dll")
+	procLockFileEx   = modkernel32.NewProc("LockFileEx")
+	procUnlockFileEx = modkernel32.NewProc("UnlockFileEx")
+)
+
+const (
+	lockfileExclusiveLock   = 0x2
+	lockfileFailImmediately = 0x1
+	errorLockViolation      = syscall.Errno(33)
+)
+
+// tryLockFile takes an exclusive lock on f without blocking. It returns
+// false if another process holds it.
+func tryLockFile(f *os.File) (bool, error) {
+	var ol syscall.Overlapped
+	r, _, err := procLockFileEx.Call(f.Fd(), lockfileExclusiveLock|lockfileFailImmediately, 0, 1, 0, uintptr(unsafe.Pointer(&ol)))
+	if r != 0 {
+		return true, nil
+	}
+	if err == errorLockViolation {
+		return false, nil
+	}
+	return false, err
+}
+
+// unlockFile releases the lock tryLockFile took.
+func unlockFile(f *os.File) {
+	var ol syscall.Overlapped
+	procUnlockFileEx.Call(f.Fd(), 0, 1, 0, uintptr(unsafe.Pointer(&ol)))
+}
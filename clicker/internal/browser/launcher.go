@@ -9,10 +9,13 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"sync"
 	"time"
 
+	"github.com/vibium/clicker/internal/bidi"
+	"github.com/vibium/clicker/internal/devices"
+	errs "github.com/vibium/clicker/internal/errors"
 	"github.com/vibium/clicker/internal/log"
-	"github.com/vibium/clicker/internal/paths"
 	"github.com/vibium/clicker/internal/process"
 )
 
@@ -48,17 +51,113 @@ func (pw *prefixWriter) Write(p []byte) (n int, err error) {
 
 // LaunchOptions contains options for launching the browser.
 type LaunchOptions struct {
+	Browser  string // "chrome" (default) or "firefox", see ParseBrowser
 	Headless bool
-	Port     int  // Chromedriver port, 0 = auto-select
-	Verbose  bool // Show chromedriver output
+	Port     int  // Driver port, 0 = auto-select
+	Verbose  bool // Show driver output
+
+	// Viewport sizes the initial window. Launch cannot set the exact
+	// viewport itself; callers apply it with bidi.Client.SetViewport
+	// once connected.
+	Viewport  *devices.Viewport
+	UserAgent string // Override the browser user agent
+	Touch     bool   // Enable touch events
+
+	// Locale and Timezone are set through flags and environment at launch
+	// and, where the browser supports it, through BiDi emulation commands.
+	// Geolocation can only be set over BiDi. See ApplyEmulation.
+	Locale      string // e.g. "de-DE"
+	Timezone    string // IANA name, e.g. "Europe/Berlin"
+	Geolocation *devices.Geolocation
+
+	// DownloadDir is where downloads are saved without prompting.
+	// Empty keeps the browser default (downloads are blocked when headless).
+	DownloadDir string
+
+	// DialogPolicy handles alert, confirm, prompt and beforeunload dialogs
+	// nobody handles explicitly: "accept", "dismiss" (default) or "fail".
+	DialogPolicy string
+
+	// Pacing makes clicks, pointer moves and typing human-paced.
+	// nil = instant input.
+	Pacing *bidi.Pacing
+
+	// ConnectURL attaches to an existing browser instead of launching one
+	// (see Connect). Capabilities are added to the alwaysMatch capabilities
+	// of the WebDriver session, replacing defaults with the same name.
+	ConnectURL   string
+	Capabilities map[string]interface{}
+
+	// Args are extra browser arguments. IgnoreDefaultArgs drops Chrome's
+	// default arguments by name, e.g. "--disable-features" drops
+	// "--disable-features=TranslateUI".
+	Args              []string
+	IgnoreDefaultArgs []string
+
+	// UserDataDir keeps the profile (cookies, storage, logins) in a
+	// directory across runs; created if missing. Empty = fresh profile.
+	UserDataDir string
+
+	// ProxyServer routes traffic through [SCHEME://]HOST:PORT (http, https,
+	// socks4 or socks5), except to the comma-separated hosts of ProxyBypass.
+	ProxyServer string
+	ProxyBypass string
+
+	// Extensions are unpacked extension directories to load.
+	Extensions []string
+
+	// Env is added to the environment of the driver and the browser.
+	// Launch only.
+	Env map[string]string
+
+	// ExecutablePath and ChromedriverPath (geckodriver with Firefox) replace
+	// the browser and driver found in the cache or on the system.
+	// ChromedriverPath is Launch only. With Connect, ExecutablePath,
+	// UserDataDir and Extensions are paths on the driver's machine.
+	ExecutablePath   string
+	ChromedriverPath string
+
+	// ChromeVersion picks an installed Chrome for Testing, e.g.
+	// "131.0.6778.85" or "131" for the newest 131 (see
+	// paths.FindInstalledChrome). Empty = VIBIUM_CHROME_VERSION or the newest.
+	ChromeVersion string
+
+	// LaunchTimeout bounds starting the driver and, separately, creating the
+	// session. 0 = 30s.
+	LaunchTimeout time.Duration
 }
 
-// LaunchResult contains the result of launching the browser via chromedriver.
+// ApplyDevice configures the options to emulate a device profile.
+func (o *LaunchOptions) ApplyDevice(d *devices.Device) {
+	viewport := d.Viewport
+	o.Viewport = &viewport
+	o.UserAgent = d.UserAgent
+	o.Touch = d.Touch
+}
+
+// LaunchResult contains the result of launching the browser via its driver.
 type LaunchResult struct {
-	WebSocketURL   string
-	SessionID      string
-	ChromedriverCmd *exec.Cmd
-	Port           int
+	WebSocketURL    string
+	SessionID       string
+	ChromedriverCmd *exec.Cmd // the driver process: chromedriver or geckodriver
+	Port            int
+
+	// backend launched the browser; nil when connected.
+	backend backend
+
+	// driverURL is the WebDriver endpoint the session was created on.
+	driverURL string
+
+	// forget unregisters the process.OnKill cleanup of a connected session.
+	forget func()
+
+	// Watchdog state, see CrashError.
+	stderr   *ringBuffer
+	exited   chan struct{}
+	exitCode int
+
+	mu     sync.Mutex
+	closed bool
 }
 
 // sessionRequest is the payload for creating a new session.
@@ -91,53 +190,97 @@ type sessionValue struct {
 	Capabilities map[string]interface{} `json:"capabilities"`
 }
 
-// Launch starts chromedriver and creates a BiDi session.
+// Launch starts the driver of opts.Browser (chromedriver by default) and
+// creates a BiDi session. Failures to start the browser are returned as
+// *errs.LaunchError.
 func Launch(opts LaunchOptions) (*LaunchResult, error) {
-	log.Debug("launching browser", "headless", opts.Headless)
+	browserName, err := ParseBrowser(opts.Browser)
+	if err != nil {
+		return nil, err
+	}
+	b := backendFor(browserName)
+	log.Debug("launching browser", "browser", browserName, "headless", opts.Headless)
+
+	if err := validateProxy(opts); err != nil {
+		return nil, err
+	}
+	if err := resolveLocalPaths(&opts); err != nil {
+		return nil, err
+	}
 
-	chromedriverPath, err := paths.GetChromedriverPath()
+	driverPath, err := findExecutable(opts.ChromedriverPath, func() (string, error) { return b.driverPath(opts) })
 	if err != nil {
-		return nil, fmt.Errorf("chromedriver not found: %w (run 'clicker install' first)", err)
+		return nil, &errs.LaunchError{Cause: fmt.Errorf("%s not found: %w (%s)", b.driverName(), err, b.installHint(opts))}
 	}
-	log.Debug("found chromedriver", "path", chromedriverPath)
+	log.Debug("found driver", "path", driverPath)
 
-	chromePath, err := paths.GetChromeExecutable()
+	browserPath, err := findExecutable(opts.ExecutablePath, func() (string, error) { return b.executable(opts) })
 	if err != nil {
-		return nil, fmt.Errorf("Chrome not found: %w (run 'clicker install' first)", err)
+		return nil, &errs.LaunchError{Cause: fmt.Errorf("%s not found: %w (%s)", browserName, err, b.installHint(opts))}
+	}
+	log.Debug("found browser", "path", browserPath)
+
+	if opts.DownloadDir != "" {
+		dir, err := resolveDownloadDir(opts.DownloadDir)
+		if err != nil {
+			return nil, err
+		}
+		opts.DownloadDir = dir
 	}
-	log.Debug("found chrome", "path", chromePath)
 
 	// Find available port
 	port := opts.Port
 	if port == 0 {
 		port, err = findAvailablePort()
 		if err != nil {
-			return nil, fmt.Errorf("failed to find available port: %w", err)
+			return nil, &errs.LaunchError{Cause: fmt.Errorf("failed to find available port: %w", err)}
 		}
 	}
 	log.Debug("using port", "port", port)
 
-	// Start chromedriver as a process group leader so we can kill all children
-	cmd := exec.Command(chromedriverPath, fmt.Sprintf("--port=%d", port))
+	driverArgs, err := b.driverArgs(port)
+	if err != nil {
+		return nil, &errs.LaunchError{Cause: err}
+	}
+
+	// Start the driver as a process group leader so we can kill all children
+	cmd := exec.Command(driverPath, driverArgs...)
 	setProcGroup(cmd)
+	// The browser inherits the driver's environment, which sets its timezone and UI language
+	if env := append(emulationEnv(opts), envList(opts.Env)...); len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	// The tail of stderr goes into crash reports
+	stderr := newRingBuffer(stderrTailSize)
+	cmd.Stderr = stderr
 	if opts.Verbose {
-		fmt.Println("       ------- chromedriver -------")
+		fmt.Printf("       ------- %s -------\n", b.driverName())
 		pw := newPrefixWriter(os.Stdout, "       ")
 		cmd.Stdout = pw
-		cmd.Stderr = pw
+		cmd.Stderr = io.MultiWriter(pw, stderr)
 	}
 	if err := cmd.Start(); err != nil {
-		return nil, fmt.Errorf("failed to start chromedriver: %w", err)
+		return nil, &errs.LaunchError{Cause: fmt.Errorf("failed to start %s: %w", b.driverName(), err)}
 	}
 
 	// Track for cleanup
 	process.Track(cmd)
 
-	// Wait for chromedriver to be ready
 	baseURL := fmt.Sprintf("http://localhost:%d", port)
-	if err := waitForChromedriver(baseURL, 10*time.Second); err != nil {
+	result := &LaunchResult{
+		ChromedriverCmd: cmd,
+		Port:            port,
+		backend:         b,
+		driverURL:       baseURL,
+		stderr:          stderr,
+		exited:          make(chan struct{}),
+	}
+	go result.watch()
+
+	// Wait for the driver to be ready
+	if err := waitForChromedriver(baseURL, opts.launchTimeout(), result.exited); err != nil {
 		cmd.Process.Kill()
-		return nil, fmt.Errorf("chromedriver failed to start: %w", err)
+		return nil, &errs.LaunchError{Cause: withOutput(fmt.Errorf("%s failed to start: %w", b.driverName(), err), stderr)}
 	}
 
 	if opts.Verbose {
@@ -145,19 +288,35 @@ func Launch(opts LaunchOptions) (*LaunchResult, error) {
 	}
 
 	// Create session with BiDi enabled
-	sessionID, wsURL, err := createSession(baseURL, chromePath, opts.Headless, opts.Verbose)
+	sessionID, wsURL, err := createSession(baseURL, browserPath, opts)
 	if err != nil {
 		cmd.Process.Kill()
-		return nil, fmt.Errorf("failed to create session: %w", err)
+		return nil, &errs.LaunchError{Cause: fmt.Errorf("failed to create session: %w", err)}
 	}
 	log.Info("browser launched", "sessionId", sessionID, "wsUrl", wsURL)
 
-	return &LaunchResult{
-		WebSocketURL:    wsURL,
-		SessionID:       sessionID,
-		ChromedriverCmd: cmd,
-		Port:            port,
-	}, nil
+	result.WebSocketURL = wsURL
+	result.SessionID = sessionID
+	return result, nil
+}
+
+// findExecutable returns path if set and present, or else what find finds.
+func findExecutable(path string, find func() (string, error)) (string, error) {
+	if path == "" {
+		return find()
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// withOutput appends what the driver printed to stderr, if anything, to err.
+func withOutput(err error, stderr *ringBuffer) error {
+	if out := stderr.String(); out != "" {
+		return fmt.Errorf("%w\n%s", err, out)
+	}
+	return err
 }
 
 // findAvailablePort finds an available TCP port.
@@ -170,10 +329,15 @@ func findAvailablePort() (int, error) {
 	return listener.Addr().(*net.TCPAddr).Port, nil
 }
 
-// waitForChromedriver waits for chromedriver to be ready.
-func waitForChromedriver(baseURL string, timeout time.Duration) error {
+// waitForChromedriver waits for the driver to be ready, or to exit.
+func waitForChromedriver(baseURL string, timeout time.Duration, exited <-chan struct{}) error {
 	deadline := time.Now().Add(timeout)
 	for time.Now().Before(deadline) {
+		select {
+		case <-exited:
+			return fmt.Errorf("driver exited")
+		default:
+		}
 		resp, err := http.Get(baseURL + "/status")
 		if err == nil {
 			resp.Body.Close()
@@ -183,55 +347,23 @@ func waitForChromedriver(baseURL string, timeout time.Duration) error {
 		}
 		time.Sleep(100 * time.Millisecond)
 	}
-	return fmt.Errorf("timeout waiting for chromedriver")
+	return fmt.Errorf("timeout waiting for driver")
 }
 
-// createSession creates a new WebDriver session with BiDi enabled.
-func createSession(baseURL, chromePath string, headless, verbose bool) (string, string, error) {
-	args := []string{
-		"--no-first-run",
-		"--no-default-browser-check",
-		"--disable-infobars",
-		"--disable-blink-features=AutomationControlled",
-		"--disable-crash-reporter",
-		"--disable-background-networking",
-		"--disable-background-timer-throttling",
-		"--disable-backgrounding-occluded-windows",
-		"--disable-breakpad",
-		"--disable-component-extensions-with-background-pages",
-		"--disable-component-update",
-		"--disable-default-apps",
-		"--disable-dev-shm-usage",
-		"--disable-extensions",
-		"--disable-features=TranslateUI",
-		"--disable-hang-monitor",
-		"--disable-ipc-flooding-protection",
-		"--disable-popup-blocking",
-		"--disable-prompt-on-repost",
-		"--disable-renderer-backgrounding",
-		"--disable-sync",
-		"--enable-features=NetworkService,NetworkServiceInProcess",
-		"--force-color-profile=srgb",
-		"--metrics-recording-only",
-		"--password-store=basic",
-		"--use-mock-keychain",
-	}
-
-	if headless {
-		args = append(args, "--headless=new")
+// createSession creates a new WebDriver session with BiDi enabled for
+// opts.Browser. An empty binary leaves the choice of binary to the driver.
+func createSession(baseURL, binary string, opts LaunchOptions) (string, string, error) {
+	b := backendFor(opts.Browser)
+	alwaysMatch := b.capabilities(binary, opts)
+	alwaysMatch["webSocketUrl"] = true
+	// Dialogs are handled by the client according to DialogPolicy
+	alwaysMatch["unhandledPromptBehavior"] = "ignore"
+	for name, value := range opts.Capabilities {
+		alwaysMatch[name] = value
 	}
-
 	reqBody := map[string]interface{}{
 		"capabilities": map[string]interface{}{
-			"alwaysMatch": map[string]interface{}{
-				"browserName":  "chrome",
-				"webSocketUrl": true,
-				"goog:chromeOptions": map[string]interface{}{
-					"binary":          chromePath,
-					"args":            args,
-					"excludeSwitches": []string{"enable-automation"},
-				},
-			},
+			"alwaysMatch": alwaysMatch,
 		},
 	}
 
@@ -240,12 +372,13 @@ func createSession(baseURL, chromePath string, headless, verbose bool) (string,
 		return "", "", err
 	}
 
-	if verbose {
+	if opts.Verbose {
 		fmt.Println("       ------- POST /session -------")
 		fmt.Printf("       --> %s\n", string(jsonBody))
 	}
 
-	resp, err := http.Post(baseURL+"/session", "application/json", bytes.NewReader(jsonBody))
+	client := &http.Client{Timeout: opts.launchTimeout()}
+	resp, err := client.Post(baseURL+"/session", "application/json", bytes.NewReader(jsonBody))
 	if err != nil {
 		return "", "", err
 	}
@@ -261,7 +394,7 @@ func createSession(baseURL, chromePath string, headless, verbose bool) (string,
 		return "", "", fmt.Errorf("failed to read session response: %w", err)
 	}
 
-	if verbose {
+	if opts.Verbose {
 		fmt.Printf("       <-- %s\n", string(respBody))
 		fmt.Println("       ------------------------------")
 	}
@@ -271,38 +404,76 @@ func createSession(baseURL, chromePath string, headless, verbose bool) (string,
 		return "", "", fmt.Errorf("failed to decode session response: %w", err)
 	}
 
+	sessionID := sessResp.Value.SessionID
 	wsURL, ok := sessResp.Value.Capabilities["webSocketUrl"].(string)
 	if !ok || wsURL == "" {
+		deleteSession(baseURL, sessionID)
 		return "", "", fmt.Errorf("webSocketUrl not found in session capabilities")
 	}
 
-	return sessResp.Value.SessionID, wsURL, nil
+	if len(opts.Extensions) > 0 {
+		if err := b.installExtensions(baseURL, sessionID, opts.Extensions); err != nil {
+			deleteSession(baseURL, sessionID)
+			return "", "", err
+		}
+	}
+
+	return sessionID, wsURL, nil
+}
+
+// deleteSession ends a WebDriver session, which makes the driver quit the
+// browser. Errors are ignored: the browser may already be gone.
+func deleteSession(baseURL, sessionID string) {
+	if sessionID == "" {
+		return
+	}
+	req, _ := http.NewRequest(http.MethodDelete, baseURL+"/session/"+sessionID, nil)
+	if req != nil {
+		client := &http.Client{Timeout: 5 * time.Second}
+		client.Do(req)
+	}
 }
 
-// Close terminates a chromedriver session and process.
+// Close ends the WebDriver session and, if the browser was launched rather
+// than connected to, kills the driver and the browser.
 func (r *LaunchResult) Close() error {
 	log.Debug("closing browser", "sessionId", r.SessionID)
 
-	// Delete session first (tells chromedriver to quit Chrome gracefully)
-	if r.SessionID != "" && r.Port > 0 {
-		req, _ := http.NewRequest(http.MethodDelete, fmt.Sprintf("http://localhost:%d/session/%s", r.Port, r.SessionID), nil)
-		if req != nil {
-			client := &http.Client{Timeout: 5 * time.Second}
-			client.Do(req)
+	// From here on, the browser going away is no crash
+	r.mu.Lock()
+	r.closed = true
+	r.mu.Unlock()
+
+	if r.forget != nil {
+		r.forget()
+	}
+
+	// Delete session first (tells the driver to quit the browser gracefully)
+	if r.SessionID != "" && r.driverURL != "" {
+		deleteSession(r.driverURL, r.SessionID)
+		if r.ChromedriverCmd != nil {
+			// Give the browser a moment to quit gracefully
+			time.Sleep(500 * time.Millisecond)
 		}
-		// Give Chrome a moment to quit gracefully
-		time.Sleep(500 * time.Millisecond)
 	}
 
-	// Kill chromedriver and all its descendants
+	// Kill the driver and all its descendants
 	if r.ChromedriverCmd != nil && r.ChromedriverCmd.Process != nil {
 		pid := r.ChromedriverCmd.Process.Pid
 
-		// Kill the entire process tree (chromedriver + Chrome + all helpers)
-		killProcessTree(pid)
+		// Kill the entire process tree (driver + browser + all helpers)
+		var orphans []string
+		if r.backend != nil {
+			orphans = r.backend.orphanPatterns()
+		}
+		killProcessTree(pid, orphans)
 
-		// Wait for chromedriver to exit
-		r.ChromedriverCmd.Wait()
+		// Wait for the driver to exit
+		if r.exited != nil {
+			<-r.exited
+		} else {
+			r.ChromedriverCmd.Wait()
+		}
 
 		process.Untrack(r.ChromedriverCmd)
 	}
@@ -310,8 +481,9 @@ func (r *LaunchResult) Close() error {
 	return nil
 }
 
-// killProcessTree kills a process and all its descendants.
-func killProcessTree(pid int) {
+// killProcessTree kills a process and all its descendants, then orphaned
+// processes matching orphans.
+func killProcessTree(pid int, orphans []string) {
 	// First, find all descendant PIDs while parent relationships still exist
 	descendants := getDescendants(pid)
 
@@ -326,9 +498,9 @@ func killProcessTree(pid int) {
 	// Wait a moment for processes to die
 	time.Sleep(100 * time.Millisecond)
 
-	// Kill any orphaned Chrome processes that escaped
+	// Kill any orphaned browser processes that escaped
 	// (Chrome helpers sometimes get reparented to init before we can kill them)
-	killOrphanedChromeProcesses()
+	killOrphanedProcesses(orphans)
 }
 
 // getDescendants returns all descendant PIDs of a process (recursive).
@@ -359,12 +531,10 @@ func getDescendants(pid int) []int {
 	return descendants
 }
 
-// killOrphanedChromeProcesses finds and kills Chrome/chromedriver processes
-// that have been orphaned (reparented to init/launchd).
-func killOrphanedChromeProcesses() {
-	// Kill orphaned chromedriver and Chrome for Testing processes
-	patterns := []string{"chromedriver", "Chrome for Testing"}
-
+// killOrphanedProcesses finds and kills driver and browser processes whose
+// command line matches one of patterns and that have been orphaned
+// (reparented to init/launchd).
+func killOrphanedProcesses(patterns []string) {
 	for _, pattern := range patterns {
 		cmd := exec.Command("pgrep", "-f", pattern)
 		output, err := cmd.Output()
@@ -0,0 +1,135 @@
+package browser
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	errs "github.com/vibium/clicker/internal/errors"
+)
+
+// defaultLaunchTimeout is used when LaunchOptions.LaunchTimeout is 0.
+const defaultLaunchTimeout = 30 * time.Second
+
+// launchTimeout returns how long starting the driver, and separately
+// creating the session, may take.
+func (o LaunchOptions) launchTimeout() time.Duration {
+	if o.LaunchTimeout > 0 {
+		return o.LaunchTimeout
+	}
+	return defaultLaunchTimeout
+}
+
+// resolveLocalPaths makes the profile and extension directories of opts
+// absolute, creating the profile directory if needed. Only Launch calls it:
+// with Connect, the paths are on the driver's machine.
+func resolveLocalPaths(opts *LaunchOptions) error {
+	if opts.UserDataDir != "" {
+		dir, err := filepath.Abs(opts.UserDataDir)
+		if err != nil {
+			return errs.InvalidArgument("invalid user data directory: %w", err)
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return errs.InvalidArgument("cannot create user data directory: %w", err)
+		}
+		opts.UserDataDir = dir
+	}
+
+	var extensions []string
+	for _, ext := range opts.Extensions {
+		dir, err := filepath.Abs(ext)
+		if err != nil {
+			return errs.InvalidArgument("invalid extension path: %w", err)
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return errs.InvalidArgument("extension %s is not a directory (expected an unpacked extension)", ext)
+		}
+		extensions = append(extensions, dir)
+	}
+	opts.Extensions = extensions
+	return nil
+}
+
+// validateProxy checks ProxyServer, so a bad value fails before launching.
+func validateProxy(opts LaunchOptions) error {
+	if opts.ProxyServer == "" {
+		return nil
+	}
+	if _, _, err := parseProxy(opts.ProxyServer); err != nil {
+		return err
+	}
+	return nil
+}
+
+// parseProxy splits a proxy server, "[SCHEME://]HOST:PORT", into its scheme
+// (http by default) and host:port.
+func parseProxy(server string) (string, string, error) {
+	raw := server
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || u.Port() == "" {
+		return "", "", errs.InvalidArgument("invalid proxy server %q (expected [SCHEME://]HOST:PORT)", server)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks4", "socks5":
+	default:
+		return "", "", errs.InvalidArgument("unsupported proxy scheme %q (expected http, https, socks4 or socks5)", u.Scheme)
+	}
+	return u.Scheme, u.Host, nil
+}
+
+// proxyBypassList splits ProxyBypass, a comma- or semicolon-separated list.
+func proxyBypassList(bypass string) []string {
+	var hosts []string
+	for _, h := range strings.FieldsFunc(bypass, func(r rune) bool { return r == ',' || r == ';' }) {
+		if h = strings.TrimSpace(h); h != "" {
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts
+}
+
+// withoutArgs returns args minus those named in ignore. An ignored name
+// without a value, such as "--disable-features", also drops
+// "--disable-features=...".
+func withoutArgs(args, ignore []string) []string {
+	if len(ignore) == 0 {
+		return args
+	}
+
+	var kept []string
+	for _, arg := range args {
+		name, _, _ := strings.Cut(arg, "=")
+		drop := false
+		for _, ig := range ignore {
+			if ig == arg || ig == name {
+				drop = true
+				break
+			}
+		}
+		if !drop {
+			kept = append(kept, arg)
+		}
+	}
+	return kept
+}
+
+// envList returns env as KEY=VALUE pairs in a stable order.
+func envList(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	list := make([]string, 0, len(keys))
+	for _, k := range keys {
+		list = append(list, k+"="+env[k])
+	}
+	return list
+}
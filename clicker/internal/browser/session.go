@@ -0,0 +1,19 @@
+package browser
+
+import (
+	"github.com/vibium/clicker/internal/bidi"
+)
+
+// PrepareSession applies the session-wide parts of opts once connected:
+// download tracking and directory, the dialog policy and input pacing.
+// Call it after connecting and before navigating.
+func PrepareSession(client *bidi.Client, opts LaunchOptions) error {
+	if err := configureDownloads(client, opts); err != nil {
+		return err
+	}
+	if err := client.SetDialogPolicy(opts.DialogPolicy); err != nil {
+		return err
+	}
+	client.SetPacing(opts.Pacing)
+	return nil
+}
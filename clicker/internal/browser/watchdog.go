@@ -0,0 +1,108 @@
+package browser
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vibium/clicker/internal/bidi"
+	errs "github.com/vibium/clicker/internal/errors"
+)
+
+// stderrTailSize is how much of chromedriver's stderr is kept for crash
+// reports.
+const stderrTailSize = 4096
+
+// crashGrace is how long CrashError gives chromedriver to exit after the
+// connection to the browser was lost.
+const crashGrace = 500 * time.Millisecond
+
+// ringBuffer is an io.Writer that keeps only the last bytes written to it.
+type ringBuffer struct {
+	mu      sync.Mutex
+	buf     []byte
+	size    int
+	wrapped bool
+}
+
+func newRingBuffer(size int) *ringBuffer {
+	return &ringBuffer{size: size}
+}
+
+func (b *ringBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.buf = append(b.buf, p...)
+	if over := len(b.buf) - b.size; over > 0 {
+		b.buf = append(b.buf[:0], b.buf[over:]...)
+		b.wrapped = true
+	}
+	return len(p), nil
+}
+
+// String returns the kept output, starting at a line boundary once older
+// output has been dropped.
+func (b *ringBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := string(b.buf)
+	if b.wrapped {
+		if i := strings.IndexByte(s, '\n'); i >= 0 {
+			s = s[i+1:]
+		}
+	}
+	return strings.TrimSpace(s)
+}
+
+// watch waits for chromedriver to exit and records its exit status.
+func (r *LaunchResult) watch() {
+	r.ChromedriverCmd.Wait()
+	r.exitCode = r.ChromedriverCmd.ProcessState.ExitCode()
+	close(r.exited)
+}
+
+// Exited is closed when chromedriver exits, for whatever reason.
+func (r *LaunchResult) Exited() <-chan struct{} {
+	return r.exited
+}
+
+// CrashError tells why the browser went away: it returns an
+// *errs.BrowserCrashedError if chromedriver or Chrome exited on their own,
+// and nil while both are running or once Close was called. Call it when the
+// connection to the browser is lost; it waits briefly for chromedriver to
+// exit.
+func (r *LaunchResult) CrashError() error {
+	if r.exited == nil || r.isClosed() {
+		return nil
+	}
+
+	select {
+	case <-r.exited:
+		if r.isClosed() {
+			return nil
+		}
+		return &errs.BrowserCrashedError{ExitCode: r.exitCode, Output: r.stderr.String()}
+	case <-time.After(crashGrace):
+	}
+
+	// The driver outlives the browser, so look for it among its children
+	if len(getDescendants(r.ChromedriverCmd.Process.Pid)) == 0 {
+		return &errs.BrowserCrashedError{ExitCode: -1, Reason: "browser process exited", Output: r.stderr.String()}
+	}
+	return nil
+}
+
+// Watch makes client report crashes of this browser: commands fail with
+// *errs.BrowserCrashedError once the browser is gone, instead of with a
+// connection or protocol error.
+func (r *LaunchResult) Watch(client *bidi.Client) error {
+	return client.SetCrashCheck(r.CrashError)
+}
+
+func (r *LaunchResult) isClosed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.closed
+}
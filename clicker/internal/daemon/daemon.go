@@ -0,0 +1,308 @@
+// Package daemon keeps a browser session alive in a background process
+// behind a local Unix socket, so separate clicker commands can attach to it
+// instead of launching a browser each time.
+//
+// The daemon relays WebDriver BiDi messages between the browser and any
+// number of attached clients, renumbering command IDs so clients cannot
+// collide, and answers two commands of its own: daemon.status and daemon.stop.
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/vibium/clicker/internal/bidi"
+	"github.com/vibium/clicker/internal/browser"
+	errs "github.com/vibium/clicker/internal/errors"
+	"github.com/vibium/clicker/internal/log"
+	"github.com/vibium/clicker/internal/paths"
+	"github.com/vibium/clicker/internal/process"
+)
+
+// DefaultName is the session name used when none is given.
+const DefaultName = "default"
+
+// Daemon commands, sent like BiDi commands over the socket.
+const (
+	MethodStatus = "daemon.status"
+	MethodStop   = "daemon.stop"
+)
+
+// Status describes a running daemon.
+type Status struct {
+	Name    string                `json:"name"`
+	PID     int                   `json:"pid"`
+	Started time.Time             `json:"started"`
+	Socket  string                `json:"socket"`
+	Clients int                   `json:"clients"` // attached clients, including the one asking
+	Options browser.LaunchOptions `json:"options"`
+}
+
+// namePattern restricts session names to what is safe in a file name.
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// ValidateName checks that a session name is usable.
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return errs.InvalidArgument("invalid session name %q (use letters, digits, '.', '_' and '-')", name)
+	}
+	return nil
+}
+
+// Dir returns the directory holding daemon sockets and logs.
+func Dir() (string, error) {
+	cacheDir, err := paths.GetCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "sessions"), nil
+}
+
+// SocketPath returns the socket path of session name.
+func SocketPath(name string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".sock"), nil
+}
+
+// LogPath returns the path of the log file a started daemon writes to.
+func LogPath(name string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".log"), nil
+}
+
+// connect dials the daemon of session name.
+func connect(name string) (*bidi.Connection, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+	socket, err := SocketPath(name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(socket); err != nil {
+		return nil, fmt.Errorf("no daemon running for session %q (start one with 'clicker daemon start --session %s')", name, name)
+	}
+
+	conn, err := bidi.ConnectUnix(socket)
+	if err != nil {
+		return nil, fmt.Errorf("daemon for session %q is not responding (stale socket %s?): %w", name, socket, err)
+	}
+	return conn, nil
+}
+
+// isDead reports whether the daemon behind socket is gone: the socket does
+// not exist or nothing listens on it. A daemon that is busy or slow to
+// answer is alive, and its socket must stay.
+func isDead(socket string) bool {
+	conn, err := net.DialTimeout("unix", socket, time.Second)
+	if err == nil {
+		conn.Close()
+		return false
+	}
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ENOENT)
+}
+
+// status asks the daemon behind client for its status.
+func status(client *bidi.Client) (*Status, error) {
+	msg, err := client.SendCommand(MethodStatus, map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+
+	var s Status
+	if err := json.Unmarshal(msg.Result, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse daemon status: %w", err)
+	}
+	return &s, nil
+}
+
+// Attach connects to the daemon of session name and returns a client set up
+// with the session's dialog policy, input pacing and download directory.
+// Closing the client detaches; the browser keeps running.
+func Attach(name string) (*bidi.Client, *Status, error) {
+	conn, err := connect(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client := bidi.NewClient(conn)
+	s, err := status(client)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	if err := browser.PrepareSession(client, s.Options); err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to prepare session: %w", err)
+	}
+
+	return client, s, nil
+}
+
+// GetStatus returns the status of the daemon of session name.
+func GetStatus(name string) (*Status, error) {
+	conn, err := connect(name)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return status(bidi.NewClient(conn))
+}
+
+// List returns the status of every running daemon, sorted by name.
+// Sockets left behind by daemons that died are removed; daemons that are
+// alive but do not answer are left out.
+func List() ([]Status, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	sockets, err := filepath.Glob(filepath.Join(dir, "*.sock"))
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Status
+	for _, socket := range sockets {
+		name := strings.TrimSuffix(filepath.Base(socket), ".sock")
+		s, err := GetStatus(name)
+		if err != nil {
+			if isDead(socket) {
+				os.Remove(socket)
+			} else {
+				log.Warn("daemon is not responding", "session", name, "error", err)
+			}
+			continue
+		}
+		list = append(list, *s)
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// Stop asks the daemon of session name to close its browser and exit, and
+// waits up to timeout for its socket to go away.
+func Stop(name string, timeout time.Duration) error {
+	conn, err := connect(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = bidi.NewClient(conn).SendCommand(MethodStop, map[string]interface{}{})
+	conn.Close()
+	if err != nil {
+		return fmt.Errorf("failed to stop daemon: %w", err)
+	}
+
+	socket, err := SocketPath(name)
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(socket); os.IsNotExist(err) {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return fmt.Errorf("daemon for session %q did not exit within %s", name, timeout)
+}
+
+// Start runs `executable args...` as a detached background process, which
+// must end up calling Serve for session name, and waits up to timeout until
+// it accepts connections. Its output goes to the session's log file.
+func Start(name, executable string, args []string, timeout time.Duration) (*Status, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+	if s, err := GetStatus(name); err == nil {
+		return nil, fmt.Errorf("a daemon is already running for session %q (pid %d)", name, s.PID)
+	}
+
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	// A socket left by a daemon that died would make Serve fail
+	socket, err := SocketPath(name)
+	if err != nil {
+		return nil, err
+	}
+	if !isDead(socket) {
+		return nil, fmt.Errorf("a daemon is running for session %q but not responding; try again, or stop it with 'clicker daemon stop --session %s'", name, name)
+	}
+	os.Remove(socket)
+
+	logPath, err := LogPath(name)
+	if err != nil {
+		return nil, err
+	}
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create log file: %w", err)
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(executable, args...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	process.Detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start daemon: %w", err)
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		select {
+		case err := <-exited:
+			return nil, fmt.Errorf("daemon exited during startup (%v):\n%s", err, tail(logPath, 20))
+		case <-time.After(200 * time.Millisecond):
+		}
+
+		if s, err := GetStatus(name); err == nil {
+			return s, nil
+		}
+	}
+
+	cmd.Process.Kill()
+	return nil, fmt.Errorf("daemon did not start within %s:\n%s", timeout, tail(logPath, 20))
+}
+
+// tail returns the last n lines of a file, for error messages.
+func tail(path string, n int) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,451 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/vibium/clicker/internal/bidi"
+	"github.com/vibium/clicker/internal/browser"
+	errs "github.com/vibium/clicker/internal/errors"
+	"github.com/vibium/clicker/internal/log"
+)
+
+// clientQueueSize is how many messages may wait for a slow client before
+// further events for it are dropped.
+const clientQueueSize = 256
+
+// clientWriteTimeout is how long writing one message to a client may take
+// before the client is disconnected, so a client that stopped reading
+// cannot hold up responses for the others.
+const clientWriteTimeout = 10 * time.Second
+
+// server relays BiDi messages between one browser and its attached clients.
+type server struct {
+	name    string
+	socket  string
+	opts    browser.LaunchOptions
+	started time.Time
+	browser *bidi.Connection
+	launch  *browser.LaunchResult
+
+	mu      sync.Mutex
+	nextID  int64
+	pending map[int64]pendingCommand // daemon-assigned ID -> origin
+	clients map[*client]bool
+
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// pendingCommand remembers who sent a command relayed to the browser.
+type pendingCommand struct {
+	client *client         // nil for the daemon's own commands
+	id     json.RawMessage // the ID the client used
+}
+
+// client is an attached WebSocket connection.
+type client struct {
+	conn *websocket.Conn
+
+	out      chan []byte
+	done     chan struct{} // closed by finish
+	doneOnce sync.Once
+	written  chan struct{} // closed when the write loop ends
+}
+
+// Serve launches a browser with opts and serves it to clients on the socket
+// of session name until daemon.stop is received or the browser goes away.
+// Progress is printed to stdout.
+func Serve(name string, opts browser.LaunchOptions) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	socket, err := SocketPath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(socket), 0700); err != nil {
+		return fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	fmt.Printf("[daemon] Launching browser for session %q...\n", name)
+	launchResult, err := browser.Start(opts)
+	if err != nil {
+		return fmt.Errorf("failed to launch browser: %w", err)
+	}
+	defer launchResult.Close()
+
+	conn, err := bidi.Connect(launchResult.WebSocketURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to browser: %w", err)
+	}
+	defer conn.Close()
+
+	setup := bidi.NewClient(conn)
+	if err := browser.ApplyEmulation(setup, "", opts); err != nil {
+		return fmt.Errorf("failed to apply emulation: %w", err)
+	}
+	if err := browser.PrepareSession(setup, opts); err != nil {
+		return fmt.Errorf("failed to prepare session: %w", err)
+	}
+
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", socket, err)
+	}
+	defer os.Remove(socket)
+	os.Chmod(socket, 0600)
+
+	s := &server{
+		name:    name,
+		socket:  socket,
+		opts:    opts,
+		started: time.Now(),
+		browser: conn,
+		launch:  launchResult,
+		pending: make(map[int64]pendingCommand),
+		clients: make(map[*client]bool),
+		done:    make(chan struct{}),
+	}
+
+	upgrader := websocket.Upgrader{}
+	httpServer := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ws, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			s.serveClient(ws)
+		}),
+	}
+	go httpServer.Serve(listener)
+	go s.relayBrowser()
+
+	fmt.Printf("[daemon] Session %q ready on %s (pid %d)\n", name, socket, os.Getpid())
+
+	<-s.done
+
+	fmt.Println("[daemon] Shutting down...")
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	httpServer.Shutdown(ctx)
+	s.closeClients()
+	return nil
+}
+
+// stop ends Serve.
+func (s *server) stop(reason string) {
+	s.stopOnce.Do(func() {
+		fmt.Printf("[daemon] Stopping: %s\n", reason)
+		close(s.done)
+	})
+}
+
+// status describes the daemon.
+func (s *server) status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return Status{
+		Name:    s.name,
+		PID:     os.Getpid(),
+		Started: s.started,
+		Socket:  s.socket,
+		Clients: len(s.clients),
+		Options: s.opts,
+	}
+}
+
+// serveClient relays commands from an attached client until it disconnects.
+func (s *server) serveClient(ws *websocket.Conn) {
+	c := &client{
+		conn:    ws,
+		out:     make(chan []byte, clientQueueSize),
+		done:    make(chan struct{}),
+		written: make(chan struct{}),
+	}
+
+	s.mu.Lock()
+	s.clients[c] = true
+	s.mu.Unlock()
+
+	go c.writeLoop()
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, c)
+		for id, p := range s.pending {
+			if p.client == c {
+				// The browser still answers; the response is dropped
+				s.pending[id] = pendingCommand{}
+			}
+		}
+		s.mu.Unlock()
+		c.close()
+	}()
+
+	for {
+		_, data, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		s.handleCommand(c, data)
+	}
+}
+
+// handleCommand answers daemon commands and relays everything else to the
+// browser under a fresh ID.
+func (s *server) handleCommand(c *client, data []byte) {
+	var msg map[string]json.RawMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		c.send(errorResponse(nil, errs.InvalidArgument("invalid JSON: %v", err)))
+		return
+	}
+	id := msg["id"]
+
+	var method string
+	json.Unmarshal(msg["method"], &method)
+
+	switch method {
+	case MethodStatus:
+		c.send(successResponse(id, s.status()))
+		return
+	case MethodStop:
+		c.send(successResponse(id, map[string]interface{}{}))
+		s.stop("stop requested")
+		return
+	}
+
+	s.mu.Lock()
+	s.nextID++
+	relayID := s.nextID
+	s.pending[relayID] = pendingCommand{client: c, id: id}
+	s.mu.Unlock()
+
+	msg["id"], _ = json.Marshal(relayID)
+	relayed, err := json.Marshal(msg)
+	if err == nil {
+		err = s.browser.Send(string(relayed))
+	}
+	if err != nil {
+		s.mu.Lock()
+		delete(s.pending, relayID)
+		s.mu.Unlock()
+		c.send(errorResponse(id, &errs.ConnectionError{URL: s.launch.WebSocketURL, Cause: err}))
+	}
+}
+
+// relayBrowser routes browser messages: responses go back to whoever sent
+// the command, events go to every attached client.
+func (s *server) relayBrowser() {
+	for {
+		data, err := s.browser.Receive()
+		if err != nil {
+			var reason error = fmt.Errorf("browser connection lost: %w", err)
+			if crash := s.launch.CrashError(); crash != nil {
+				reason = crash
+			}
+			s.failPending(reason)
+			s.stop(reason.Error())
+			return
+		}
+
+		var msg map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(data), &msg); err != nil {
+			continue
+		}
+
+		rawID, isResponse := msg["id"]
+		if !isResponse {
+			s.broadcast([]byte(data), msg)
+			continue
+		}
+
+		var relayID int64
+		json.Unmarshal(rawID, &relayID)
+		s.mu.Lock()
+		p, ok := s.pending[relayID]
+		delete(s.pending, relayID)
+		s.mu.Unlock()
+		if !ok || p.client == nil {
+			continue
+		}
+
+		msg["id"] = p.id
+		if response, err := json.Marshal(msg); err == nil {
+			p.client.send(response)
+		}
+	}
+}
+
+// failPending answers every command still waiting for the browser with
+// err, so clients learn why before they are disconnected.
+func (s *server) failPending(err error) {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[int64]pendingCommand)
+	s.mu.Unlock()
+
+	for _, p := range pending {
+		if p.client == nil {
+			continue
+		}
+		p.client.send(errorResponse(p.id, err))
+	}
+}
+
+// broadcast sends an event to every attached client. With nobody attached,
+// dialogs would block the page until someone attaches, so the daemon
+// applies the session's dialog policy itself.
+func (s *server) broadcast(data []byte, msg map[string]json.RawMessage) {
+	s.mu.Lock()
+	clients := make([]*client, 0, len(s.clients))
+	for c := range s.clients {
+		clients = append(clients, c)
+	}
+	s.mu.Unlock()
+
+	for _, c := range clients {
+		c.sendEvent(data)
+	}
+
+	var method string
+	json.Unmarshal(msg["method"], &method)
+	if len(clients) == 0 && method == bidi.EventUserPromptOpened {
+		var p bidi.UserPromptOpenedParams
+		if err := json.Unmarshal(msg["params"], &p); err == nil && (p.Handler == "" || p.Handler == "ignore") {
+			s.command("browsingContext.handleUserPrompt", map[string]interface{}{
+				"context": p.Context,
+				"accept":  s.opts.DialogPolicy == bidi.DialogAccept,
+			})
+		}
+	}
+}
+
+// command sends a command of the daemon's own; its response is dropped.
+func (s *server) command(method string, params interface{}) {
+	s.mu.Lock()
+	s.nextID++
+	id := s.nextID
+	s.pending[id] = pendingCommand{}
+	s.mu.Unlock()
+
+	data, err := json.Marshal(bidi.Command{ID: id, Method: method, Params: params})
+	if err == nil {
+		s.browser.Send(string(data))
+	}
+}
+
+// closeClients disconnects every attached client, after writing what is
+// queued for it (such as the errors of failPending) if that is quick.
+func (s *server) closeClients() {
+	s.mu.Lock()
+	clients := make([]*client, 0, len(s.clients))
+	for c := range s.clients {
+		clients = append(clients, c)
+	}
+	s.mu.Unlock()
+
+	deadline := time.After(time.Second)
+	for _, c := range clients {
+		c.finish()
+		select {
+		case <-c.written:
+		case <-deadline:
+		}
+		c.conn.Close()
+	}
+}
+
+// send queues a response for the client, waiting for room in its queue:
+// a response is never dropped, or the client would wait for it forever.
+func (c *client) send(data []byte) {
+	select {
+	case c.out <- data:
+	case <-c.done:
+	}
+}
+
+// sendEvent queues an event for the client. If the client has stopped
+// reading and its queue is full, the event is dropped rather than stalling
+// the browser connection.
+func (c *client) sendEvent(data []byte) {
+	select {
+	case c.out <- data:
+	case <-c.done:
+	default:
+		log.Warn("daemon client is not reading, dropping event")
+	}
+}
+
+// close stops the write loop and closes the connection.
+func (c *client) close() {
+	c.finish()
+	c.conn.Close()
+}
+
+// finish stops queueing messages; the write loop ends once the queue is
+// written.
+func (c *client) finish() {
+	c.doneOnce.Do(func() { close(c.done) })
+}
+
+// writeLoop writes queued messages to the client. A client that cannot be
+// written to is disconnected.
+func (c *client) writeLoop() {
+	defer close(c.written)
+	for {
+		select {
+		case data := <-c.out:
+			c.write(data)
+		case <-c.done:
+			for {
+				select {
+				case data := <-c.out:
+					c.write(data)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// write writes one message to the client, disconnecting it on failure.
+func (c *client) write(data []byte) {
+	c.conn.SetWriteDeadline(time.Now().Add(clientWriteTimeout))
+	if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		c.conn.Close()
+		c.finish()
+	}
+}
+
+// successResponse builds a BiDi success response.
+func successResponse(id json.RawMessage, result interface{}) []byte {
+	data, _ := json.Marshal(map[string]interface{}{
+		"id":     id,
+		"type":   "success",
+		"result": result,
+	})
+	return data
+}
+
+// errorResponse builds a BiDi error response for err. Its "error" is the
+// errs code of err, so clients exit with the status they would without
+// the daemon (see errs.ProtocolError).
+func errorResponse(id json.RawMessage, err error) []byte {
+	data, _ := json.Marshal(map[string]interface{}{
+		"id":      id,
+		"type":    "error",
+		"error":   string(errs.CodeOf(err)),
+		"message": err.Error(),
+	})
+	return data
+}
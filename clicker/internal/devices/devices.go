@@ -0,0 +1,145 @@
+// Package devices provides a catalog of device profiles and the other
+// settings used for browser emulation.
+package devices
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	errs "github.com/vibium/clicker/internal/errors"
+)
+
+// Viewport describes the page viewport size and pixel density.
+type Viewport struct {
+	Width            int
+	Height           int
+	DevicePixelRatio float64 // 0 = browser default
+}
+
+// Device describes an emulated device.
+type Device struct {
+	Name      string
+	Viewport  Viewport
+	UserAgent string
+	Touch     bool
+	Mobile    bool
+}
+
+const (
+	iOSUserAgent     = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1"
+	iPadUserAgent    = "Mozilla/5.0 (iPad; CPU OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1"
+	androidUserAgent = "Mozilla/5.0 (Linux; Android 14; %s) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Mobile Safari/537.36"
+	tabletUserAgent  = "Mozilla/5.0 (Linux; Android 14; %s) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36"
+)
+
+// TouchPoints is the navigator.maxTouchPoints of touch devices when they are
+// emulated after launch, as in Chrome's device mode.
+const TouchPoints = 1
+
+// catalog is the built-in list of device profiles.
+var catalog = []Device{
+	{Name: "iPhone SE", Viewport: Viewport{375, 667, 2}, UserAgent: iOSUserAgent, Touch: true, Mobile: true},
+	{Name: "iPhone 15", Viewport: Viewport{393, 852, 3}, UserAgent: iOSUserAgent, Touch: true, Mobile: true},
+	{Name: "iPhone 15 Pro Max", Viewport: Viewport{430, 932, 3}, UserAgent: iOSUserAgent, Touch: true, Mobile: true},
+	{Name: "iPad Mini", Viewport: Viewport{768, 1024, 2}, UserAgent: iPadUserAgent, Touch: true, Mobile: true},
+	{Name: "iPad Pro 11", Viewport: Viewport{834, 1194, 2}, UserAgent: iPadUserAgent, Touch: true, Mobile: true},
+	{Name: "Pixel 5", Viewport: Viewport{393, 851, 2.75}, UserAgent: fmt.Sprintf(androidUserAgent, "Pixel 5"), Touch: true, Mobile: true},
+	{Name: "Pixel 7", Viewport: Viewport{412, 915, 2.625}, UserAgent: fmt.Sprintf(androidUserAgent, "Pixel 7"), Touch: true, Mobile: true},
+	{Name: "Galaxy S23", Viewport: Viewport{360, 780, 3}, UserAgent: fmt.Sprintf(androidUserAgent, "SM-S911B"), Touch: true, Mobile: true},
+	{Name: "Galaxy Tab S8", Viewport: Viewport{800, 1280, 2}, UserAgent: fmt.Sprintf(tabletUserAgent, "SM-X700"), Touch: true, Mobile: true},
+	{Name: "Desktop HD", Viewport: Viewport{1280, 720, 1}},
+	{Name: "Desktop FHD", Viewport: Viewport{1920, 1080, 1}},
+	{Name: "Laptop", Viewport: Viewport{1366, 768, 1}},
+	{Name: "MacBook Pro 14", Viewport: Viewport{1512, 982, 2}},
+}
+
+// Lookup returns the device profile with the given name (case-insensitive).
+func Lookup(name string) (*Device, error) {
+	for _, d := range catalog {
+		if strings.EqualFold(d.Name, name) {
+			device := d
+			return &device, nil
+		}
+	}
+	return nil, errs.InvalidArgument("unknown device %q (run 'clicker devices' to list profiles)", name)
+}
+
+// List returns all built-in device profiles sorted by name.
+func List() []Device {
+	list := make([]Device, len(catalog))
+	copy(list, catalog)
+	sort.Slice(list, func(i, j int) bool {
+		return strings.ToLower(list[i].Name) < strings.ToLower(list[j].Name)
+	})
+	return list
+}
+
+// ParseViewport parses a "WIDTHxHEIGHT" string such as "1280x720".
+func ParseViewport(s string) (*Viewport, error) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(s)), "x")
+	if len(parts) != 2 {
+		return nil, errs.InvalidArgument("invalid viewport %q (expected WIDTHxHEIGHT, e.g. 1280x720)", s)
+	}
+
+	width, err := strconv.Atoi(parts[0])
+	if err != nil || width <= 0 {
+		return nil, errs.InvalidArgument("invalid viewport width in %q", s)
+	}
+	height, err := strconv.Atoi(parts[1])
+	if err != nil || height <= 0 {
+		return nil, errs.InvalidArgument("invalid viewport height in %q", s)
+	}
+
+	return &Viewport{Width: width, Height: height}, nil
+}
+
+// Geolocation is an emulated position.
+type Geolocation struct {
+	Latitude  float64
+	Longitude float64
+	Accuracy  float64 // meters, 0 = browser default
+}
+
+// ParseGeolocation parses a "LAT,LON" or "LAT,LON,ACCURACY" string such as "52.52,13.40".
+func ParseGeolocation(s string) (*Geolocation, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 && len(parts) != 3 {
+		return nil, errs.InvalidArgument("invalid geolocation %q (expected LAT,LON[,ACCURACY])", s)
+	}
+
+	values := make([]float64, len(parts))
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, errs.InvalidArgument("invalid geolocation %q: %w", s, err)
+		}
+		values[i] = v
+	}
+
+	geo := &Geolocation{Latitude: values[0], Longitude: values[1]}
+	if len(values) == 3 {
+		geo.Accuracy = values[2]
+	}
+
+	if err := geo.Validate(); err != nil {
+		return nil, err
+	}
+	return geo, nil
+}
+
+// Validate checks that the position is on Earth and the accuracy is not
+// negative.
+func (g *Geolocation) Validate() error {
+	if g.Latitude < -90 || g.Latitude > 90 {
+		return errs.InvalidArgument("latitude %g out of range (-90 to 90)", g.Latitude)
+	}
+	if g.Longitude < -180 || g.Longitude > 180 {
+		return errs.InvalidArgument("longitude %g out of range (-180 to 180)", g.Longitude)
+	}
+	if g.Accuracy < 0 {
+		return errs.InvalidArgument("accuracy %g must not be negative", g.Accuracy)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package errors
+
+import (
+	stderrors "errors"
+)
+
+// Code classifies an error for machines: it is the "code" of CLI --json
+// errors, the "error" field of proxy BiDi error responses and the "code" of
+// MCP error results. Codes are stable; messages are not.
+type Code string
+
+// Error codes.
+const (
+	CodeUnknown          Code = "unknown_error"
+	CodeInvalidArgument  Code = "invalid_argument"
+	CodeElementNotFound  Code = "element_not_found"
+	CodeNotActionable    Code = "not_actionable"
+	CodeTimeout          Code = "timeout"
+	CodeNavigationFailed Code = "navigation_failed"
+	CodeUnexpectedDialog Code = "unexpected_dialog"
+	CodeProtocolError    Code = "protocol_error"
+	CodeConnectionFailed Code = "connection_failed"
+	CodeLaunchFailed     Code = "launch_failed"
+	CodeBrowserCrashed   Code = "browser_crashed"
+	CodeStepFailed       Code = "step_failed"
+)
+
+// exitCodes are the CLI exit statuses of each code. 1 is the generic
+// failure status (also used by 'clicker run' when a step failed); 2 is the
+// conventional status for usage errors.
+var exitCodes = map[Code]int{
+	CodeUnknown:          1,
+	CodeStepFailed:       1,
+	CodeInvalidArgument:  2,
+	CodeElementNotFound:  3,
+	CodeNotActionable:    4,
+	CodeTimeout:          5,
+	CodeNavigationFailed: 6,
+	CodeUnexpectedDialog: 7,
+	CodeProtocolError:    8,
+	CodeConnectionFailed: 9,
+	CodeLaunchFailed:     10,
+	CodeBrowserCrashed:   11,
+}
+
+// ExitCode returns the CLI exit status for code.
+func (c Code) ExitCode() int {
+	if status, ok := exitCodes[c]; ok {
+		return status
+	}
+	return 1
+}
+
+// coder is implemented by errors that know their code.
+type coder interface {
+	ErrorCode() Code
+}
+
+// CodeOf returns the code of the first error in err's chain that has one,
+// or CodeUnknown.
+func CodeOf(err error) Code {
+	var c coder
+	if stderrors.As(err, &c) {
+		return c.ErrorCode()
+	}
+	return CodeUnknown
+}
@@ -23,11 +23,18 @@ func (e *ConnectionError) Unwrap() error {
 	return e.Cause
 }
 
+func (e *ConnectionError) ErrorCode() Code {
+	return CodeConnectionFailed
+}
+
 // TimeoutError is returned when a wait operation times out.
+// Cause, if set, is what was still wrong when time ran out, e.g. an
+// ElementNotFoundError or a NotActionableError.
 type TimeoutError struct {
 	Selector string
 	Timeout  time.Duration
 	Reason   string
+	Cause    error
 }
 
 func (e *TimeoutError) Error() string {
@@ -37,6 +44,19 @@ func (e *TimeoutError) Error() string {
 	return fmt.Sprintf("timeout after %s waiting for '%s'", e.Timeout, e.Selector)
 }
 
+func (e *TimeoutError) Unwrap() error {
+	return e.Cause
+}
+
+// ErrorCode returns the code of Cause if it has one: waiting for an element
+// that never appears is element_not_found, not timeout.
+func (e *TimeoutError) ErrorCode() Code {
+	if code := CodeOf(e.Cause); code != CodeUnknown {
+		return code
+	}
+	return CodeTimeout
+}
+
 // ElementNotFoundError is returned when a selector matches no elements.
 type ElementNotFoundError struct {
 	Selector string
@@ -50,15 +70,177 @@ func (e *ElementNotFoundError) Error() string {
 	return fmt.Sprintf("element not found: %s", e.Selector)
 }
 
-// BrowserCrashedError is returned when the browser process dies unexpectedly.
+func (e *ElementNotFoundError) ErrorCode() Code {
+	return CodeElementNotFound
+}
+
+// NotActionableError is returned when an element exists but an
+// actionability check (visible, stable, receivesEvents, enabled, editable)
+// fails.
+type NotActionableError struct {
+	Selector string
+	Check    string
+	Reason   string
+}
+
+func (e *NotActionableError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("element '%s' is not actionable: %s check failed (%s)", e.Selector, e.Check, e.Reason)
+	}
+	return fmt.Sprintf("element '%s' is not actionable: %s check failed", e.Selector, e.Check)
+}
+
+func (e *NotActionableError) ErrorCode() Code {
+	return CodeNotActionable
+}
+
+// NavigationFailedError is returned when the browser cannot load a URL,
+// e.g. because the host does not resolve.
+type NavigationFailedError struct {
+	URL   string
+	Cause error
+}
+
+func (e *NavigationFailedError) Error() string {
+	return fmt.Sprintf("failed to navigate to %s: %v", e.URL, e.Cause)
+}
+
+func (e *NavigationFailedError) Unwrap() error {
+	return e.Cause
+}
+
+func (e *NavigationFailedError) ErrorCode() Code {
+	return CodeNavigationFailed
+}
+
+// ProtocolError is returned when the browser answers a BiDi command with an
+// error response.
+type ProtocolError struct {
+	BiDiCode string // e.g. "no such frame"
+	Message  string
+}
+
+func (e *ProtocolError) Error() string {
+	if e.Message == "" || e.Message == e.BiDiCode {
+		return fmt.Sprintf("BiDi error: %s", e.BiDiCode)
+	}
+	return fmt.Sprintf("BiDi error: %s - %s", e.BiDiCode, e.Message)
+}
+
+// ErrorCode maps the BiDi error codes that have a counterpart; the rest
+// are protocol_error. Codes of clicker's own, as sent by the proxy and the
+// daemon, are kept.
+func (e *ProtocolError) ErrorCode() Code {
+	if _, ok := exitCodes[Code(e.BiDiCode)]; ok {
+		return Code(e.BiDiCode)
+	}
+	switch e.BiDiCode {
+	case "no such element", "no such node":
+		return CodeElementNotFound
+	case "invalid argument":
+		return CodeInvalidArgument
+	case "unexpected alert open":
+		return CodeUnexpectedDialog
+	case "timeout":
+		return CodeTimeout
+	}
+	return CodeProtocolError
+}
+
+// InvalidArgumentError is returned when a command or tool is given a
+// missing or malformed argument.
+type InvalidArgumentError struct {
+	Err error
+}
+
+// InvalidArgument returns an InvalidArgumentError with a message formatted
+// as by fmt.Errorf.
+func InvalidArgument(format string, args ...interface{}) error {
+	return &InvalidArgumentError{Err: fmt.Errorf(format, args...)}
+}
+
+func (e *InvalidArgumentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *InvalidArgumentError) Unwrap() error {
+	return e.Err
+}
+
+func (e *InvalidArgumentError) ErrorCode() Code {
+	return CodeInvalidArgument
+}
+
+// LaunchError is returned when the browser cannot be started, e.g. because
+// it is not installed.
+type LaunchError struct {
+	Cause error
+}
+
+func (e *LaunchError) Error() string {
+	return e.Cause.Error()
+}
+
+func (e *LaunchError) Unwrap() error {
+	return e.Cause
+}
+
+func (e *LaunchError) ErrorCode() Code {
+	return CodeLaunchFailed
+}
+
+// StepFailedError is returned by 'clicker run' when a step of a script
+// failed. The code of each failed step is in the report.
+type StepFailedError struct {
+	Script  string
+	Summary string
+}
+
+func (e *StepFailedError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Script, e.Summary)
+}
+
+func (e *StepFailedError) ErrorCode() Code {
+	return CodeStepFailed
+}
+
+// BrowserCrashedError is returned when the browser process dies unexpectedly
+// or a page crashes. ExitCode is chromedriver's exit status, or -1 if it is
+// still running or was killed; Output is the tail of its stderr.
 type BrowserCrashedError struct {
 	ExitCode int
+	Reason   string // what happened if chromedriver did not exit, e.g. "browser process exited"
 	Output   string
 }
 
 func (e *BrowserCrashedError) Error() string {
+	msg := fmt.Sprintf("browser crashed with exit code %d", e.ExitCode)
+	if e.Reason != "" {
+		msg = "browser crashed: " + e.Reason
+	}
 	if e.Output != "" {
-		return fmt.Sprintf("browser crashed with exit code %d: %s", e.ExitCode, e.Output)
+		return msg + ": " + e.Output
 	}
-	return fmt.Sprintf("browser crashed with exit code %d", e.ExitCode)
+	return msg
+}
+
+func (e *BrowserCrashedError) ErrorCode() Code {
+	return CodeBrowserCrashed
+}
+
+// UnexpectedDialogError is returned when a JavaScript dialog (alert, confirm,
+// prompt or beforeunload) blocks a command and the dialog policy is "fail".
+// The dialog stays open until it is handled explicitly.
+type UnexpectedDialogError struct {
+	Type    string // "alert", "confirm", "prompt" or "beforeunload"
+	Message string
+	Context string // browsing context ID
+}
+
+func (e *UnexpectedDialogError) Error() string {
+	return fmt.Sprintf("blocked by %s dialog: %q (accept or dismiss it to continue)", e.Type, e.Message)
+}
+
+func (e *UnexpectedDialogError) ErrorCode() Code {
+	return CodeUnexpectedDialog
 }
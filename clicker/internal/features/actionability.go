@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/vibium/clicker/internal/bidi"
+	errs "github.com/vibium/clicker/internal/errors"
 )
 
 // ActionabilityResult contains all actionability check results.
@@ -15,6 +16,10 @@ type ActionabilityResult struct {
 	ReceivesEvents bool `json:"receivesEvents"`
 	Enabled        bool `json:"enabled"`
 	Editable       bool `json:"editable"`
+
+	// Reasons explains each failed check, keyed by its JSON name, e.g.
+	// "receivesEvents": "obscured by div".
+	Reasons map[string]string `json:"reasons,omitempty"`
 }
 
 // CheckVisible verifies the element has a non-empty bounding box and is not hidden.
@@ -23,6 +28,12 @@ type ActionabilityResult struct {
 // - visibility is not "hidden"
 // - display is not "none"
 func CheckVisible(client *bidi.Client, context, selector string) (bool, error) {
+	ok, _, err := checkVisible(client, context, selector)
+	return ok, err
+}
+
+// checkVisible is CheckVisible, also returning why the check failed.
+func checkVisible(client *bidi.Client, context, selector string) (bool, string, error) {
 	script := `
 		(selector) => {
 			const el = document.querySelector(selector);
@@ -47,22 +58,23 @@ func CheckVisible(client *bidi.Client, context, selector string) (bool, error) {
 
 	result, err := callCheckFunction(client, context, selector, script)
 	if err != nil {
-		return false, err
+		return false, "", err
 	}
 
 	var data struct {
 		Visible bool   `json:"visible"`
+		Reason  string `json:"reason,omitempty"`
 		Error   string `json:"error,omitempty"`
 	}
 	if err := json.Unmarshal([]byte(result), &data); err != nil {
-		return false, fmt.Errorf("failed to parse visibility result: %w", err)
+		return false, "", fmt.Errorf("failed to parse visibility result: %w", err)
 	}
 
 	if data.Error != "" {
-		return false, fmt.Errorf("element %s", data.Error)
+		return false, "", elementError(selector, data.Error)
 	}
 
-	return data.Visible, nil
+	return data.Visible, data.Reason, nil
 }
 
 // CheckStable verifies the element's bounding box hasn't changed between two checks.
@@ -95,6 +107,12 @@ func CheckStable(client *bidi.Client, context, selector string) (bool, error) {
 // CheckReceivesEvents verifies the element is the hit target at its center point.
 // Uses elementFromPoint() to check if the element (or a descendant) receives pointer events.
 func CheckReceivesEvents(client *bidi.Client, context, selector string) (bool, error) {
+	ok, _, err := checkReceivesEvents(client, context, selector)
+	return ok, err
+}
+
+// checkReceivesEvents is CheckReceivesEvents, also returning why the check failed.
+func checkReceivesEvents(client *bidi.Client, context, selector string) (bool, string, error) {
 	script := `
 		(selector) => {
 			const el = document.querySelector(selector);
@@ -125,22 +143,23 @@ func CheckReceivesEvents(client *bidi.Client, context, selector string) (bool, e
 
 	result, err := callCheckFunction(client, context, selector, script)
 	if err != nil {
-		return false, err
+		return false, "", err
 	}
 
 	var data struct {
 		ReceivesEvents bool   `json:"receivesEvents"`
+		Reason         string `json:"reason,omitempty"`
 		Error          string `json:"error,omitempty"`
 	}
 	if err := json.Unmarshal([]byte(result), &data); err != nil {
-		return false, fmt.Errorf("failed to parse receivesEvents result: %w", err)
+		return false, "", fmt.Errorf("failed to parse receivesEvents result: %w", err)
 	}
 
 	if data.Error != "" {
-		return false, fmt.Errorf("element %s", data.Error)
+		return false, "", elementError(selector, data.Error)
 	}
 
-	return data.ReceivesEvents, nil
+	return data.ReceivesEvents, data.Reason, nil
 }
 
 // CheckEnabled verifies the element is not disabled.
@@ -149,6 +168,12 @@ func CheckReceivesEvents(client *bidi.Client, context, selector string) (bool, e
 // - It has aria-disabled="true"
 // - It's inside a disabled <fieldset>
 func CheckEnabled(client *bidi.Client, context, selector string) (bool, error) {
+	ok, _, err := checkEnabled(client, context, selector)
+	return ok, err
+}
+
+// checkEnabled is CheckEnabled, also returning why the check failed.
+func checkEnabled(client *bidi.Client, context, selector string) (bool, string, error) {
 	script := `
 		(selector) => {
 			const el = document.querySelector(selector);
@@ -180,22 +205,23 @@ func CheckEnabled(client *bidi.Client, context, selector string) (bool, error) {
 
 	result, err := callCheckFunction(client, context, selector, script)
 	if err != nil {
-		return false, err
+		return false, "", err
 	}
 
 	var data struct {
 		Enabled bool   `json:"enabled"`
+		Reason  string `json:"reason,omitempty"`
 		Error   string `json:"error,omitempty"`
 	}
 	if err := json.Unmarshal([]byte(result), &data); err != nil {
-		return false, fmt.Errorf("failed to parse enabled result: %w", err)
+		return false, "", fmt.Errorf("failed to parse enabled result: %w", err)
 	}
 
 	if data.Error != "" {
-		return false, fmt.Errorf("element %s", data.Error)
+		return false, "", elementError(selector, data.Error)
 	}
 
-	return data.Enabled, nil
+	return data.Enabled, data.Reason, nil
 }
 
 // CheckEditable verifies the element can accept text input.
@@ -205,13 +231,19 @@ func CheckEnabled(client *bidi.Client, context, selector string) (bool, error) {
 // - It does not have aria-readonly="true"
 // - For contenteditable, it must be "true" or ""
 func CheckEditable(client *bidi.Client, context, selector string) (bool, error) {
+	ok, _, err := checkEditable(client, context, selector)
+	return ok, err
+}
+
+// checkEditable is CheckEditable, also returning why the check failed.
+func checkEditable(client *bidi.Client, context, selector string) (bool, string, error) {
 	// First check if enabled
-	enabled, err := CheckEnabled(client, context, selector)
+	enabled, reason, err := checkEnabled(client, context, selector)
 	if err != nil {
-		return false, err
+		return false, "", err
 	}
 	if !enabled {
-		return false, nil
+		return false, reason, nil
 	}
 
 	script := `
@@ -233,6 +265,9 @@ func CheckEditable(client *bidi.Client, context, selector string) (bool, error)
 			const tag = el.tagName.toLowerCase();
 			if (tag === 'input') {
 				const type = (el.type || 'text').toLowerCase();
+				if (type === 'file') {
+					return JSON.stringify({ editable: false, reason: 'file input (set files instead of typing)' });
+				}
 				const textTypes = ['text', 'password', 'email', 'number', 'search', 'tel', 'url'];
 				if (!textTypes.includes(type)) {
 					return JSON.stringify({ editable: false, reason: 'input type ' + type + ' not editable' });
@@ -256,58 +291,73 @@ func CheckEditable(client *bidi.Client, context, selector string) (bool, error)
 
 	result, err := callCheckFunction(client, context, selector, script)
 	if err != nil {
-		return false, err
+		return false, "", err
 	}
 
 	var data struct {
 		Editable bool   `json:"editable"`
+		Reason   string `json:"reason,omitempty"`
 		Error    string `json:"error,omitempty"`
 	}
 	if err := json.Unmarshal([]byte(result), &data); err != nil {
-		return false, fmt.Errorf("failed to parse editable result: %w", err)
+		return false, "", fmt.Errorf("failed to parse editable result: %w", err)
 	}
 
 	if data.Error != "" {
-		return false, fmt.Errorf("element %s", data.Error)
+		return false, "", elementError(selector, data.Error)
 	}
 
-	return data.Editable, nil
+	return data.Editable, data.Reason, nil
 }
 
-// CheckAll runs all actionability checks and returns the results.
+// CheckAll runs all actionability checks and returns the results, with the
+// reason for each check that failed.
 func CheckAll(client *bidi.Client, context, selector string) (*ActionabilityResult, error) {
-	result := &ActionabilityResult{}
+	result := &ActionabilityResult{Reasons: map[string]string{}}
 
 	var err error
+	var reason string
 
-	result.Visible, err = CheckVisible(client, context, selector)
+	result.Visible, reason, err = checkVisible(client, context, selector)
 	if err != nil {
 		return nil, fmt.Errorf("visible check failed: %w", err)
 	}
+	result.addReason("visible", result.Visible, reason)
 
 	result.Stable, err = CheckStable(client, context, selector)
 	if err != nil {
 		return nil, fmt.Errorf("stable check failed: %w", err)
 	}
+	result.addReason("stable", result.Stable, "bounding box is still changing")
 
-	result.ReceivesEvents, err = CheckReceivesEvents(client, context, selector)
+	result.ReceivesEvents, reason, err = checkReceivesEvents(client, context, selector)
 	if err != nil {
 		return nil, fmt.Errorf("receivesEvents check failed: %w", err)
 	}
+	result.addReason("receivesEvents", result.ReceivesEvents, reason)
 
-	result.Enabled, err = CheckEnabled(client, context, selector)
+	result.Enabled, reason, err = checkEnabled(client, context, selector)
 	if err != nil {
 		return nil, fmt.Errorf("enabled check failed: %w", err)
 	}
+	result.addReason("enabled", result.Enabled, reason)
 
-	result.Editable, err = CheckEditable(client, context, selector)
+	result.Editable, reason, err = checkEditable(client, context, selector)
 	if err != nil {
 		return nil, fmt.Errorf("editable check failed: %w", err)
 	}
+	result.addReason("editable", result.Editable, reason)
 
 	return result, nil
 }
 
+// addReason records why the check name failed, if it did.
+func (r *ActionabilityResult) addReason(name string, passed bool, reason string) {
+	if !passed {
+		r.Reasons[name] = reason
+	}
+}
+
 // callCheckFunction is a helper to execute a script and return the JSON string result.
 func callCheckFunction(client *bidi.Client, context, selector, script string) (string, error) {
 	if context == "" {
@@ -362,6 +412,14 @@ func callCheckFunction(client *bidi.Client, context, selector, script string) (s
 	return remoteValue.Value, nil
 }
 
+// elementError converts the error reported by a check script.
+func elementError(selector, msg string) error {
+	if msg == "not found" {
+		return &errs.ElementNotFoundError{Selector: selector}
+	}
+	return fmt.Errorf("element %s", msg)
+}
+
 // getBoundingBox returns the element's bounding box coordinates.
 func getBoundingBox(client *bidi.Client, context, selector string) (*bidi.BoxInfo, error) {
 	script := `
@@ -396,7 +454,7 @@ func getBoundingBox(client *bidi.Client, context, selector string) (*bidi.BoxInf
 	}
 
 	if data.Error != "" {
-		return nil, fmt.Errorf("element %s", data.Error)
+		return nil, elementError(selector, data.Error)
 	}
 
 	return &bidi.BoxInfo{
@@ -61,6 +61,25 @@ var (
 		CheckEnabledType,
 		CheckEditableType,
 	}
+
+	// HoverChecks are the checks required before hovering over or dragging an element.
+	HoverChecks = []Check{
+		CheckVisibleType,
+		CheckStableType,
+		CheckReceivesEventsType,
+	}
+
+	// SelectChecks are the checks required before selecting options of a <select>.
+	SelectChecks = []Check{
+		CheckVisibleType,
+		CheckEnabledType,
+	}
+
+	// UploadChecks are the checks required before setting files on a file input.
+	// File inputs are often hidden behind a styled label, so visibility is not required.
+	UploadChecks = []Check{
+		CheckEnabledType,
+	}
 )
 
 // WaitOptions configures wait behavior.
@@ -101,6 +120,7 @@ func WaitForSelector(client *bidi.Client, context, selector string, opts WaitOpt
 				Selector: selector,
 				Timeout:  opts.Timeout,
 				Reason:   "element not found",
+				Cause:    &errs.ElementNotFoundError{Selector: selector, Context: context},
 			}
 		}
 
@@ -124,10 +144,11 @@ func WaitForActionable(client *bidi.Client, context, selector string, checks []C
 		// Run all checks
 		allPassed := true
 		var failedCheck Check
+		var failedReason string
 		var checkErr error
 
 		for _, check := range checks {
-			passed, err := runCheck(client, context, selector, check)
+			passed, reason, err := runCheck(client, context, selector, check)
 			if err != nil {
 				// Element not found or other error - keep waiting
 				allPassed = false
@@ -138,6 +159,7 @@ func WaitForActionable(client *bidi.Client, context, selector string, checks []C
 			if !passed {
 				allPassed = false
 				failedCheck = check
+				failedReason = reason
 				break
 			}
 		}
@@ -149,13 +171,17 @@ func WaitForActionable(client *bidi.Client, context, selector string, checks []C
 		// Check if we've timed out
 		if time.Now().After(deadline) {
 			reason := fmt.Sprintf("check '%s' failed", failedCheck)
+			cause := checkErr
 			if checkErr != nil {
 				reason = fmt.Sprintf("check '%s' failed: %v", failedCheck, checkErr)
+			} else {
+				cause = &errs.NotActionableError{Selector: selector, Check: failedCheck.String(), Reason: failedReason}
 			}
 			return &errs.TimeoutError{
 				Selector: selector,
 				Timeout:  opts.Timeout,
 				Reason:   reason,
+				Cause:    cause,
 			}
 		}
 
@@ -184,20 +210,52 @@ func WaitForType(client *bidi.Client, context, selector string, opts WaitOptions
 	return WaitForActionable(client, context, selector, TypeChecks, opts)
 }
 
-// runCheck executes a single actionability check.
-func runCheck(client *bidi.Client, context, selector string, check Check) (bool, error) {
+// WaitForHover waits until an element is actionable for hovering or dragging.
+func WaitForHover(client *bidi.Client, context, selector string, opts WaitOptions) error {
+	// First wait for element to exist
+	if err := WaitForSelector(client, context, selector, opts); err != nil {
+		return err
+	}
+	// Then wait for hover checks
+	return WaitForActionable(client, context, selector, HoverChecks, opts)
+}
+
+// WaitForSelect waits until a <select> is ready to have options selected.
+func WaitForSelect(client *bidi.Client, context, selector string, opts WaitOptions) error {
+	// First wait for element to exist
+	if err := WaitForSelector(client, context, selector, opts); err != nil {
+		return err
+	}
+	// Then wait for select checks
+	return WaitForActionable(client, context, selector, SelectChecks, opts)
+}
+
+// WaitForUpload waits until a file input is ready to receive files.
+func WaitForUpload(client *bidi.Client, context, selector string, opts WaitOptions) error {
+	// First wait for element to exist
+	if err := WaitForSelector(client, context, selector, opts); err != nil {
+		return err
+	}
+	// Then wait for upload checks
+	return WaitForActionable(client, context, selector, UploadChecks, opts)
+}
+
+// runCheck executes a single actionability check, returning why it failed
+// if it did.
+func runCheck(client *bidi.Client, context, selector string, check Check) (bool, string, error) {
 	switch check {
 	case CheckVisibleType:
-		return CheckVisible(client, context, selector)
+		return checkVisible(client, context, selector)
 	case CheckStableType:
-		return CheckStable(client, context, selector)
+		stable, err := CheckStable(client, context, selector)
+		return stable, "bounding box is still changing", err
 	case CheckReceivesEventsType:
-		return CheckReceivesEvents(client, context, selector)
+		return checkReceivesEvents(client, context, selector)
 	case CheckEnabledType:
-		return CheckEnabled(client, context, selector)
+		return checkEnabled(client, context, selector)
 	case CheckEditableType:
-		return CheckEditable(client, context, selector)
+		return checkEditable(client, context, selector)
 	default:
-		return false, fmt.Errorf("unknown check type: %d", check)
+		return false, "", fmt.Errorf("unknown check type: %d", check)
 	}
 }
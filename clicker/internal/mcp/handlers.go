@@ -5,9 +5,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/vibium/clicker/internal/bidi"
 	"github.com/vibium/clicker/internal/browser"
+	"github.com/vibium/clicker/internal/devices"
+	errs "github.com/vibium/clicker/internal/errors"
 	"github.com/vibium/clicker/internal/features"
 	"github.com/vibium/clicker/internal/log"
 )
@@ -18,37 +22,135 @@ type Handlers struct {
 	client        *bidi.Client
 	conn          *bidi.Connection
 	screenshotDir string
+	uploadDir     string
+	downloadDir   string
+
+	// activeContext is the browsing context tools operate on.
+	// Empty means the first top-level context.
+	activeContext string
+
+	// launchOpts holds the emulation settings, reapplied to new tabs.
+	launchOpts browser.LaunchOptions
+
+	// Crash recovery, see recover.go. lastURL and storage are the state of
+	// the active tab after the last successful tool call.
+	autoRecover    bool
+	recoverStorage bool
+	lastURL        string
+	storage        *storageState
+
+	// browserName, connectURL and capabilities are passed on to
+	// browser.Start; browser_launch may choose another browser.
+	browserName  string
+	connectURL   string
+	capabilities map[string]interface{}
 }
 
 // NewHandlers creates a new Handlers instance.
-// screenshotDir specifies where screenshots are saved. If empty, file saving is disabled.
-func NewHandlers(screenshotDir string) *Handlers {
+// See ServerOptions for the meaning of the directories.
+func NewHandlers(opts ServerOptions) *Handlers {
 	return &Handlers{
-		screenshotDir: screenshotDir,
+		screenshotDir:  opts.ScreenshotDir,
+		uploadDir:      opts.UploadDir,
+		downloadDir:    opts.DownloadDir,
+		autoRecover:    opts.AutoRecover || opts.RecoverStorage,
+		recoverStorage: opts.RecoverStorage,
+		browserName:    opts.Browser,
+		connectURL:     opts.ConnectURL,
+		capabilities:   opts.Capabilities,
 	}
 }
 
 // Call executes a tool by name with the given arguments.
+// Dialogs the page opened during the call are reported after the result.
+// With auto-recover, a call that fails because the browser crashed
+// relaunches it, and read-only calls run again (see recoverSession).
 func (h *Handlers) Call(name string, args map[string]interface{}) (*ToolsCallResult, error) {
 	log.Debug("tool call", "name", name, "args", args)
 
+	result, err := h.dispatch(name, args)
+	if err != nil && h.canRecover(name, err) {
+		result, err = h.recoverSession(name, args, err)
+	}
+	if err != nil || result == nil || h.client == nil {
+		return result, err
+	}
+
+	for _, d := range h.client.TakeDialogs() {
+		result.Content = append(result.Content, Content{
+			Type: "text",
+			Text: describeDialog(d),
+		})
+	}
+	h.remember()
+	return result, nil
+}
+
+// describeDialog summarizes a dialog for a tool result.
+func describeDialog(d bidi.Dialog) string {
+	if d.Outcome == "open" {
+		return fmt.Sprintf("A %s dialog is open: %q (use browser_handle_dialog to accept or dismiss it)", d.Type, d.Message)
+	}
+	return fmt.Sprintf("A %s dialog was %s: %q", d.Type, d.Outcome, d.Message)
+}
+
+// dispatch routes a tool call to its handler.
+func (h *Handlers) dispatch(name string, args map[string]interface{}) (*ToolsCallResult, error) {
 	switch name {
 	case "browser_launch":
 		return h.browserLaunch(args)
+	case "browser_set_viewport":
+		return h.browserSetViewport(args)
 	case "browser_navigate":
 		return h.browserNavigate(args)
+	case "browser_back":
+		return h.browserBack(args)
+	case "browser_forward":
+		return h.browserForward(args)
+	case "browser_reload":
+		return h.browserReload(args)
 	case "browser_click":
 		return h.browserClick(args)
+	case "browser_hover":
+		return h.browserHover(args)
+	case "browser_drag":
+		return h.browserDrag(args)
+	case "browser_press":
+		return h.browserPress(args)
+	case "browser_scroll":
+		return h.browserScroll(args)
 	case "browser_type":
 		return h.browserType(args)
+	case "browser_fill":
+		return h.browserFill(args)
+	case "browser_select":
+		return h.browserSelect(args)
+	case "browser_check":
+		return h.browserCheck(args)
+	case "browser_upload":
+		return h.browserUpload(args)
+	case "browser_wait_for_download":
+		return h.browserWaitForDownload(args)
 	case "browser_screenshot":
 		return h.browserScreenshot(args)
+	case "browser_pdf":
+		return h.browserPDF(args)
 	case "browser_find":
 		return h.browserFind(args)
+	case "browser_handle_dialog":
+		return h.browserHandleDialog(args)
 	case "browser_quit":
 		return h.browserQuit(args)
+	case "browser_tabs_list":
+		return h.browserTabsList(args)
+	case "browser_tab_new":
+		return h.browserTabNew(args)
+	case "browser_tab_switch":
+		return h.browserTabSwitch(args)
+	case "browser_tab_close":
+		return h.browserTabClose(args)
 	default:
-		return nil, fmt.Errorf("unknown tool: %s", name)
+		return nil, errs.InvalidArgument("unknown tool: %s", name)
 	}
 }
 
@@ -63,6 +165,10 @@ func (h *Handlers) Close() {
 		h.launchResult = nil
 	}
 	h.client = nil
+	h.activeContext = ""
+	h.launchOpts = browser.LaunchOptions{}
+	h.lastURL = ""
+	h.storage = nil
 }
 
 // browserLaunch launches a new browser session.
@@ -76,31 +182,254 @@ func (h *Handlers) browserLaunch(args map[string]interface{}) (*ToolsCallResult,
 		headless = val
 	}
 
+	opts := browser.LaunchOptions{Headless: headless, Browser: h.browserName}
+	if name, ok := args["browser"].(string); ok && name != "" {
+		b, err := browser.ParseBrowser(name)
+		if err != nil {
+			return nil, err
+		}
+		opts.Browser = b
+	}
+	if name, ok := args["device"].(string); ok && name != "" {
+		d, err := devices.Lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		opts.ApplyDevice(d)
+	}
+	if vp := viewportArgs(args); vp != nil {
+		if vp.DevicePixelRatio == 0 && opts.Viewport != nil {
+			vp.DevicePixelRatio = opts.Viewport.DevicePixelRatio
+		}
+		opts.Viewport = vp
+	}
+	if geo, ok := args["geolocation"].(map[string]interface{}); ok {
+		lat, latOK := geo["latitude"].(float64)
+		lon, lonOK := geo["longitude"].(float64)
+		if !latOK || !lonOK {
+			return nil, errs.InvalidArgument("geolocation requires latitude and longitude")
+		}
+		accuracy, _ := geo["accuracy"].(float64)
+		opts.Geolocation = &devices.Geolocation{Latitude: lat, Longitude: lon, Accuracy: accuracy}
+		if err := opts.Geolocation.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if locale, ok := args["locale"].(string); ok {
+		opts.Locale = locale
+	}
+	if timezone, ok := args["timezone"].(string); ok {
+		opts.Timezone = timezone
+	}
+	if userAgent, ok := args["userAgent"].(string); ok && userAgent != "" {
+		opts.UserAgent = userAgent
+	}
+	opts.DownloadDir = h.downloadDir
+	opts.ConnectURL = h.connectURL
+	opts.Capabilities = h.capabilities
+	if policy, ok := args["dialogPolicy"].(string); ok {
+		p, err := bidi.ParseDialogPolicy(policy)
+		if err != nil {
+			return nil, err
+		}
+		opts.DialogPolicy = p
+	}
+
+	if pacing, ok := args["pacing"].(map[string]interface{}); ok {
+		opts.Pacing = pacingArgs(pacing)
+	}
+	launchArgs(args, &opts)
+
+	if err := h.start(opts); err != nil {
+		return nil, err
+	}
+
+	text := fmt.Sprintf("Browser launched (headless: %v)", headless)
+	if vp := opts.Viewport; vp != nil {
+		text = fmt.Sprintf("Browser launched (headless: %v, viewport: %dx%d)", headless, vp.Width, vp.Height)
+	}
+
+	if p := h.client.Pacer(); p != nil {
+		text += fmt.Sprintf("; input pacing on (seed %d)", p.Seed())
+	}
+
+	return &ToolsCallResult{
+		Content: []Content{{
+			Type: "text",
+			Text: text,
+		}},
+	}, nil
+}
+
+// start launches a browser with opts and connects to it.
+func (h *Handlers) start(opts browser.LaunchOptions) error {
 	// Launch browser
-	launchResult, err := browser.Launch(browser.LaunchOptions{Headless: headless})
+	launchResult, err := browser.Start(opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to launch browser: %w", err)
+		return fmt.Errorf("failed to launch browser: %w", err)
 	}
 
 	// Connect to BiDi
 	conn, err := bidi.Connect(launchResult.WebSocketURL)
 	if err != nil {
 		launchResult.Close()
-		return nil, fmt.Errorf("failed to connect to browser: %w", err)
+		return fmt.Errorf("failed to connect to browser: %w", err)
 	}
 
 	h.launchResult = launchResult
 	h.conn = conn
 	h.client = bidi.NewClient(conn)
 
+	if err := launchResult.Watch(h.client); err != nil {
+		h.Close()
+		return fmt.Errorf("failed to watch browser: %w", err)
+	}
+	if err := browser.ApplyEmulation(h.client, "", opts); err != nil {
+		h.Close()
+		return fmt.Errorf("failed to apply emulation: %w", err)
+	}
+	if err := browser.PrepareSession(h.client, opts); err != nil {
+		h.Close()
+		return fmt.Errorf("failed to prepare session: %w", err)
+	}
+	h.launchOpts = opts
+	return nil
+}
+
+// pacingArgs reads the pacing argument of browser_launch. Missing fields
+// keep their defaults; durations are in milliseconds.
+func pacingArgs(args map[string]interface{}) *bidi.Pacing {
+	pacing := bidi.DefaultPacing()
+	ms := func(v float64) time.Duration { return time.Duration(v * float64(time.Millisecond)) }
+
+	if v, ok := args["moveDuration"].(float64); ok {
+		pacing.MoveDuration = ms(v)
+	}
+	if v, ok := args["moveSteps"].(float64); ok {
+		pacing.MoveSteps = int(v)
+	}
+	if v, ok := args["clickDelay"].(float64); ok {
+		pacing.ClickDelay = ms(v)
+	}
+	if v, ok := args["keyDelay"].(float64); ok {
+		pacing.KeyDelay = ms(v)
+	}
+	if v, ok := args["jitter"].(float64); ok {
+		pacing.Jitter = v
+	}
+	if v, ok := args["seed"].(float64); ok {
+		pacing.Seed = int64(v)
+	}
+	return &pacing
+}
+
+// browserSetViewport changes the viewport of the active tab.
+func (h *Handlers) browserSetViewport(args map[string]interface{}) (*ToolsCallResult, error) {
+	if err := h.ensureBrowser(); err != nil {
+		return nil, err
+	}
+
+	var vp *devices.Viewport
+	var profile *browser.LaunchOptions
+	if name, ok := args["device"].(string); ok && name != "" {
+		d, err := devices.Lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		profile = &browser.LaunchOptions{}
+		profile.ApplyDevice(d)
+		vp = profile.Viewport
+	}
+	if v := viewportArgs(args); v != nil {
+		if v.DevicePixelRatio == 0 && vp != nil {
+			v.DevicePixelRatio = vp.DevicePixelRatio
+		}
+		vp = v
+	}
+	if vp == nil {
+		return nil, errs.InvalidArgument("width and height, or device, is required")
+	}
+
+	if err := h.client.SetViewport(h.activeContext, vp.Width, vp.Height, vp.DevicePixelRatio); err != nil {
+		return nil, fmt.Errorf("failed to set viewport: %w", err)
+	}
+	h.launchOpts.Viewport = vp
+
+	// A device also brings its user agent and touch support, as in the proxy's
+	// vibium:setViewport; a relaunch after a crash keeps them.
+	if profile != nil {
+		if err := h.client.SetUserAgentOverride(h.activeContext, profile.UserAgent); err != nil {
+			return nil, err
+		}
+		touchPoints := 0
+		if profile.Touch {
+			touchPoints = devices.TouchPoints
+		}
+		if err := h.client.SetTouchOverride(h.activeContext, touchPoints); err != nil {
+			log.Debug("touch override not applied", "error", err)
+		}
+		h.launchOpts.UserAgent = profile.UserAgent
+		h.launchOpts.Touch = profile.Touch
+	}
+
 	return &ToolsCallResult{
 		Content: []Content{{
 			Type: "text",
-			Text: fmt.Sprintf("Browser launched (headless: %v)", headless),
+			Text: fmt.Sprintf("Viewport set to %dx%d", vp.Width, vp.Height),
 		}},
 	}, nil
 }
 
+// launchArgs reads the browser customization arguments of browser_launch
+// into opts: args, ignoreDefaultArgs, userDataDir, proxy, extensions, env,
+// executable paths and launchTimeout (in milliseconds).
+func launchArgs(args map[string]interface{}, opts *browser.LaunchOptions) {
+	opts.Args = stringsArg(args, "args")
+	opts.IgnoreDefaultArgs = stringsArg(args, "ignoreDefaultArgs")
+	opts.Extensions = stringsArg(args, "extensions")
+	opts.UserDataDir, _ = args["userDataDir"].(string)
+	opts.ProxyServer, _ = args["proxyServer"].(string)
+	opts.ProxyBypass, _ = args["proxyBypass"].(string)
+	opts.ExecutablePath, _ = args["executablePath"].(string)
+	opts.ChromedriverPath, _ = args["chromedriverPath"].(string)
+	if env, ok := args["env"].(map[string]interface{}); ok {
+		opts.Env = map[string]string{}
+		for name, value := range env {
+			if s, ok := value.(string); ok {
+				opts.Env[name] = s
+			}
+		}
+	}
+	if ms, ok := args["launchTimeout"].(float64); ok && ms > 0 {
+		opts.LaunchTimeout = time.Duration(ms * float64(time.Millisecond))
+	}
+}
+
+// stringsArg returns the strings of an array argument.
+func stringsArg(args map[string]interface{}, name string) []string {
+	var list []string
+	if values, ok := args[name].([]interface{}); ok {
+		for _, v := range values {
+			if s, ok := v.(string); ok {
+				list = append(list, s)
+			}
+		}
+	}
+	return list
+}
+
+// viewportArgs parses width, height and devicePixelRatio tool arguments.
+// Returns nil if width or height is missing.
+func viewportArgs(args map[string]interface{}) *devices.Viewport {
+	width, _ := args["width"].(float64)
+	height, _ := args["height"].(float64)
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+	dpr, _ := args["devicePixelRatio"].(float64)
+	return &devices.Viewport{Width: int(width), Height: int(height), DevicePixelRatio: dpr}
+}
+
 // browserNavigate navigates to a URL.
 func (h *Handlers) browserNavigate(args map[string]interface{}) (*ToolsCallResult, error) {
 	if err := h.ensureBrowser(); err != nil {
@@ -109,10 +438,14 @@ func (h *Handlers) browserNavigate(args map[string]interface{}) (*ToolsCallResul
 
 	url, ok := args["url"].(string)
 	if !ok || url == "" {
-		return nil, fmt.Errorf("url is required")
+		return nil, errs.InvalidArgument("url is required")
+	}
+
+	if err := browser.GrantGeolocation(h.client, h.launchOpts, url); err != nil {
+		return nil, fmt.Errorf("failed to grant geolocation permission: %w", err)
 	}
 
-	result, err := h.client.Navigate("", url)
+	result, err := h.client.Navigate(h.activeContext, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to navigate: %w", err)
 	}
@@ -125,6 +458,73 @@ func (h *Handlers) browserNavigate(args map[string]interface{}) (*ToolsCallResul
 	}, nil
 }
 
+// browserBack navigates back in the active tab's history.
+func (h *Handlers) browserBack(args map[string]interface{}) (*ToolsCallResult, error) {
+	if err := h.ensureBrowser(); err != nil {
+		return nil, err
+	}
+
+	if err := h.client.Back(h.activeContext); err != nil {
+		return nil, fmt.Errorf("failed to go back: %w", err)
+	}
+
+	url, err := h.activeURL()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ToolsCallResult{
+		Content: []Content{{
+			Type: "text",
+			Text: fmt.Sprintf("Went back to %s", url),
+		}},
+	}, nil
+}
+
+// browserForward navigates forward in the active tab's history.
+func (h *Handlers) browserForward(args map[string]interface{}) (*ToolsCallResult, error) {
+	if err := h.ensureBrowser(); err != nil {
+		return nil, err
+	}
+
+	if err := h.client.Forward(h.activeContext); err != nil {
+		return nil, fmt.Errorf("failed to go forward: %w", err)
+	}
+
+	url, err := h.activeURL()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ToolsCallResult{
+		Content: []Content{{
+			Type: "text",
+			Text: fmt.Sprintf("Went forward to %s", url),
+		}},
+	}, nil
+}
+
+// browserReload reloads the active tab.
+func (h *Handlers) browserReload(args map[string]interface{}) (*ToolsCallResult, error) {
+	if err := h.ensureBrowser(); err != nil {
+		return nil, err
+	}
+
+	ignoreCache, _ := args["ignoreCache"].(bool)
+
+	result, err := h.client.Reload(h.activeContext, ignoreCache)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reload: %w", err)
+	}
+
+	return &ToolsCallResult{
+		Content: []Content{{
+			Type: "text",
+			Text: fmt.Sprintf("Reloaded %s", result.URL),
+		}},
+	}, nil
+}
+
 // browserClick clicks an element.
 func (h *Handlers) browserClick(args map[string]interface{}) (*ToolsCallResult, error) {
 	if err := h.ensureBrowser(); err != nil {
@@ -133,151 +533,742 @@ func (h *Handlers) browserClick(args map[string]interface{}) (*ToolsCallResult,
 
 	selector, ok := args["selector"].(string)
 	if !ok || selector == "" {
-		return nil, fmt.Errorf("selector is required")
+		return nil, errs.InvalidArgument("selector is required")
+	}
+
+	clickOpts, err := clickOptionsArgs(args)
+	if err != nil {
+		return nil, err
 	}
 
 	// Wait for element to be actionable
 	opts := features.DefaultWaitOptions()
-	if err := features.WaitForClick(h.client, "", selector, opts); err != nil {
+	if err := features.WaitForClick(h.client, h.activeContext, selector, opts); err != nil {
 		return nil, err
 	}
 
 	// Click the element
-	if err := h.client.ClickElement("", selector); err != nil {
-		return nil, fmt.Errorf("failed to click: %w", err)
+	click := func() error {
+		if err := h.client.ClickElementWithOptions(h.activeContext, selector, clickOpts); err != nil {
+			return fmt.Errorf("failed to click: %w", err)
+		}
+		return nil
+	}
+
+	text := fmt.Sprintf("Clicked element: %s", selector)
+	if newTab, _ := args["newTab"].(bool); newTab {
+		info, err := h.client.WaitForNewContext(opts.Timeout, click)
+		if err != nil {
+			return nil, err
+		}
+		h.activeContext = info.Context
+		if err := browser.ApplyEmulation(h.client, info.Context, h.launchOpts); err != nil {
+			return nil, fmt.Errorf("failed to apply emulation: %w", err)
+		}
+		text += fmt.Sprintf(", switched to new tab %s", info.Context)
+	} else if err := click(); err != nil {
+		return nil, err
 	}
 
 	return &ToolsCallResult{
 		Content: []Content{{
 			Type: "text",
-			Text: fmt.Sprintf("Clicked element: %s", selector),
+			Text: text,
 		}},
 	}, nil
 }
 
-// browserType types text into an element.
-func (h *Handlers) browserType(args map[string]interface{}) (*ToolsCallResult, error) {
+// clickOptionsArgs reads the optional click arguments of browser_click.
+func clickOptionsArgs(args map[string]interface{}) (bidi.ClickOptions, error) {
+	var opts bidi.ClickOptions
+
+	if button, ok := args["button"].(string); ok {
+		opts.Button = button
+	}
+	if count, ok := args["clickCount"].(float64); ok {
+		opts.ClickCount = int(count)
+	}
+	if mods, ok := args["modifiers"].([]interface{}); ok {
+		for _, m := range mods {
+			if name, ok := m.(string); ok {
+				opts.Modifiers = append(opts.Modifiers, name)
+			}
+		}
+	}
+	if pos, ok := args["position"].(map[string]interface{}); ok {
+		x, _ := pos["x"].(float64)
+		y, _ := pos["y"].(float64)
+		opts.Position = &bidi.Point{X: x, Y: y}
+	}
+	if delay, ok := args["delay"].(float64); ok {
+		opts.Delay = time.Duration(delay) * time.Millisecond
+	}
+
+	// Validate before waiting on the element
+	if _, err := bidi.ClickActions(0, 0, opts); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+// browserHover moves the mouse over an element.
+func (h *Handlers) browserHover(args map[string]interface{}) (*ToolsCallResult, error) {
 	if err := h.ensureBrowser(); err != nil {
 		return nil, err
 	}
 
 	selector, ok := args["selector"].(string)
 	if !ok || selector == "" {
-		return nil, fmt.Errorf("selector is required")
+		return nil, errs.InvalidArgument("selector is required")
 	}
 
-	text, ok := args["text"].(string)
-	if !ok {
-		return nil, fmt.Errorf("text is required")
-	}
-
-	// Wait for element to be actionable
 	opts := features.DefaultWaitOptions()
-	if err := features.WaitForType(h.client, "", selector, opts); err != nil {
+	if err := features.WaitForHover(h.client, h.activeContext, selector, opts); err != nil {
 		return nil, err
 	}
 
-	// Type into the element
-	if err := h.client.TypeIntoElement("", selector, text); err != nil {
-		return nil, fmt.Errorf("failed to type: %w", err)
+	if err := h.client.Hover(h.activeContext, selector); err != nil {
+		return nil, fmt.Errorf("failed to hover: %w", err)
 	}
 
 	return &ToolsCallResult{
 		Content: []Content{{
 			Type: "text",
-			Text: fmt.Sprintf("Typed into element: %s", selector),
+			Text: fmt.Sprintf("Hovering over element: %s", selector),
 		}},
 	}, nil
 }
 
-// browserScreenshot captures a screenshot.
-func (h *Handlers) browserScreenshot(args map[string]interface{}) (*ToolsCallResult, error) {
+// browserDrag drags one element onto another.
+func (h *Handlers) browserDrag(args map[string]interface{}) (*ToolsCallResult, error) {
 	if err := h.ensureBrowser(); err != nil {
 		return nil, err
 	}
 
-	base64Data, err := h.client.CaptureScreenshot("")
-	if err != nil {
-		return nil, fmt.Errorf("failed to capture screenshot: %w", err)
+	source, ok := args["source"].(string)
+	if !ok || source == "" {
+		return nil, errs.InvalidArgument("source is required")
+	}
+	target, ok := args["target"].(string)
+	if !ok || target == "" {
+		return nil, errs.InvalidArgument("target is required")
 	}
 
-	// If filename provided, save to file (only if screenshotDir is configured)
-	if filename, ok := args["filename"].(string); ok && filename != "" {
-		if h.screenshotDir == "" {
-			return nil, fmt.Errorf("screenshot file saving is disabled (use --screenshot-dir to enable)")
+	opts := features.DefaultWaitOptions()
+	for _, selector := range []string{source, target} {
+		if err := features.WaitForHover(h.client, h.activeContext, selector, opts); err != nil {
+			return nil, err
 		}
+	}
+
+	if err := h.client.DragAndDrop(h.activeContext, source, target); err != nil {
+		return nil, fmt.Errorf("failed to drag: %w", err)
+	}
+
+	return &ToolsCallResult{
+		Content: []Content{{
+			Type: "text",
+			Text: fmt.Sprintf("Dragged %s onto %s", source, target),
+		}},
+	}, nil
+}
+
+// browserPress presses a key or key chord, optionally focusing an element first.
+func (h *Handlers) browserPress(args map[string]interface{}) (*ToolsCallResult, error) {
+	if err := h.ensureBrowser(); err != nil {
+		return nil, err
+	}
+
+	keys, ok := args["keys"].(string)
+	if !ok || keys == "" {
+		return nil, errs.InvalidArgument("keys is required")
+	}
+	if _, err := bidi.ParseChord(keys); err != nil {
+		return nil, err
+	}
 
-		// Create directory if it doesn't exist
-		if err := os.MkdirAll(h.screenshotDir, 0755); err != nil {
-			return nil, fmt.Errorf("failed to create screenshot directory: %w", err)
+	if selector, ok := args["selector"].(string); ok && selector != "" {
+		opts := features.DefaultWaitOptions()
+		if err := features.WaitForClick(h.client, h.activeContext, selector, opts); err != nil {
+			return nil, err
 		}
+		if err := h.client.ClickElement(h.activeContext, selector); err != nil {
+			return nil, fmt.Errorf("failed to focus: %w", err)
+		}
+	}
 
-		// Use only the basename to prevent path traversal
-		safeName := filepath.Base(filename)
-		fullPath := filepath.Join(h.screenshotDir, safeName)
+	if err := h.client.PressChord(h.activeContext, keys); err != nil {
+		return nil, fmt.Errorf("failed to press keys: %w", err)
+	}
 
-		pngData, err := base64.StdEncoding.DecodeString(base64Data)
-		if err != nil {
-			return nil, fmt.Errorf("failed to decode screenshot: %w", err)
+	return &ToolsCallResult{
+		Content: []Content{{
+			Type: "text",
+			Text: fmt.Sprintf("Pressed %s", keys),
+		}},
+	}, nil
+}
+
+// browserScroll scrolls with the mouse wheel, over an element or the viewport.
+func (h *Handlers) browserScroll(args map[string]interface{}) (*ToolsCallResult, error) {
+	if err := h.ensureBrowser(); err != nil {
+		return nil, err
+	}
+
+	dx, _ := args["deltaX"].(float64)
+	dy, _ := args["deltaY"].(float64)
+	if dx == 0 && dy == 0 {
+		return nil, errs.InvalidArgument("deltaX or deltaY is required")
+	}
+
+	if selector, ok := args["selector"].(string); ok && selector != "" {
+		opts := features.DefaultWaitOptions()
+		if err := features.WaitForHover(h.client, h.activeContext, selector, opts); err != nil {
+			return nil, err
 		}
-		if err := os.WriteFile(fullPath, pngData, 0644); err != nil {
-			return nil, fmt.Errorf("failed to save screenshot: %w", err)
+		if err := h.client.ScrollElement(h.activeContext, selector, int(dx), int(dy)); err != nil {
+			return nil, fmt.Errorf("failed to scroll: %w", err)
 		}
 		return &ToolsCallResult{
 			Content: []Content{{
 				Type: "text",
-				Text: fmt.Sprintf("Screenshot saved to %s", fullPath),
+				Text: fmt.Sprintf("Scrolled %s by (%d, %d)", selector, int(dx), int(dy)),
 			}},
 		}, nil
 	}
 
+	if err := h.client.Scroll(h.activeContext, 0, 0, int(dx), int(dy)); err != nil {
+		return nil, fmt.Errorf("failed to scroll: %w", err)
+	}
+
 	return &ToolsCallResult{
 		Content: []Content{{
-			Type:     "image",
-			Data:     base64Data,
-			MimeType: "image/png",
+			Type: "text",
+			Text: fmt.Sprintf("Scrolled page by (%d, %d)", int(dx), int(dy)),
 		}},
 	}, nil
 }
 
-// browserFind finds an element and returns its info.
-func (h *Handlers) browserFind(args map[string]interface{}) (*ToolsCallResult, error) {
+// browserType types text into an element.
+func (h *Handlers) browserType(args map[string]interface{}) (*ToolsCallResult, error) {
 	if err := h.ensureBrowser(); err != nil {
 		return nil, err
 	}
 
 	selector, ok := args["selector"].(string)
 	if !ok || selector == "" {
-		return nil, fmt.Errorf("selector is required")
+		return nil, errs.InvalidArgument("selector is required")
 	}
 
-	info, err := h.client.FindElement("", selector)
-	if err != nil {
+	text, ok := args["text"].(string)
+	if !ok {
+		return nil, errs.InvalidArgument("text is required")
+	}
+
+	// Wait for element to be actionable
+	opts := features.DefaultWaitOptions()
+	if err := features.WaitForType(h.client, h.activeContext, selector, opts); err != nil {
 		return nil, err
 	}
 
+	// Type into the element
+	if err := h.client.TypeIntoElement(h.activeContext, selector, text); err != nil {
+		return nil, fmt.Errorf("failed to type: %w", err)
+	}
+
 	return &ToolsCallResult{
 		Content: []Content{{
 			Type: "text",
-			Text: fmt.Sprintf("tag=%s, text=\"%s\", box={x:%.0f, y:%.0f, w:%.0f, h:%.0f}",
-				info.Tag, info.Text, info.Box.X, info.Box.Y, info.Box.Width, info.Box.Height),
+			Text: fmt.Sprintf("Typed into element: %s", selector),
 		}},
 	}, nil
 }
 
-// browserQuit closes the browser session.
-func (h *Handlers) browserQuit(args map[string]interface{}) (*ToolsCallResult, error) {
-	if h.launchResult == nil {
-		return &ToolsCallResult{
-			Content: []Content{{
-				Type: "text",
-				Text: "No browser session to close",
-			}},
-		}, nil
+// browserFill replaces the content of a text field.
+func (h *Handlers) browserFill(args map[string]interface{}) (*ToolsCallResult, error) {
+	if err := h.ensureBrowser(); err != nil {
+		return nil, err
 	}
 
-	h.Close()
-
+	selector, ok := args["selector"].(string)
+	if !ok || selector == "" {
+		return nil, errs.InvalidArgument("selector is required")
+	}
+
+	value, ok := args["value"].(string)
+	if !ok {
+		return nil, errs.InvalidArgument("value is required")
+	}
+
+	opts := features.DefaultWaitOptions()
+	if err := features.WaitForType(h.client, h.activeContext, selector, opts); err != nil {
+		return nil, err
+	}
+
+	if err := h.client.Fill(h.activeContext, selector, value); err != nil {
+		return nil, fmt.Errorf("failed to fill: %w", err)
+	}
+
+	return &ToolsCallResult{
+		Content: []Content{{
+			Type: "text",
+			Text: fmt.Sprintf("Filled element: %s", selector),
+		}},
+	}, nil
+}
+
+// browserSelect selects options of a <select>.
+func (h *Handlers) browserSelect(args map[string]interface{}) (*ToolsCallResult, error) {
+	if err := h.ensureBrowser(); err != nil {
+		return nil, err
+	}
+
+	selector, ok := args["selector"].(string)
+	if !ok || selector == "" {
+		return nil, errs.InvalidArgument("selector is required")
+	}
+
+	var by bidi.SelectBy
+	if values, ok := args["values"].([]interface{}); ok {
+		for _, v := range values {
+			if s, ok := v.(string); ok {
+				by.Values = append(by.Values, s)
+			}
+		}
+	}
+	if labels, ok := args["labels"].([]interface{}); ok {
+		for _, l := range labels {
+			if s, ok := l.(string); ok {
+				by.Labels = append(by.Labels, s)
+			}
+		}
+	}
+	if indexes, ok := args["indexes"].([]interface{}); ok {
+		for _, i := range indexes {
+			if n, ok := i.(float64); ok {
+				by.Indexes = append(by.Indexes, int(n))
+			}
+		}
+	}
+	if len(by.Values) == 0 && len(by.Labels) == 0 && len(by.Indexes) == 0 {
+		return nil, errs.InvalidArgument("values, labels or indexes is required")
+	}
+
+	opts := features.DefaultWaitOptions()
+	if err := features.WaitForSelect(h.client, h.activeContext, selector, opts); err != nil {
+		return nil, err
+	}
+
+	selected, err := h.client.SelectOption(h.activeContext, selector, by)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select: %w", err)
+	}
+
+	return &ToolsCallResult{
+		Content: []Content{{
+			Type: "text",
+			Text: fmt.Sprintf("Selected %s in %s", strings.Join(selected, ", "), selector),
+		}},
+	}, nil
+}
+
+// browserCheck checks or unchecks a checkbox or radio button.
+func (h *Handlers) browserCheck(args map[string]interface{}) (*ToolsCallResult, error) {
+	if err := h.ensureBrowser(); err != nil {
+		return nil, err
+	}
+
+	selector, ok := args["selector"].(string)
+	if !ok || selector == "" {
+		return nil, errs.InvalidArgument("selector is required")
+	}
+
+	checked := true
+	if val, ok := args["checked"].(bool); ok {
+		checked = val
+	}
+
+	opts := features.DefaultWaitOptions()
+	if err := features.WaitForClick(h.client, h.activeContext, selector, opts); err != nil {
+		return nil, err
+	}
+
+	if err := h.client.SetChecked(h.activeContext, selector, checked); err != nil {
+		return nil, err
+	}
+
+	state := "Checked"
+	if !checked {
+		state = "Unchecked"
+	}
+
+	return &ToolsCallResult{
+		Content: []Content{{
+			Type: "text",
+			Text: fmt.Sprintf("%s element: %s", state, selector),
+		}},
+	}, nil
+}
+
+// browserUpload sets the files of a file input.
+func (h *Handlers) browserUpload(args map[string]interface{}) (*ToolsCallResult, error) {
+	if err := h.ensureBrowser(); err != nil {
+		return nil, err
+	}
+
+	selector, ok := args["selector"].(string)
+	if !ok || selector == "" {
+		return nil, errs.InvalidArgument("selector is required")
+	}
+
+	rawPaths, ok := args["paths"].([]interface{})
+	if !ok || len(rawPaths) == 0 {
+		return nil, errs.InvalidArgument("paths is required")
+	}
+
+	files := make([]string, 0, len(rawPaths))
+	for _, raw := range rawPaths {
+		p, ok := raw.(string)
+		if !ok || p == "" {
+			return nil, errs.InvalidArgument("paths must be non-empty strings")
+		}
+		resolved, err := h.resolveUploadPath(p)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, resolved)
+	}
+
+	// Wait for the file input to be ready
+	opts := features.DefaultWaitOptions()
+	if err := features.WaitForUpload(h.client, h.activeContext, selector, opts); err != nil {
+		return nil, err
+	}
+
+	if err := h.client.SetFiles(h.activeContext, selector, files); err != nil {
+		return nil, fmt.Errorf("failed to upload: %w", err)
+	}
+
+	return &ToolsCallResult{
+		Content: []Content{{
+			Type: "text",
+			Text: fmt.Sprintf("Set %d file(s) on element: %s", len(files), selector),
+		}},
+	}, nil
+}
+
+// resolveUploadPath resolves p against the upload directory and rejects paths
+// that escape it, including through symlinks.
+func (h *Handlers) resolveUploadPath(p string) (string, error) {
+	if h.uploadDir == "" {
+		return "", errs.InvalidArgument("file uploads are disabled (use --upload-dir to enable)")
+	}
+
+	root, err := filepath.Abs(h.uploadDir)
+	if err != nil {
+		return "", errs.InvalidArgument("invalid upload directory: %w", err)
+	}
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		return "", errs.InvalidArgument("invalid upload directory: %w", err)
+	}
+
+	// Relative paths are relative to the upload directory
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(root, p)
+	}
+	resolved, err := filepath.EvalSymlinks(p)
+	if err != nil {
+		return "", fmt.Errorf("cannot upload %q: %w", p, err)
+	}
+
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errs.InvalidArgument("cannot upload %q: outside the upload directory %s", p, root)
+	}
+
+	return resolved, nil
+}
+
+// browserWaitForDownload waits for the next download to finish.
+func (h *Handlers) browserWaitForDownload(args map[string]interface{}) (*ToolsCallResult, error) {
+	if err := h.ensureBrowser(); err != nil {
+		return nil, err
+	}
+
+	timeout := features.DefaultTimeout
+	if ms, ok := args["timeout"].(float64); ok && ms > 0 {
+		timeout = time.Duration(ms) * time.Millisecond
+	}
+
+	download, err := h.client.WaitForDownload(h.activeContext, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ToolsCallResult{
+		Content: []Content{{
+			Type: "text",
+			Text: fmt.Sprintf("Downloaded %s to %s (%d bytes)", download.SuggestedFilename, download.Path, download.Size),
+		}},
+	}, nil
+}
+
+// clipArg parses the clip argument of browser_screenshot, {x, y, width, height}.
+func clipArg(clip map[string]interface{}) (*bidi.ClipRectangle, error) {
+	values := make([]float64, 4)
+	for i, name := range []string{"x", "y", "width", "height"} {
+		v, ok := clip[name].(float64)
+		if !ok {
+			return nil, errs.InvalidArgument("clip.%s is required and must be a number", name)
+		}
+		values[i] = v
+	}
+	if values[2] <= 0 || values[3] <= 0 {
+		return nil, errs.InvalidArgument("clip width and height must be positive")
+	}
+	return &bidi.ClipRectangle{X: values[0], Y: values[1], Width: values[2], Height: values[3]}, nil
+}
+
+// browserScreenshot captures a screenshot.
+func (h *Handlers) browserScreenshot(args map[string]interface{}) (*ToolsCallResult, error) {
+	if err := h.ensureBrowser(); err != nil {
+		return nil, err
+	}
+
+	opts := bidi.ScreenshotOptions{}
+	opts.FullPage, _ = args["fullPage"].(bool)
+	opts.Format, _ = args["format"].(string)
+	if quality, ok := args["quality"].(float64); ok {
+		opts.Quality = int(quality)
+	}
+
+	mimeType, err := bidi.ScreenshotMimeType(opts.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	if clip, ok := args["clip"].(map[string]interface{}); ok {
+		rect, err := clipArg(clip)
+		if err != nil {
+			return nil, err
+		}
+		opts.Clip = rect
+	}
+
+	if selector, ok := args["selector"].(string); ok && selector != "" {
+		if opts.Clip != nil {
+			return nil, errs.InvalidArgument("clip and selector cannot be used together")
+		}
+		sharedID, err := h.client.GetElementSharedID(h.activeContext, selector)
+		if err != nil {
+			return nil, err
+		}
+		opts.ElementID = sharedID
+	}
+
+	base64Data, err := h.client.CaptureScreenshotWithOptions(h.activeContext, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture screenshot: %w", err)
+	}
+
+	// If filename provided, save to file (only if screenshotDir is configured)
+	if filename, ok := args["filename"].(string); ok && filename != "" {
+		fullPath, err := h.saveToScreenshotDir(filename, base64Data, "screenshot")
+		if err != nil {
+			return nil, err
+		}
+		return &ToolsCallResult{
+			Content: []Content{{
+				Type: "text",
+				Text: fmt.Sprintf("Screenshot saved to %s", fullPath),
+			}},
+		}, nil
+	}
+
+	return &ToolsCallResult{
+		Content: []Content{{
+			Type:     "image",
+			Data:     base64Data,
+			MimeType: mimeType,
+		}},
+	}, nil
+}
+
+// browserPDF prints the current page to a PDF file in the screenshot directory.
+func (h *Handlers) browserPDF(args map[string]interface{}) (*ToolsCallResult, error) {
+	if err := h.ensureBrowser(); err != nil {
+		return nil, err
+	}
+
+	filename, _ := args["filename"].(string)
+	if filename == "" {
+		filename = "page.pdf"
+	}
+
+	opts := bidi.PDFOptions{}
+	opts.PageWidth, _ = args["width"].(float64)
+	opts.PageHeight, _ = args["height"].(float64)
+	opts.Landscape, _ = args["landscape"].(bool)
+	opts.Scale, _ = args["scale"].(float64)
+	opts.Background, _ = args["background"].(bool)
+	opts.PageRanges, _ = args["pageRanges"].(string)
+
+	if paper, ok := args["paper"].(string); ok && paper != "" {
+		w, ht, err := bidi.PaperSize(paper)
+		if err != nil {
+			return nil, err
+		}
+		opts.PageWidth, opts.PageHeight = w, ht
+	}
+
+	if margin, ok := args["margin"].(map[string]interface{}); ok {
+		m := &bidi.PDFMargin{}
+		m.Top, _ = margin["top"].(float64)
+		m.Right, _ = margin["right"].(float64)
+		m.Bottom, _ = margin["bottom"].(float64)
+		m.Left, _ = margin["left"].(float64)
+		opts.Margin = m
+	}
+
+	// Check before printing so a disabled directory fails fast
+	if h.screenshotDir == "" {
+		return nil, fmt.Errorf("pdf file saving is disabled (use --screenshot-dir to enable)")
+	}
+
+	base64Data, err := h.client.PrintToPDF(h.activeContext, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to print to PDF: %w", err)
+	}
+
+	fullPath, err := h.saveToScreenshotDir(filename, base64Data, "pdf")
+	if err != nil {
+		return nil, err
+	}
+
+	return &ToolsCallResult{
+		Content: []Content{{
+			Type: "text",
+			Text: fmt.Sprintf("PDF saved to %s", fullPath),
+		}},
+	}, nil
+}
+
+// saveToScreenshotDir decodes base64 data and writes it into the screenshot
+// directory. kind names the artifact in error messages.
+// Returns the full path of the saved file.
+func (h *Handlers) saveToScreenshotDir(filename, base64Data, kind string) (string, error) {
+	if h.screenshotDir == "" {
+		return "", fmt.Errorf("%s file saving is disabled (use --screenshot-dir to enable)", kind)
+	}
+
+	// Create directory if it doesn't exist
+	if err := os.MkdirAll(h.screenshotDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create screenshot directory: %w", err)
+	}
+
+	// Use only the basename to prevent path traversal
+	safeName := filepath.Base(filename)
+	fullPath := filepath.Join(h.screenshotDir, safeName)
+
+	data, err := base64.StdEncoding.DecodeString(base64Data)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode %s: %w", kind, err)
+	}
+	if err := os.WriteFile(fullPath, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to save %s: %w", kind, err)
+	}
+
+	return fullPath, nil
+}
+
+// browserHandleDialog accepts or dismisses the open dialog and optionally
+// changes the policy for future dialogs.
+func (h *Handlers) browserHandleDialog(args map[string]interface{}) (*ToolsCallResult, error) {
+	if err := h.ensureBrowser(); err != nil {
+		return nil, err
+	}
+
+	var messages []string
+
+	if policy, ok := args["policy"].(string); ok && policy != "" {
+		if err := h.client.SetDialogPolicy(policy); err != nil {
+			return nil, err
+		}
+		messages = append(messages, fmt.Sprintf("Future dialogs will be handled with policy: %s", h.client.DialogPolicy()))
+	}
+
+	accept, hasAccept := args["accept"].(bool)
+	if !hasAccept {
+		if len(messages) == 0 {
+			return nil, errs.InvalidArgument("accept is required")
+		}
+	} else {
+		text, _ := args["text"].(string)
+		dialog := h.client.OpenDialog()
+		if err := h.client.HandleUserPrompt("", accept, text); err != nil {
+			return nil, fmt.Errorf("failed to handle dialog: %w", err)
+		}
+
+		action := "Dismissed"
+		if accept {
+			action = "Accepted"
+		}
+		if dialog != nil {
+			messages = append([]string{fmt.Sprintf("%s %s dialog: %q", action, dialog.Type, dialog.Message)}, messages...)
+		} else {
+			messages = append([]string{fmt.Sprintf("%s dialog", action)}, messages...)
+		}
+	}
+
+	// The handled dialog was just reported, so don't repeat it
+	h.client.TakeDialogs()
+
+	return &ToolsCallResult{
+		Content: []Content{{
+			Type: "text",
+			Text: strings.Join(messages, "\n"),
+		}},
+	}, nil
+}
+
+// browserFind finds an element and returns its info.
+func (h *Handlers) browserFind(args map[string]interface{}) (*ToolsCallResult, error) {
+	if err := h.ensureBrowser(); err != nil {
+		return nil, err
+	}
+
+	selector, ok := args["selector"].(string)
+	if !ok || selector == "" {
+		return nil, errs.InvalidArgument("selector is required")
+	}
+
+	info, err := h.client.FindElement(h.activeContext, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ToolsCallResult{
+		Content: []Content{{
+			Type: "text",
+			Text: fmt.Sprintf("tag=%s, text=\"%s\", box={x:%.0f, y:%.0f, w:%.0f, h:%.0f}",
+				info.Tag, info.Text, info.Box.X, info.Box.Y, info.Box.Width, info.Box.Height),
+		}},
+	}, nil
+}
+
+// browserQuit closes the browser session.
+func (h *Handlers) browserQuit(args map[string]interface{}) (*ToolsCallResult, error) {
+	if h.launchResult == nil {
+		return &ToolsCallResult{
+			Content: []Content{{
+				Type: "text",
+				Text: "No browser session to close",
+			}},
+		}, nil
+	}
+
+	h.Close()
+
 	return &ToolsCallResult{
 		Content: []Content{{
 			Type: "text",
@@ -286,6 +1277,192 @@ func (h *Handlers) browserQuit(args map[string]interface{}) (*ToolsCallResult, e
 	}, nil
 }
 
+// browserTabsList lists the open tabs and windows.
+func (h *Handlers) browserTabsList(args map[string]interface{}) (*ToolsCallResult, error) {
+	if err := h.ensureBrowser(); err != nil {
+		return nil, err
+	}
+
+	contexts, err := h.client.ListContexts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tabs: %w", err)
+	}
+
+	active := h.currentContext(contexts)
+	var sb strings.Builder
+	for i, ctx := range contexts {
+		marker := " "
+		if ctx.Context == active {
+			marker = "*"
+		}
+		fmt.Fprintf(&sb, "%s [%d] %s %s\n", marker, i, ctx.Context, ctx.URL)
+	}
+
+	return &ToolsCallResult{
+		Content: []Content{{
+			Type: "text",
+			Text: strings.TrimRight(sb.String(), "\n"),
+		}},
+	}, nil
+}
+
+// browserTabNew opens a new tab or window and makes it the active tab.
+func (h *Handlers) browserTabNew(args map[string]interface{}) (*ToolsCallResult, error) {
+	if err := h.ensureBrowser(); err != nil {
+		return nil, err
+	}
+
+	contextType, _ := args["type"].(string)
+	context, err := h.client.CreateContext(contextType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open tab: %w", err)
+	}
+	h.activeContext = context
+
+	if err := browser.ApplyEmulation(h.client, context, h.launchOpts); err != nil {
+		return nil, fmt.Errorf("failed to apply emulation: %w", err)
+	}
+
+	text := fmt.Sprintf("Opened new tab %s", context)
+	if url, ok := args["url"].(string); ok && url != "" {
+		if err := browser.GrantGeolocation(h.client, h.launchOpts, url); err != nil {
+			return nil, fmt.Errorf("failed to grant geolocation permission: %w", err)
+		}
+		result, err := h.client.Navigate(context, url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to navigate: %w", err)
+		}
+		text = fmt.Sprintf("Opened new tab %s at %s", context, result.URL)
+	}
+
+	return &ToolsCallResult{
+		Content: []Content{{
+			Type: "text",
+			Text: text,
+		}},
+	}, nil
+}
+
+// browserTabSwitch makes another tab the active tab.
+func (h *Handlers) browserTabSwitch(args map[string]interface{}) (*ToolsCallResult, error) {
+	if err := h.ensureBrowser(); err != nil {
+		return nil, err
+	}
+
+	ctx, err := h.resolveTab(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.client.ActivateContext(ctx.Context); err != nil {
+		return nil, fmt.Errorf("failed to switch tab: %w", err)
+	}
+	h.activeContext = ctx.Context
+
+	return &ToolsCallResult{
+		Content: []Content{{
+			Type: "text",
+			Text: fmt.Sprintf("Switched to tab %s (%s)", ctx.Context, ctx.URL),
+		}},
+	}, nil
+}
+
+// browserTabClose closes a tab (the active tab by default).
+func (h *Handlers) browserTabClose(args map[string]interface{}) (*ToolsCallResult, error) {
+	if err := h.ensureBrowser(); err != nil {
+		return nil, err
+	}
+
+	ctx, err := h.resolveTab(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.client.CloseContext(ctx.Context); err != nil {
+		return nil, fmt.Errorf("failed to close tab: %w", err)
+	}
+
+	// Fall back to the first remaining tab if the active one was closed
+	if ctx.Context == h.activeContext {
+		h.activeContext = ""
+	}
+
+	return &ToolsCallResult{
+		Content: []Content{{
+			Type: "text",
+			Text: fmt.Sprintf("Closed tab %s", ctx.Context),
+		}},
+	}, nil
+}
+
+// resolveTab finds the tab addressed by the "id" or "index" argument,
+// defaulting to the active tab.
+func (h *Handlers) resolveTab(args map[string]interface{}) (*bidi.BrowsingContextInfo, error) {
+	contexts, err := h.client.ListContexts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tabs: %w", err)
+	}
+	if len(contexts) == 0 {
+		return nil, fmt.Errorf("no tabs open")
+	}
+
+	if id, ok := args["id"].(string); ok && id != "" {
+		for i := range contexts {
+			if contexts[i].Context == id {
+				return &contexts[i], nil
+			}
+		}
+		return nil, fmt.Errorf("no tab with id %s", id)
+	}
+
+	if index, ok := args["index"].(float64); ok {
+		i := int(index)
+		if i < 0 || i >= len(contexts) {
+			return nil, fmt.Errorf("tab index %d out of range (0-%d)", i, len(contexts)-1)
+		}
+		return &contexts[i], nil
+	}
+
+	active := h.currentContext(contexts)
+	for i := range contexts {
+		if contexts[i].Context == active {
+			return &contexts[i], nil
+		}
+	}
+	return &contexts[0], nil
+}
+
+// activeURL returns the URL of the active tab.
+func (h *Handlers) activeURL() (string, error) {
+	contexts, err := h.client.ListContexts()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current URL: %w", err)
+	}
+
+	active := h.currentContext(contexts)
+	for _, ctx := range contexts {
+		if ctx.Context == active {
+			return ctx.URL, nil
+		}
+	}
+	return "", fmt.Errorf("no browsing contexts available")
+}
+
+// currentContext returns the active context ID, resetting it to the first
+// tab if the active tab no longer exists.
+func (h *Handlers) currentContext(contexts []bidi.BrowsingContextInfo) string {
+	for _, ctx := range contexts {
+		if ctx.Context == h.activeContext {
+			return h.activeContext
+		}
+	}
+	h.activeContext = ""
+	if len(contexts) > 0 {
+		return contexts[0].Context
+	}
+	return ""
+}
+
 // ensureBrowser checks that a browser session is active.
 func (h *Handlers) ensureBrowser() error {
 	if h.client == nil {
@@ -0,0 +1,177 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/vibium/clicker/internal/bidi"
+	errs "github.com/vibium/clicker/internal/errors"
+	"github.com/vibium/clicker/internal/log"
+)
+
+// storageState is what RecoverStorage restores after a crash: all cookies,
+// and the localStorage of the active tab's origin.
+type storageState struct {
+	cookies      []bidi.Cookie
+	origin       string
+	localStorage map[string]string
+}
+
+// readLocalStorageScript returns the origin and localStorage of a page.
+const readLocalStorageScript = `JSON.stringify({origin: location.origin, items: Object.assign({}, localStorage)})`
+
+// writeLocalStorageScript fills localStorage if the page is still on origin
+// and returns the number of items set.
+const writeLocalStorageScript = `(origin, items) => {
+	if (location.origin !== origin) return 0;
+	const entries = Object.entries(JSON.parse(items));
+	for (const [key, value] of entries) localStorage.setItem(key, value);
+	return entries.length;
+}`
+
+// retryableTools are the tools recoverSession runs again after a crash:
+// those that only read the page or set it up, so running them twice does no
+// harm. Others, such as a click that submitted a form, may have taken effect
+// before the crash.
+var retryableTools = map[string]bool{
+	"browser_navigate":     true,
+	"browser_set_viewport": true,
+	"browser_screenshot":   true,
+	"browser_pdf":          true,
+	"browser_find":         true,
+	"browser_tabs_list":    true,
+}
+
+// canRecover reports whether a failed tool call should relaunch the
+// browser: auto-recover is on, a session was running and it crashed.
+func (h *Handlers) canRecover(name string, err error) bool {
+	if !h.autoRecover || h.launchResult == nil {
+		return false
+	}
+	if name == "browser_launch" || name == "browser_quit" {
+		return false
+	}
+	return errs.CodeOf(err) == errs.CodeBrowserCrashed
+}
+
+// recoverSession relaunches the browser with the original launch options
+// after crash and restores the last URL (and the storage state, if enabled).
+// Read-only tool calls run again, with a note about the recovery before their
+// result so the agent knows page state was lost; other tool calls fail with
+// that note, so the agent checks the page before repeating them.
+func (h *Handlers) recoverSession(name string, args map[string]interface{}, crash error) (*ToolsCallResult, error) {
+	opts, url, storage := h.launchOpts, h.lastURL, h.storage
+	log.Info("browser crashed, relaunching", "error", crash)
+
+	h.Close()
+	if err := h.start(opts); err != nil {
+		return nil, fmt.Errorf("%w (automatic recovery failed: %v)", crash, err)
+	}
+	h.lastURL, h.storage = url, storage
+
+	restored, err := h.restore(url, storage)
+	if err != nil {
+		return nil, fmt.Errorf("%w (relaunched the browser, but restoring the session failed: %v)", crash, err)
+	}
+
+	note := fmt.Sprintf("The browser crashed (%v) and was relaunched automatically", crash)
+	if restored != "" {
+		note += "; restored " + restored
+	}
+	note += ". Other tabs and unsaved page state were lost."
+
+	if !retryableTools[name] {
+		return nil, fmt.Errorf("%s %s was not run again, as it may have taken effect before the crash; check the page before repeating it: %w", note, name, crash)
+	}
+
+	result, err := h.dispatch(name, args)
+	if err != nil {
+		return nil, fmt.Errorf("%s Retrying %s failed: %w", note, name, err)
+	}
+	result.Content = append([]Content{{Type: "text", Text: note}}, result.Content...)
+	return result, nil
+}
+
+// restore sets the cookies of storage, navigates to url and fills its
+// localStorage. It returns a description of what was restored.
+func (h *Handlers) restore(url string, storage *storageState) (string, error) {
+	var restored []string
+
+	if storage != nil && len(storage.cookies) > 0 {
+		for _, cookie := range storage.cookies {
+			if err := h.client.SetCookie(cookie); err != nil {
+				return "", fmt.Errorf("failed to restore cookie %s: %w", cookie.Name, err)
+			}
+		}
+		restored = append(restored, fmt.Sprintf("%d cookies", len(storage.cookies)))
+	}
+
+	if url == "" || url == "about:blank" {
+		return strings.Join(restored, ", "), nil
+	}
+	if _, err := h.client.Navigate("", url); err != nil {
+		return "", err
+	}
+	restored = append([]string{url}, restored...)
+
+	if storage != nil && len(storage.localStorage) > 0 {
+		items, _ := json.Marshal(storage.localStorage)
+		n, err := h.client.CallFunction("", writeLocalStorageScript, []interface{}{storage.origin, string(items)})
+		if err != nil {
+			return "", fmt.Errorf("failed to restore localStorage: %w", err)
+		}
+		if count, _ := n.(float64); count > 0 {
+			// Let the page start over with its storage in place
+			if _, err := h.client.Reload("", false); err != nil {
+				return "", err
+			}
+			restored = append(restored, fmt.Sprintf("%d localStorage items", int(count)))
+		}
+	}
+
+	return strings.Join(restored, ", "), nil
+}
+
+// remember records the URL of the active tab and, with RecoverStorage, the
+// storage state, for recovery after a crash. What cannot be read right now
+// (e.g. while a dialog blocks the page) keeps its previous value.
+func (h *Handlers) remember() {
+	if !h.autoRecover || h.client == nil {
+		return
+	}
+
+	contexts, err := h.client.ListContexts()
+	if err != nil {
+		return
+	}
+	for _, c := range contexts {
+		if h.activeContext == "" || c.Context == h.activeContext {
+			h.lastURL = c.URL
+			break
+		}
+	}
+
+	if !h.recoverStorage {
+		return
+	}
+	cookies, err := h.client.GetCookies()
+	if err != nil {
+		log.Debug("cannot read cookies for recovery", "error", err)
+		return
+	}
+	state := &storageState{cookies: cookies}
+
+	// Pages without an origin, such as about:blank, have no localStorage
+	if v, err := h.client.Evaluate(h.activeContext, readLocalStorageScript); err == nil {
+		var page struct {
+			Origin string            `json:"origin"`
+			Items  map[string]string `json:"items"`
+		}
+		if s, ok := v.(string); ok && json.Unmarshal([]byte(s), &page) == nil {
+			state.origin = page.Origin
+			state.localStorage = page.Items
+		}
+	}
+	h.storage = state
+}
@@ -14,6 +14,136 @@ func GetToolSchemas() []Tool {
 						"description": "Run browser in headless mode (no visible window)",
 						"default":     false,
 					},
+					"browser": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"chrome", "firefox"},
+						"description": "Browser to launch (default: chrome, or the server's --browser)",
+					},
+					"device": map[string]interface{}{
+						"type":        "string",
+						"description": "Device profile to emulate (e.g. \"Pixel 7\", \"iPhone 15\", \"Desktop HD\")",
+					},
+					"width": map[string]interface{}{
+						"type":        "number",
+						"description": "Viewport width in CSS pixels (overrides the device viewport)",
+					},
+					"height": map[string]interface{}{
+						"type":        "number",
+						"description": "Viewport height in CSS pixels (overrides the device viewport)",
+					},
+					"devicePixelRatio": map[string]interface{}{
+						"type":        "number",
+						"description": "Device pixel ratio (e.g. 2 for retina)",
+					},
+					"locale": map[string]interface{}{
+						"type":        "string",
+						"description": "Browser locale (e.g. \"de-DE\")",
+					},
+					"timezone": map[string]interface{}{
+						"type":        "string",
+						"description": "IANA timezone (e.g. \"Europe/Berlin\")",
+					},
+					"geolocation": map[string]interface{}{
+						"type":        "object",
+						"description": "Emulated position reported by the Geolocation API",
+						"properties": map[string]interface{}{
+							"latitude":  map[string]interface{}{"type": "number"},
+							"longitude": map[string]interface{}{"type": "number"},
+							"accuracy":  map[string]interface{}{"type": "number", "description": "Accuracy in meters"},
+						},
+						"required": []string{"latitude", "longitude"},
+					},
+					"dialogPolicy": map[string]interface{}{
+						"type":        "string",
+						"description": "How to handle alert, confirm and prompt dialogs; fail leaves them open for browser_handle_dialog",
+						"enum":        []string{"accept", "dismiss", "fail"},
+						"default":     "dismiss",
+					},
+					"userAgent": map[string]interface{}{
+						"type":        "string",
+						"description": "Override the browser user agent",
+					},
+					"pacing": map[string]interface{}{
+						"type":        "object",
+						"description": "Pace clicks, pointer moves and typing like a person (eased paths, held clicks, spaced keystrokes). Pass {} for defaults.",
+						"properties": map[string]interface{}{
+							"moveDuration": map[string]interface{}{"type": "number", "description": "Milliseconds the pointer takes to reach a target (default 250)"},
+							"moveSteps":    map[string]interface{}{"type": "number", "description": "Pointer moves along each path (default 20)"},
+							"clickDelay":   map[string]interface{}{"type": "number", "description": "Milliseconds a mouse button is held (default 60)"},
+							"keyDelay":     map[string]interface{}{"type": "number", "description": "Milliseconds between keystrokes (default 80)"},
+							"jitter":       map[string]interface{}{"type": "number", "description": "Random variation of delays, 0 to 1 (default 0.3)"},
+							"seed":         map[string]interface{}{"type": "number", "description": "Random seed to reproduce a run"},
+						},
+					},
+					"args": map[string]interface{}{
+						"type":        "array",
+						"description": "Extra browser arguments (e.g. [\"--start-maximized\"])",
+						"items":       map[string]interface{}{"type": "string"},
+					},
+					"ignoreDefaultArgs": map[string]interface{}{
+						"type":        "array",
+						"description": "Default Chrome arguments to drop, by name (e.g. [\"--disable-extensions\"])",
+						"items":       map[string]interface{}{"type": "string"},
+					},
+					"userDataDir": map[string]interface{}{
+						"type":        "string",
+						"description": "Directory to keep the browser profile in across sessions (created if missing)",
+					},
+					"proxyServer": map[string]interface{}{
+						"type":        "string",
+						"description": "Proxy as [SCHEME://]HOST:PORT (e.g. \"http://proxy.corp:3128\")",
+					},
+					"proxyBypass": map[string]interface{}{
+						"type":        "string",
+						"description": "Comma-separated hosts that skip the proxy (e.g. \"localhost,*.internal\")",
+					},
+					"extensions": map[string]interface{}{
+						"type":        "array",
+						"description": "Unpacked extension directories to load",
+						"items":       map[string]interface{}{"type": "string"},
+					},
+					"env": map[string]interface{}{
+						"type":                 "object",
+						"description":          "Environment variables for the browser",
+						"additionalProperties": map[string]interface{}{"type": "string"},
+					},
+					"executablePath": map[string]interface{}{
+						"type":        "string",
+						"description": "Browser executable to launch instead of the one found",
+					},
+					"chromedriverPath": map[string]interface{}{
+						"type":        "string",
+						"description": "Driver executable (chromedriver, or geckodriver for Firefox) to use instead of the one found",
+					},
+					"launchTimeout": map[string]interface{}{
+						"type":        "number",
+						"description": "Milliseconds starting the driver and creating the session may take (default 30000)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "browser_set_viewport",
+			Description: "Resize the viewport of the current tab, by size or device profile",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"device": map[string]interface{}{
+						"type":        "string",
+						"description": "Device profile to emulate: its viewport, user agent and touch support (e.g. \"Pixel 7\")",
+					},
+					"width": map[string]interface{}{
+						"type":        "number",
+						"description": "Viewport width in CSS pixels",
+					},
+					"height": map[string]interface{}{
+						"type":        "number",
+						"description": "Viewport height in CSS pixels",
+					},
+					"devicePixelRatio": map[string]interface{}{
+						"type":        "number",
+						"description": "Device pixel ratio (e.g. 2 for retina)",
+					},
 				},
 			},
 		},
@@ -31,6 +161,36 @@ func GetToolSchemas() []Tool {
 				"required": []string{"url"},
 			},
 		},
+		{
+			Name:        "browser_back",
+			Description: "Go back one page in the browser history",
+			InputSchema: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+		{
+			Name:        "browser_forward",
+			Description: "Go forward one page in the browser history",
+			InputSchema: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+		{
+			Name:        "browser_reload",
+			Description: "Reload the current page",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"ignoreCache": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Bypass the cache when reloading",
+						"default":     false,
+					},
+				},
+			},
+		},
 		{
 			Name:        "browser_click",
 			Description: "Click an element by CSS selector. Waits for element to be visible, stable, and enabled.",
@@ -41,10 +201,118 @@ func GetToolSchemas() []Tool {
 						"type":        "string",
 						"description": "CSS selector for the element to click",
 					},
+					"button": map[string]interface{}{
+						"type":        "string",
+						"description": "Mouse button to click with",
+						"enum":        []string{"left", "middle", "right"},
+						"default":     "left",
+					},
+					"clickCount": map[string]interface{}{
+						"type":        "number",
+						"description": "Number of clicks (2 for a double-click)",
+						"default":     1,
+					},
+					"modifiers": map[string]interface{}{
+						"type":        "array",
+						"description": "Modifier keys to hold while clicking",
+						"items": map[string]interface{}{
+							"type": "string",
+							"enum": []string{"Shift", "Control", "Alt", "Meta"},
+						},
+					},
+					"position": map[string]interface{}{
+						"type":        "object",
+						"description": "Click position relative to the element's top-left corner (default: center)",
+						"properties": map[string]interface{}{
+							"x": map[string]interface{}{"type": "number"},
+							"y": map[string]interface{}{"type": "number"},
+						},
+						"required": []string{"x", "y"},
+					},
+					"delay": map[string]interface{}{
+						"type":        "number",
+						"description": "Milliseconds to hold the button down",
+					},
+					"newTab": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Wait for a tab or popup the click opens and make it the active tab",
+						"default":     false,
+					},
 				},
 				"required": []string{"selector"},
 			},
 		},
+		{
+			Name:        "browser_hover",
+			Description: "Move the mouse over an element by CSS selector. Waits for element to be visible and stable.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"selector": map[string]interface{}{
+						"type":        "string",
+						"description": "CSS selector for the element to hover",
+					},
+				},
+				"required": []string{"selector"},
+			},
+		},
+		{
+			Name:        "browser_drag",
+			Description: "Drag an element onto another with the mouse",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"source": map[string]interface{}{
+						"type":        "string",
+						"description": "CSS selector for the element to drag",
+					},
+					"target": map[string]interface{}{
+						"type":        "string",
+						"description": "CSS selector for the element to drop onto",
+					},
+				},
+				"required": []string{"source", "target"},
+			},
+		},
+		{
+			Name:        "browser_press",
+			Description: "Press a key or key chord, e.g. \"Enter\", \"Control+A\" or \"Meta+Shift+K\"",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"keys": map[string]interface{}{
+						"type":        "string",
+						"description": "Key name or chord joined with \"+\" (Enter, Tab, Escape, ArrowDown, F5, Control, Shift, Alt, Meta or a character)",
+					},
+					"selector": map[string]interface{}{
+						"type":        "string",
+						"description": "CSS selector for an element to click (focus) first",
+					},
+				},
+				"required": []string{"keys"},
+			},
+		},
+		{
+			Name:        "browser_scroll",
+			Description: "Scroll with the mouse wheel, over an element or the page",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"deltaX": map[string]interface{}{
+						"type":        "number",
+						"description": "Horizontal scroll in pixels",
+					},
+					"deltaY": map[string]interface{}{
+						"type":        "number",
+						"description": "Vertical scroll in pixels (positive scrolls down)",
+					},
+					"selector": map[string]interface{}{
+						"type":        "string",
+						"description": "CSS selector for the element to scroll over (default: the page)",
+					},
+				},
+			},
+		},
 		{
 			Name:        "browser_type",
 			Description: "Type text into an element by CSS selector. Waits for element to be visible, stable, enabled, and editable.",
@@ -63,9 +331,108 @@ func GetToolSchemas() []Tool {
 				"required": []string{"selector", "text"},
 			},
 		},
+		{
+			Name:        "browser_fill",
+			Description: "Replace the content of a text field: clears it, types the value and fires change. Waits for element to be visible, stable, enabled, and editable.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"selector": map[string]interface{}{
+						"type":        "string",
+						"description": "CSS selector for the input, textarea or contenteditable element",
+					},
+					"value": map[string]interface{}{
+						"type":        "string",
+						"description": "The new content (empty clears the field)",
+					},
+				},
+				"required": []string{"selector", "value"},
+			},
+		},
+		{
+			Name:        "browser_select",
+			Description: "Select options of a <select> by value, label or index; other options are deselected",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"selector": map[string]interface{}{
+						"type":        "string",
+						"description": "CSS selector for the <select> element",
+					},
+					"values": map[string]interface{}{
+						"type":        "array",
+						"description": "Option values to select",
+						"items":       map[string]interface{}{"type": "string"},
+					},
+					"labels": map[string]interface{}{
+						"type":        "array",
+						"description": "Visible option texts to select",
+						"items":       map[string]interface{}{"type": "string"},
+					},
+					"indexes": map[string]interface{}{
+						"type":        "array",
+						"description": "Zero-based option positions to select",
+						"items":       map[string]interface{}{"type": "number"},
+					},
+				},
+				"required": []string{"selector"},
+			},
+		},
+		{
+			Name:        "browser_check",
+			Description: "Check or uncheck a checkbox or radio button by clicking it (no-op if already in that state)",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"selector": map[string]interface{}{
+						"type":        "string",
+						"description": "CSS selector for the checkbox or radio button",
+					},
+					"checked": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Whether the element should end up checked",
+						"default":     true,
+					},
+				},
+				"required": []string{"selector"},
+			},
+		},
+		{
+			Name:        "browser_upload",
+			Description: "Set the files of a file input. Paths must be inside the upload directory",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"selector": map[string]interface{}{
+						"type":        "string",
+						"description": "CSS selector for the <input type=\"file\"> element",
+					},
+					"paths": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Files to upload, absolute or relative to the upload directory",
+					},
+				},
+				"required": []string{"selector", "paths"},
+			},
+		},
+		{
+			Name:        "browser_wait_for_download",
+			Description: "Wait for the next download to finish and return its saved path, filename and size",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"timeout": map[string]interface{}{
+						"type":        "number",
+						"description": "Timeout in milliseconds",
+						"default":     30000,
+					},
+				},
+			},
+		},
 		{
 			Name:        "browser_screenshot",
-			Description: "Capture a screenshot of the current page",
+			Description: "Capture a screenshot of the viewport, the full page, a single element, or a rectangle",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
@@ -73,6 +440,92 @@ func GetToolSchemas() []Tool {
 						"type":        "string",
 						"description": "Optional filename to save the screenshot (e.g., screenshot.png)",
 					},
+					"fullPage": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Capture the full scrollable page instead of the viewport",
+						"default":     false,
+					},
+					"selector": map[string]interface{}{
+						"type":        "string",
+						"description": "Capture only the element matching this CSS selector",
+					},
+					"clip": map[string]interface{}{
+						"type":        "object",
+						"description": "Capture only this rectangle, in CSS pixels relative to the viewport (or the document with fullPage)",
+						"properties": map[string]interface{}{
+							"x":      map[string]interface{}{"type": "number"},
+							"y":      map[string]interface{}{"type": "number"},
+							"width":  map[string]interface{}{"type": "number"},
+							"height": map[string]interface{}{"type": "number"},
+						},
+						"required": []string{"x", "y", "width", "height"},
+					},
+					"format": map[string]interface{}{
+						"type":        "string",
+						"description": "Image format",
+						"enum":        []string{"png", "jpeg", "webp"},
+						"default":     "png",
+					},
+					"quality": map[string]interface{}{
+						"type":        "number",
+						"description": "JPEG/WebP quality from 1 to 100",
+					},
+				},
+			},
+		},
+		{
+			Name:        "browser_pdf",
+			Description: "Print the current page to a PDF file in the screenshot directory",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"filename": map[string]interface{}{
+						"type":        "string",
+						"description": "Filename to save the PDF (e.g., invoice.pdf)",
+						"default":     "page.pdf",
+					},
+					"paper": map[string]interface{}{
+						"type":        "string",
+						"description": "Paper size",
+						"enum":        []string{"Letter", "Legal", "Tabloid", "Ledger", "A0", "A1", "A2", "A3", "A4", "A5", "A6"},
+					},
+					"width": map[string]interface{}{
+						"type":        "number",
+						"description": "Page width in centimeters (ignored when paper is set)",
+					},
+					"height": map[string]interface{}{
+						"type":        "number",
+						"description": "Page height in centimeters (ignored when paper is set)",
+					},
+					"margin": map[string]interface{}{
+						"type":        "object",
+						"description": "Page margins in centimeters",
+						"properties": map[string]interface{}{
+							"top":    map[string]interface{}{"type": "number"},
+							"right":  map[string]interface{}{"type": "number"},
+							"bottom": map[string]interface{}{"type": "number"},
+							"left":   map[string]interface{}{"type": "number"},
+						},
+					},
+					"landscape": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Print in landscape orientation",
+						"default":     false,
+					},
+					"scale": map[string]interface{}{
+						"type":        "number",
+						"description": "Page scale from 0.1 to 2",
+						"default":     1,
+					},
+					"background": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Print background graphics",
+						"default":     false,
+					},
+					"pageRanges": map[string]interface{}{
+						"type":        "string",
+						"description": "Pages to print, e.g. 1-3,5",
+					},
 				},
 			},
 		},
@@ -90,6 +543,28 @@ func GetToolSchemas() []Tool {
 				"required": []string{"selector"},
 			},
 		},
+		{
+			Name:        "browser_handle_dialog",
+			Description: "Accept or dismiss the open alert, confirm, prompt or beforeunload dialog, and optionally change how future dialogs are handled",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"accept": map[string]interface{}{
+						"type":        "boolean",
+						"description": "true to accept (OK), false to dismiss (Cancel)",
+					},
+					"text": map[string]interface{}{
+						"type":        "string",
+						"description": "Text to enter into a prompt() dialog when accepting",
+					},
+					"policy": map[string]interface{}{
+						"type":        "string",
+						"description": "How to handle future dialogs automatically; fail leaves them open for browser_handle_dialog",
+						"enum":        []string{"accept", "dismiss", "fail"},
+					},
+				},
+			},
+		},
 		{
 			Name:        "browser_quit",
 			Description: "Close the browser session",
@@ -98,5 +573,66 @@ func GetToolSchemas() []Tool {
 				"properties": map[string]interface{}{},
 			},
 		},
+		{
+			Name:        "browser_tabs_list",
+			Description: "List open tabs and windows. The active tab is marked with *",
+			InputSchema: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+		{
+			Name:        "browser_tab_new",
+			Description: "Open a new tab or window and make it the active tab",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"url": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional URL to open in the new tab",
+					},
+					"type": map[string]interface{}{
+						"type":        "string",
+						"description": "Open a tab or a window",
+						"enum":        []string{"tab", "window"},
+						"default":     "tab",
+					},
+				},
+			},
+		},
+		{
+			Name:        "browser_tab_switch",
+			Description: "Switch the active tab by index (from browser_tabs_list) or id",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"index": map[string]interface{}{
+						"type":        "number",
+						"description": "Tab index as shown by browser_tabs_list",
+					},
+					"id": map[string]interface{}{
+						"type":        "string",
+						"description": "Browsing context id of the tab",
+					},
+				},
+			},
+		},
+		{
+			Name:        "browser_tab_close",
+			Description: "Close a tab by index or id (the active tab by default)",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"index": map[string]interface{}{
+						"type":        "number",
+						"description": "Tab index as shown by browser_tabs_list",
+					},
+					"id": map[string]interface{}{
+						"type":        "string",
+						"description": "Browsing context id of the tab",
+					},
+				},
+			},
+		},
 	}
 }
@@ -9,6 +9,7 @@ import (
 	"io"
 	"os"
 
+	errs "github.com/vibium/clicker/internal/errors"
 	"github.com/vibium/clicker/internal/log"
 )
 
@@ -103,8 +104,16 @@ type ToolsCallParams struct {
 }
 
 type ToolsCallResult struct {
-	Content []Content `json:"content"`
-	IsError bool      `json:"isError,omitempty"`
+	Content           []Content   `json:"content"`
+	StructuredContent interface{} `json:"structuredContent,omitempty"`
+	IsError           bool        `json:"isError,omitempty"`
+}
+
+// ToolError is the structured content of a failed tool call, so clients can
+// tell failures apart without parsing the message.
+type ToolError struct {
+	Code    errs.Code `json:"code"`
+	Message string    `json:"message"`
 }
 
 type Content struct {
@@ -125,6 +134,23 @@ type Server struct {
 // ServerOptions configures the MCP server.
 type ServerOptions struct {
 	ScreenshotDir string // Directory for saving screenshots (empty = disabled)
+	UploadDir     string // Directory browser_upload may read files from (empty = disabled)
+	DownloadDir   string // Directory the browser saves downloads to (empty = browser default)
+
+	// AutoRecover relaunches a crashed browser with the original launch
+	// options and reopens the last URL; RecoverStorage (which implies it)
+	// also restores cookies and localStorage.
+	AutoRecover    bool
+	RecoverStorage bool
+
+	// Browser is the browser browser_launch starts unless it is given one:
+	// "chrome" (default) or "firefox".
+	Browser string
+
+	// ConnectURL makes browser_launch attach to an existing browser, with
+	// Capabilities for the WebDriver session (see browser.Connect).
+	ConnectURL   string
+	Capabilities map[string]interface{}
 }
 
 // NewServer creates a new MCP server.
@@ -132,7 +158,7 @@ func NewServer(version string, opts ServerOptions) *Server {
 	return &Server{
 		reader:   bufio.NewReader(os.Stdin),
 		writer:   os.Stdout,
-		handlers: NewHandlers(opts.ScreenshotDir),
+		handlers: NewHandlers(opts),
 		version:  version,
 	}
 }
@@ -281,6 +307,9 @@ func (s *Server) handleToolsCall(params json.RawMessage) (interface{}, *Error) {
 	if err != nil {
 		return ToolsCallResult{
 			Content: []Content{{Type: "text", Text: err.Error()}},
+			StructuredContent: map[string]ToolError{
+				"error": {Code: errs.CodeOf(err), Message: err.Error()},
+			},
 			IsError: true,
 		}, nil
 	}
@@ -2,8 +2,12 @@ package paths
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 // GetCacheDir returns the platform-specific cache directory for Vibium.
@@ -60,26 +64,134 @@ func GetChromeForTestingDir() (string, error) {
 	return filepath.Join(cacheDir, "chrome-for-testing"), nil
 }
 
-// GetChromeExecutable returns the path to Chrome executable.
-// First checks Vibium cache for Chrome for Testing, then falls back to system Chrome.
-func GetChromeExecutable() (string, error) {
-	// First, check for cached Chrome for Testing
+// ChromeVersionEnv pins the Chrome for Testing version GetChromeExecutable
+// and GetChromedriverPath use, e.g. "131.0.6778.85" or just "131".
+const ChromeVersionEnv = "VIBIUM_CHROME_VERSION"
+
+// InstallMarker is the file 'clicker install' writes into a version
+// directory once it is fully extracted. Directories without it are partial
+// installs and are ignored, unless they hold both Chrome and chromedriver:
+// installs made before the marker existed.
+const InstallMarker = ".installed"
+
+// InstalledChrome is a Chrome for Testing version in the Vibium cache.
+// ChromedriverPath is empty if that version has no chromedriver.
+type InstalledChrome struct {
+	Version          string
+	Dir              string
+	ChromePath       string
+	ChromedriverPath string
+}
+
+// ListInstalledChrome returns the completely installed Chrome for Testing
+// versions in the cache, newest first.
+func ListInstalledChrome() ([]InstalledChrome, error) {
 	cftDir, err := GetChromeForTestingDir()
-	if err == nil {
-		// Look for version directories
-		entries, err := os.ReadDir(cftDir)
-		if err == nil && len(entries) > 0 {
-			// Use the first (or latest) version found
-			for _, entry := range entries {
-				if entry.IsDir() {
-					chromePath := getChromePathInVersion(filepath.Join(cftDir, entry.Name()))
-					if _, err := os.Stat(chromePath); err == nil {
-						return chromePath, nil
-					}
-				}
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(cftDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var installed []InstalledChrome
+	for _, entry := range entries {
+		// Dot directories are the installer's work and download dirs
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		versionDir := filepath.Join(cftDir, entry.Name())
+		chromePath := getChromePathInVersion(versionDir)
+		if _, err := os.Stat(chromePath); err != nil {
+			continue
+		}
+		c := InstalledChrome{Version: entry.Name(), Dir: versionDir, ChromePath: chromePath}
+		if driverPath := getChromedriverPathInVersion(versionDir); fileExists(driverPath) {
+			c.ChromedriverPath = driverPath
+		}
+		if !fileExists(filepath.Join(versionDir, InstallMarker)) && c.ChromedriverPath == "" {
+			continue
+		}
+		installed = append(installed, c)
+	}
+
+	sort.Slice(installed, func(i, j int) bool {
+		return CompareVersions(installed[i].Version, installed[j].Version) > 0
+	})
+	return installed, nil
+}
+
+// FindInstalledChrome returns the newest installed Chrome for Testing whose
+// version is version or starts with it ("131" matches 131.0.6778.85).
+// An empty version means the one pinned by VIBIUM_CHROME_VERSION, or else
+// the newest installed.
+func FindInstalledChrome(version string) (*InstalledChrome, error) {
+	if version == "" {
+		version = os.Getenv(ChromeVersionEnv)
+	}
+
+	installed, err := ListInstalledChrome()
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range installed {
+		if MatchVersion(c.Version, version) {
+			return &c, nil
+		}
+	}
+	return nil, os.ErrNotExist
+}
+
+// MatchVersion reports whether version is pattern or starts with pattern
+// followed by a dot. An empty pattern matches every version.
+func MatchVersion(version, pattern string) bool {
+	return pattern == "" || version == pattern || strings.HasPrefix(version, pattern+".")
+}
+
+// CompareVersions compares dotted versions numerically: it returns -1 if a
+// is older than b, 1 if newer and 0 if they are equal.
+func CompareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
 			}
+			return 1
 		}
 	}
+	return 0
+}
+
+// GetChromeExecutable returns the path to Chrome executable.
+// First checks Vibium cache for Chrome for Testing, then falls back to system Chrome.
+func GetChromeExecutable() (string, error) {
+	return GetChromeExecutableFor("")
+}
+
+// GetChromeExecutableFor returns the Chrome executable of the installed
+// Chrome for Testing matching version (see FindInstalledChrome). Only when
+// no version is asked for, by version or VIBIUM_CHROME_VERSION, does it fall
+// back to system Chrome.
+func GetChromeExecutableFor(version string) (string, error) {
+	if c, err := FindInstalledChrome(version); err == nil {
+		return c.ChromePath, nil
+	}
+	if version != "" || os.Getenv(ChromeVersionEnv) != "" {
+		return "", os.ErrNotExist
+	}
 
 	// Fall back to system Chrome
 	return getSystemChromePath()
@@ -87,26 +199,25 @@ func GetChromeExecutable() (string, error) {
 
 // GetChromedriverPath returns the path to the cached chromedriver.
 func GetChromedriverPath() (string, error) {
-	cftDir, err := GetChromeForTestingDir()
-	if err != nil {
-		return "", err
-	}
+	return GetChromedriverPathFor("")
+}
 
-	entries, err := os.ReadDir(cftDir)
+// GetChromedriverPathFor returns the chromedriver of the installed Chrome
+// for Testing matching version (see FindInstalledChrome).
+func GetChromedriverPathFor(version string) (string, error) {
+	c, err := FindInstalledChrome(version)
 	if err != nil {
 		return "", err
 	}
-
-	for _, entry := range entries {
-		if entry.IsDir() {
-			driverPath := getChromedriverPathInVersion(filepath.Join(cftDir, entry.Name()))
-			if _, err := os.Stat(driverPath); err == nil {
-				return driverPath, nil
-			}
-		}
+	if c.ChromedriverPath == "" {
+		return "", os.ErrNotExist
 	}
+	return c.ChromedriverPath, nil
+}
 
-	return "", os.ErrNotExist
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
 }
 
 // getChromePathInVersion returns the Chrome executable path within a version directory.
@@ -190,6 +301,86 @@ func getSystemChromePath() (string, error) {
 	return "", os.ErrNotExist
 }
 
+// GetGeckodriverDir returns the directory where geckodriver may be cached,
+// one version directory per release.
+func GetGeckodriverDir() (string, error) {
+	cacheDir, err := GetCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "geckodriver"), nil
+}
+
+// GetGeckodriverPath returns the path to geckodriver.
+// First checks the Vibium cache, then falls back to geckodriver in PATH.
+func GetGeckodriverPath() (string, error) {
+	name := "geckodriver"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+
+	if dir, err := GetGeckodriverDir(); err == nil {
+		if entries, err := os.ReadDir(dir); err == nil {
+			for _, entry := range entries {
+				if entry.IsDir() {
+					driverPath := filepath.Join(dir, entry.Name(), name)
+					if _, err := os.Stat(driverPath); err == nil {
+						return driverPath, nil
+					}
+				}
+			}
+		}
+	}
+
+	return lookPath("geckodriver")
+}
+
+// GetFirefoxExecutable returns the path to system-installed Firefox.
+func GetFirefoxExecutable() (string, error) {
+	var paths []string
+
+	switch runtime.GOOS {
+	case "darwin":
+		paths = []string{
+			"/Applications/Firefox.app/Contents/MacOS/firefox",
+			"/Applications/Firefox Developer Edition.app/Contents/MacOS/firefox",
+			"/Applications/Firefox Nightly.app/Contents/MacOS/firefox",
+		}
+	case "windows":
+		programFiles := os.Getenv("PROGRAMFILES")
+		programFilesX86 := os.Getenv("PROGRAMFILES(X86)")
+
+		paths = []string{
+			filepath.Join(programFiles, "Mozilla Firefox", "firefox.exe"),
+			filepath.Join(programFilesX86, "Mozilla Firefox", "firefox.exe"),
+		}
+	default: // linux
+		paths = []string{
+			"/usr/bin/firefox",
+			"/usr/bin/firefox-esr",
+			"/usr/lib/firefox/firefox",
+			"/snap/bin/firefox",
+		}
+	}
+
+	for _, p := range paths {
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+
+	return lookPath("firefox")
+}
+
+// lookPath finds an executable in PATH, reporting os.ErrNotExist if it is missing.
+func lookPath(name string) (string, error) {
+	p, err := exec.LookPath(name)
+	if err != nil {
+		return "", os.ErrNotExist
+	}
+	return p, nil
+}
+
 // GetPlatformString is exported for use by the installer.
 func GetPlatformString() string {
 	return getPlatformString()
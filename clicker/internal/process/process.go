@@ -10,6 +10,8 @@ import (
 type Manager struct {
 	mu       sync.Mutex
 	browsers []*exec.Cmd
+	cleanups map[int]func()
+	next     int
 }
 
 // Global manager instance
@@ -34,14 +36,41 @@ func Untrack(cmd *exec.Cmd) {
 	}
 }
 
-// KillAll terminates all tracked browser processes and their children.
-func KillAll() {
+// OnKill registers fn to run when KillAll cleans up, for browsers no
+// process kill ends, such as a session on a remote WebDriver. Call the
+// returned function to unregister fn once the browser is closed normally.
+func OnKill(fn func()) func() {
 	defaultManager.mu.Lock()
 	defer defaultManager.mu.Unlock()
+	if defaultManager.cleanups == nil {
+		defaultManager.cleanups = map[int]func(){}
+	}
+	id := defaultManager.next
+	defaultManager.next++
+	defaultManager.cleanups[id] = fn
+
+	return func() {
+		defaultManager.mu.Lock()
+		defer defaultManager.mu.Unlock()
+		delete(defaultManager.cleanups, id)
+	}
+}
+
+// KillAll terminates all tracked browser processes and their children, and
+// runs the functions registered with OnKill.
+func KillAll() {
+	defaultManager.mu.Lock()
+	cleanups := defaultManager.cleanups
+	defaultManager.cleanups = nil
 	for _, cmd := range defaultManager.browsers {
 		killProcess(cmd)
 	}
 	defaultManager.browsers = nil
+	defaultManager.mu.Unlock()
+
+	for _, fn := range cleanups {
+		fn()
+	}
 }
 
 // KillBrowser terminates a specific browser process.
@@ -40,3 +40,9 @@ func WithCleanup(fn func()) {
 	}()
 	fn()
 }
+
+// Detach makes cmd run in its own session, so it keeps running after the
+// terminal that started it closes.
+func Detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
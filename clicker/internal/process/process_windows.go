@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"os/signal"
+	"syscall"
 
 	"github.com/vibium/clicker/internal/log"
 )
@@ -33,3 +34,14 @@ func WithCleanup(fn func()) {
 	}()
 	fn()
 }
+
+// detachedProcess is the DETACHED_PROCESS creation flag (no console).
+const detachedProcess = 0x00000008
+
+// Detach makes cmd run without a console in its own process group, so it
+// keeps running after the terminal that started it closes.
+func Detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | detachedProcess,
+	}
+}
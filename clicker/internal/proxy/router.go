@@ -3,11 +3,15 @@ package proxy
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
 	"github.com/vibium/clicker/internal/bidi"
 	"github.com/vibium/clicker/internal/browser"
+	"github.com/vibium/clicker/internal/devices"
+	errs "github.com/vibium/clicker/internal/errors"
 	"github.com/vibium/clicker/internal/recording"
 )
 
@@ -31,6 +35,21 @@ type BrowserSession struct {
 
 	// Video recording
 	recorder *recording.Recorder
+
+	// Download events seen on the browser connection, consumed by vibium:waitForDownload
+	downloadEvents chan *bidi.Event
+
+	// Contexts of the history events seen on the browser connection,
+	// consumed by vibium:back and vibium:forward. Whether the events were
+	// subscribed to, and whether historyUpdated is missing, are guarded by mu.
+	historyEvents     chan string
+	historySubscribed bool
+	noHistoryUpdated  bool
+
+	// Dialog handling: the policy for new dialogs, and the context of the
+	// dialog left open under the fail policy. Guarded by mu.
+	dialogPolicy      string
+	openDialogContext string
 }
 
 // BiDi command structure for parsing incoming messages
@@ -55,14 +74,14 @@ type bidiError struct {
 
 // Router manages browser sessions for connected clients.
 type Router struct {
-	sessions sync.Map // map[uint64]*BrowserSession (client ID -> session)
-	headless bool
+	sessions   sync.Map // map[uint64]*BrowserSession (client ID -> session)
+	launchOpts browser.LaunchOptions
 }
 
-// NewRouter creates a new router.
-func NewRouter(headless bool) *Router {
+// NewRouter creates a new router that launches browsers with the given options.
+func NewRouter(launchOpts browser.LaunchOptions) *Router {
 	return &Router{
-		headless: headless,
+		launchOpts: launchOpts,
 	}
 }
 
@@ -72,12 +91,10 @@ func (r *Router) OnClientConnect(client *ClientConn) {
 	fmt.Printf("[router] Launching browser for client %d...\n", client.ID)
 
 	// Launch browser
-	launchResult, err := browser.Launch(browser.LaunchOptions{
-		Headless: r.headless,
-	})
+	launchResult, err := browser.Start(r.launchOpts)
 	if err != nil {
 		fmt.Printf("[router] Failed to launch browser for client %d: %v\n", client.ID, err)
-		client.Send(fmt.Sprintf(`{"error":{"code":-32000,"message":"Failed to launch browser: %s"}}`, err.Error()))
+		client.Send(connectError("Failed to launch browser", err))
 		client.Close()
 		return
 	}
@@ -89,7 +106,7 @@ func (r *Router) OnClientConnect(client *ClientConn) {
 	if err != nil {
 		fmt.Printf("[router] Failed to connect to browser BiDi for client %d: %v\n", client.ID, err)
 		launchResult.Close()
-		client.Send(fmt.Sprintf(`{"error":{"code":-32000,"message":"Failed to connect to browser: %s"}}`, err.Error()))
+		client.Send(connectError("Failed to connect to browser", err))
 		client.Close()
 		return
 	}
@@ -99,6 +116,23 @@ func (r *Router) OnClientConnect(client *ClientConn) {
 	// Create a BiDi client for handling custom commands
 	bidiClient := bidi.NewClient(bidiConn)
 
+	// Apply emulation settings before the routing goroutine takes over the connection
+	if err := browser.ApplyEmulation(bidiClient, "", r.launchOpts); err != nil {
+		fmt.Printf("[router] Failed to apply emulation for client %d: %v\n", client.ID, err)
+	}
+	if err := browser.PrepareSession(bidiClient, r.launchOpts); err != nil {
+		fmt.Printf("[router] Failed to prepare session for client %d: %v\n", client.ID, err)
+	}
+
+	if p := bidiClient.Pacer(); p != nil {
+		fmt.Printf("[router] Input pacing for client %d (seed %d)\n", client.ID, p.Seed())
+	}
+
+	dialogPolicy, err := bidi.ParseDialogPolicy(r.launchOpts.DialogPolicy)
+	if err != nil {
+		dialogPolicy = bidi.DialogDismiss
+	}
+
 	session := &BrowserSession{
 		LaunchResult:   launchResult,
 		BidiConn:       bidiConn,
@@ -107,6 +141,9 @@ func (r *Router) OnClientConnect(client *ClientConn) {
 		stopChan:       make(chan struct{}),
 		internalCmds:   make(map[int]chan json.RawMessage),
 		nextInternalID: 1000000, // Start at high number to avoid collision with client IDs
+		downloadEvents: make(chan *bidi.Event, 64),
+		historyEvents:  make(chan string, 64),
+		dialogPolicy:   dialogPolicy,
 	}
 
 	r.sessions.Store(client.ID, session)
@@ -151,9 +188,70 @@ func (r *Router) OnClientMessage(client *ClientConn, msg string) {
 	case "vibium:type":
 		r.handleVibiumType(session, cmd)
 		return
+	case "vibium:fill":
+		r.handleVibiumFill(session, cmd)
+		return
+	case "vibium:clear":
+		r.handleVibiumClear(session, cmd)
+		return
+	case "vibium:select":
+		r.handleVibiumSelect(session, cmd)
+		return
+	case "vibium:check":
+		r.handleVibiumCheck(session, cmd)
+		return
+	case "vibium:hover":
+		r.handleVibiumHover(session, cmd)
+		return
+	case "vibium:drag":
+		r.handleVibiumDrag(session, cmd)
+		return
+	case "vibium:press":
+		r.handleVibiumPress(session, cmd)
+		return
+	case "vibium:scroll":
+		r.handleVibiumScroll(session, cmd)
+		return
 	case "vibium:find":
 		r.handleVibiumFind(session, cmd)
 		return
+	case "vibium:back":
+		r.handleVibiumTraverseHistory(session, cmd, -1)
+		return
+	case "vibium:forward":
+		r.handleVibiumTraverseHistory(session, cmd, 1)
+		return
+	case "vibium:reload":
+		r.handleVibiumReload(session, cmd)
+		return
+	case "vibium:stop":
+		r.handleVibiumStop(session, cmd)
+		return
+	case "vibium:setViewport":
+		r.handleVibiumSetViewport(session, cmd)
+		return
+	case "vibium:emulate":
+		r.handleVibiumEmulate(session, cmd)
+		return
+	case "vibium:screenshot":
+		r.handleVibiumScreenshot(session, cmd)
+		return
+	case "vibium:setFiles":
+		r.handleVibiumSetFiles(session, cmd)
+		return
+	case "vibium:waitForDownload":
+		// Runs in the background so the client can trigger the download while waiting
+		go r.handleVibiumWaitForDownload(session, cmd)
+		return
+	case "vibium:handleDialog":
+		r.handleVibiumHandleDialog(session, cmd)
+		return
+	case "vibium:setDialogPolicy":
+		r.handleVibiumSetDialogPolicy(session, cmd)
+		return
+	case "vibium:pdf":
+		r.handleVibiumPDF(session, cmd)
+		return
 	case "vibium:startRecording":
 		r.handleVibiumStartRecording(session, cmd)
 		return
@@ -169,11 +267,19 @@ func (r *Router) OnClientMessage(client *ClientConn, msg string) {
 }
 
 // handleVibiumClick handles the vibium:click command with actionability checks.
+// Optional params: button, clickCount, modifiers, position {x, y} relative to
+// the element's top-left corner, and delay (ms between press and release).
 func (r *Router) handleVibiumClick(session *BrowserSession, cmd bidiCommand) {
 	selector, _ := cmd.Params["selector"].(string)
 	context, _ := cmd.Params["context"].(string)
 	timeoutMs, _ := cmd.Params["timeout"].(float64)
 
+	opts := clickOptionsParams(cmd.Params)
+	if _, err := bidi.ClickActions(0, 0, opts); err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+
 	timeout := defaultTimeout
 	if timeoutMs > 0 {
 		timeout = time.Duration(timeoutMs) * time.Millisecond
@@ -196,26 +302,23 @@ func (r *Router) handleVibiumClick(session *BrowserSession, cmd bidiCommand) {
 		return
 	}
 
-	// Perform the click at element center
-	x := int(info.Box.X + info.Box.Width/2)
-	y := int(info.Box.Y + info.Box.Height/2)
+	// Perform the click at element center, or at the requested offset
+	x := info.Box.X + info.Box.Width/2
+	y := info.Box.Y + info.Box.Height/2
+	if opts.Position != nil {
+		x = info.Box.X + opts.Position.X
+		y = info.Box.Y + opts.Position.Y
+	}
+
+	actions, err := r.clickActions(session, x, y, opts)
+	if err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
 
 	clickParams := map[string]interface{}{
 		"context": context,
-		"actions": []map[string]interface{}{
-			{
-				"type": "pointer",
-				"id":   "mouse",
-				"parameters": map[string]interface{}{
-					"pointerType": "mouse",
-				},
-				"actions": []map[string]interface{}{
-					{"type": "pointerMove", "x": x, "y": y, "duration": 0},
-					{"type": "pointerDown", "button": 0},
-					{"type": "pointerUp", "button": 0},
-				},
-			},
-		},
+		"actions": actions,
 	}
 
 	if _, err := r.sendInternalCommand(session, "input.performActions", clickParams); err != nil {
@@ -226,11 +329,46 @@ func (r *Router) handleVibiumClick(session *BrowserSession, cmd bidiCommand) {
 	r.sendSuccess(session, cmd.ID, map[string]interface{}{"clicked": true})
 }
 
-// handleVibiumType handles the vibium:type command with actionability checks.
-func (r *Router) handleVibiumType(session *BrowserSession, cmd bidiCommand) {
+// clickActions builds the input sources of a click at x, y, paced if the
+// session was launched with input pacing.
+func (r *Router) clickActions(session *BrowserSession, x, y float64, opts bidi.ClickOptions) ([]map[string]interface{}, error) {
+	if p := session.BidiClient.Pacer(); p != nil {
+		return p.ClickActions(x, y, opts)
+	}
+	return bidi.ClickActions(x, y, opts)
+}
+
+// clickOptionsParams reads the optional click params of vibium:click.
+func clickOptionsParams(params map[string]interface{}) bidi.ClickOptions {
+	var opts bidi.ClickOptions
+
+	opts.Button, _ = params["button"].(string)
+	if count, ok := params["clickCount"].(float64); ok {
+		opts.ClickCount = int(count)
+	}
+	if mods, ok := params["modifiers"].([]interface{}); ok {
+		for _, m := range mods {
+			if name, ok := m.(string); ok {
+				opts.Modifiers = append(opts.Modifiers, name)
+			}
+		}
+	}
+	if pos, ok := params["position"].(map[string]interface{}); ok {
+		x, _ := pos["x"].(float64)
+		y, _ := pos["y"].(float64)
+		opts.Position = &bidi.Point{X: x, Y: y}
+	}
+	if delay, ok := params["delay"].(float64); ok {
+		opts.Delay = time.Duration(delay) * time.Millisecond
+	}
+	return opts
+}
+
+// handleVibiumHover handles the vibium:hover command: it waits for the
+// element and moves the mouse to its center.
+func (r *Router) handleVibiumHover(session *BrowserSession, cmd bidiCommand) {
 	selector, _ := cmd.Params["selector"].(string)
 	context, _ := cmd.Params["context"].(string)
-	text, _ := cmd.Params["text"].(string)
 	timeoutMs, _ := cmd.Params["timeout"].(float64)
 
 	timeout := defaultTimeout
@@ -248,18 +386,16 @@ func (r *Router) handleVibiumType(session *BrowserSession, cmd bidiCommand) {
 		context = ctx
 	}
 
-	// Wait for element and get its position
 	info, err := r.waitForElement(session, context, selector, timeout)
 	if err != nil {
 		r.sendError(session, cmd.ID, err)
 		return
 	}
 
-	// Click to focus first
 	x := int(info.Box.X + info.Box.Width/2)
 	y := int(info.Box.Y + info.Box.Height/2)
 
-	clickParams := map[string]interface{}{
+	hoverParams := map[string]interface{}{
 		"context": context,
 		"actions": []map[string]interface{}{
 			{
@@ -270,56 +406,43 @@ func (r *Router) handleVibiumType(session *BrowserSession, cmd bidiCommand) {
 				},
 				"actions": []map[string]interface{}{
 					{"type": "pointerMove", "x": x, "y": y, "duration": 0},
-					{"type": "pointerDown", "button": 0},
-					{"type": "pointerUp", "button": 0},
 				},
 			},
 		},
 	}
-
-	if _, err := r.sendInternalCommand(session, "input.performActions", clickParams); err != nil {
-		r.sendError(session, cmd.ID, err)
-		return
-	}
-
-	// Build key actions for typing
-	keyActions := make([]map[string]interface{}, 0, len(text)*2)
-	for _, char := range text {
-		keyActions = append(keyActions,
-			map[string]interface{}{"type": "keyDown", "value": string(char)},
-			map[string]interface{}{"type": "keyUp", "value": string(char)},
-		)
-	}
-
-	typeParams := map[string]interface{}{
-		"context": context,
-		"actions": []map[string]interface{}{
-			{
-				"type":    "key",
-				"id":      "keyboard",
-				"actions": keyActions,
-			},
-		},
+	if p := session.BidiClient.Pacer(); p != nil {
+		hoverParams["actions"] = p.MoveActions(float64(x), float64(y))
 	}
 
-	if _, err := r.sendInternalCommand(session, "input.performActions", typeParams); err != nil {
+	if _, err := r.sendInternalCommand(session, "input.performActions", hoverParams); err != nil {
 		r.sendError(session, cmd.ID, err)
 		return
 	}
 
-	r.sendSuccess(session, cmd.ID, map[string]interface{}{"typed": true})
+	r.sendSuccess(session, cmd.ID, map[string]interface{}{"hovered": true})
 }
 
-// handleVibiumFind handles the vibium:find command with wait-for-selector.
-func (r *Router) handleVibiumFind(session *BrowserSession, cmd bidiCommand) {
-	selector, _ := cmd.Params["selector"].(string)
+// handleVibiumDrag handles the vibium:drag command: it drags the element
+// matching "source" onto the element matching "target".
+func (r *Router) handleVibiumDrag(session *BrowserSession, cmd bidiCommand) {
+	source, _ := cmd.Params["source"].(string)
+	target, _ := cmd.Params["target"].(string)
 	context, _ := cmd.Params["context"].(string)
 	timeoutMs, _ := cmd.Params["timeout"].(float64)
+	steps, _ := cmd.Params["steps"].(float64)
+
+	if source == "" || target == "" {
+		r.sendError(session, cmd.ID, errs.InvalidArgument("source and target are required"))
+		return
+	}
 
 	timeout := defaultTimeout
 	if timeoutMs > 0 {
 		timeout = time.Duration(timeoutMs) * time.Millisecond
 	}
+	if steps <= 0 {
+		steps = 10
+	}
 
 	// Get context if not provided
 	if context == "" {
@@ -331,140 +454,1427 @@ func (r *Router) handleVibiumFind(session *BrowserSession, cmd bidiCommand) {
 		context = ctx
 	}
 
-	// Wait for element
-	info, err := r.waitForElement(session, context, selector, timeout)
+	deadline := time.Now().Add(timeout)
+	src, err := r.waitForElement(session, context, source, timeout)
+	if err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+	dst, err := r.waitForElement(session, context, target, time.Until(deadline))
 	if err != nil {
 		r.sendError(session, cmd.ID, err)
 		return
 	}
 
-	r.sendSuccess(session, cmd.ID, map[string]interface{}{
-		"tag":  info.Tag,
-		"text": info.Text,
-		"box": map[string]interface{}{
-			"x":      info.Box.X,
-			"y":      info.Box.Y,
-			"width":  info.Box.Width,
-			"height": info.Box.Height,
-		},
-	})
-}
+	from := bidi.Point{X: src.Box.X + src.Box.Width/2, Y: src.Box.Y + src.Box.Height/2}
+	to := bidi.Point{X: dst.Box.X + dst.Box.Width/2, Y: dst.Box.Y + dst.Box.Height/2}
 
-// elementInfo holds parsed element information.
-type elementInfo struct {
-	Tag  string  `json:"tag"`
-	Text string  `json:"text"`
-	Box  boxInfo `json:"box"`
-}
+	dragParams := map[string]interface{}{
+		"context": context,
+		"actions": bidi.DragActions(from, to, int(steps)),
+	}
+	if p := session.BidiClient.Pacer(); p != nil {
+		dragParams["actions"] = p.DragActions(from, to)
+	}
 
-type boxInfo struct {
-	X      float64 `json:"x"`
-	Y      float64 `json:"y"`
-	Width  float64 `json:"width"`
-	Height float64 `json:"height"`
+	if _, err := r.sendInternalCommand(session, "input.performActions", dragParams); err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+
+	r.sendSuccess(session, cmd.ID, map[string]interface{}{"dragged": true})
 }
 
-// getContext retrieves the first browsing context.
-func (r *Router) getContext(session *BrowserSession) (string, error) {
-	resp, err := r.sendInternalCommand(session, "browsingContext.getTree", map[string]interface{}{})
+// handleVibiumPress handles the vibium:press command: it presses a key chord
+// such as "Enter" or "Control+A" in the focused element.
+func (r *Router) handleVibiumPress(session *BrowserSession, cmd bidiCommand) {
+	keys, _ := cmd.Params["keys"].(string)
+	context, _ := cmd.Params["context"].(string)
+
+	actions, err := bidi.KeyChordActions(keys)
 	if err != nil {
-		return "", err
+		r.sendError(session, cmd.ID, err)
+		return
 	}
 
-	var result struct {
-		Result struct {
-			Contexts []struct {
-				Context string `json:"context"`
-			} `json:"contexts"`
-		} `json:"result"`
+	// Get context if not provided
+	if context == "" {
+		ctx, err := r.getContext(session)
+		if err != nil {
+			r.sendError(session, cmd.ID, err)
+			return
+		}
+		context = ctx
 	}
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return "", fmt.Errorf("failed to parse getTree response: %w", err)
+
+	pressParams := map[string]interface{}{
+		"context": context,
+		"actions": actions,
 	}
-	if len(result.Result.Contexts) == 0 {
-		return "", fmt.Errorf("no browsing contexts available")
+
+	if _, err := r.sendInternalCommand(session, "input.performActions", pressParams); err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
 	}
-	return result.Result.Contexts[0].Context, nil
+
+	r.sendSuccess(session, cmd.ID, map[string]interface{}{"pressed": true})
 }
 
-// waitForElement polls until an element is found or timeout.
-func (r *Router) waitForElement(session *BrowserSession, context, selector string, timeout time.Duration) (*elementInfo, error) {
-	deadline := time.Now().Add(timeout)
-	interval := 100 * time.Millisecond
+// handleVibiumScroll handles the vibium:scroll command: it turns the mouse
+// wheel by deltaX, deltaY over the element matching "selector", or over the
+// top-left corner of the viewport if no selector is given.
+func (r *Router) handleVibiumScroll(session *BrowserSession, cmd bidiCommand) {
+	selector, _ := cmd.Params["selector"].(string)
+	context, _ := cmd.Params["context"].(string)
+	deltaX, _ := cmd.Params["deltaX"].(float64)
+	deltaY, _ := cmd.Params["deltaY"].(float64)
+	timeoutMs, _ := cmd.Params["timeout"].(float64)
 
-	findScript := `
-		(selector) => {
-			const el = document.querySelector(selector);
-			if (!el) return null;
-			const rect = el.getBoundingClientRect();
-			return JSON.stringify({
-				tag: el.tagName.toLowerCase(),
-				text: (el.textContent || '').trim().substring(0, 100),
-				box: {
-					x: rect.x,
-					y: rect.y,
-					width: rect.width,
-					height: rect.height
-				}
-			});
-		}
-	`
+	timeout := defaultTimeout
+	if timeoutMs > 0 {
+		timeout = time.Duration(timeoutMs) * time.Millisecond
+	}
 
-	for {
-		params := map[string]interface{}{
-			"functionDeclaration": findScript,
-			"target":              map[string]interface{}{"context": context},
-			"arguments": []map[string]interface{}{
-				{"type": "string", "value": selector},
-			},
-			"awaitPromise":    false,
-			"resultOwnership": "root",
+	// Get context if not provided
+	if context == "" {
+		ctx, err := r.getContext(session)
+		if err != nil {
+			r.sendError(session, cmd.ID, err)
+			return
 		}
+		context = ctx
+	}
 
-		resp, err := r.sendInternalCommand(session, "script.callFunction", params)
-		if err == nil {
-			// The BiDi response structure is nested:
-			// { "result": { "realm": "...", "result": { "type": "string", "value": "..." } } }
-			var result struct {
-				Result struct {
-					Result struct {
-						Type  string `json:"type"`
-						Value string `json:"value,omitempty"`
-					} `json:"result"`
-				} `json:"result"`
-			}
-			if err := json.Unmarshal(resp, &result); err == nil {
-				if result.Result.Result.Type == "string" && result.Result.Result.Value != "" {
-					var info elementInfo
-					if err := json.Unmarshal([]byte(result.Result.Result.Value), &info); err == nil {
-						return &info, nil
-					}
-				}
-			}
+	var x, y float64
+	if selector != "" {
+		info, err := r.waitForElement(session, context, selector, timeout)
+		if err != nil {
+			r.sendError(session, cmd.ID, err)
+			return
 		}
+		x = info.Box.X + info.Box.Width/2
+		y = info.Box.Y + info.Box.Height/2
+	}
 
-		if time.Now().After(deadline) {
-			return nil, fmt.Errorf("timeout after %s waiting for '%s': element not found", timeout, selector)
-		}
+	scrollParams := map[string]interface{}{
+		"context": context,
+		"actions": bidi.WheelActions(x, y, int(deltaX), int(deltaY)),
+	}
 
-		time.Sleep(interval)
+	if _, err := r.sendInternalCommand(session, "input.performActions", scrollParams); err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
 	}
-}
 
-// sendSuccess sends a successful response to the client.
-func (r *Router) sendSuccess(session *BrowserSession, id int, result interface{}) {
-	resp := bidiResponse{ID: id, Type: "success", Result: result}
-	data, _ := json.Marshal(resp)
-	session.Client.Send(string(data))
+	r.sendSuccess(session, cmd.ID, map[string]interface{}{"scrolled": true})
 }
 
-// sendError sends an error response to the client.
-func (r *Router) sendError(session *BrowserSession, id int, err error) {
-	resp := bidiResponse{
+// handleVibiumType handles the vibium:type command with actionability checks.
+func (r *Router) handleVibiumType(session *BrowserSession, cmd bidiCommand) {
+	selector, _ := cmd.Params["selector"].(string)
+	context, _ := cmd.Params["context"].(string)
+	text, _ := cmd.Params["text"].(string)
+	timeoutMs, _ := cmd.Params["timeout"].(float64)
+
+	timeout := defaultTimeout
+	if timeoutMs > 0 {
+		timeout = time.Duration(timeoutMs) * time.Millisecond
+	}
+
+	// Get context if not provided
+	if context == "" {
+		ctx, err := r.getContext(session)
+		if err != nil {
+			r.sendError(session, cmd.ID, err)
+			return
+		}
+		context = ctx
+	}
+
+	// Wait for element and get its position
+	info, err := r.waitForElement(session, context, selector, timeout)
+	if err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+
+	// Click to focus first
+	x := info.Box.X + info.Box.Width/2
+	y := info.Box.Y + info.Box.Height/2
+
+	clickActions, err := r.clickActions(session, x, y, bidi.ClickOptions{})
+	if err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+
+	clickParams := map[string]interface{}{
+		"context": context,
+		"actions": clickActions,
+	}
+
+	if _, err := r.sendInternalCommand(session, "input.performActions", clickParams); err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+
+	typeParams := map[string]interface{}{
+		"context": context,
+		"actions": typeActions(session, text),
+	}
+
+	if _, err := r.sendInternalCommand(session, "input.performActions", typeParams); err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+
+	r.sendSuccess(session, cmd.ID, map[string]interface{}{"typed": true})
+}
+
+// typeActions builds the input sources that type text, paced if the session
+// was launched with input pacing.
+func typeActions(session *BrowserSession, text string) []map[string]interface{} {
+	if p := session.BidiClient.Pacer(); p != nil {
+		return p.TypeActions(text)
+	}
+
+	keyActions := make([]map[string]interface{}, 0, len(text)*2)
+	for _, char := range text {
+		keyActions = append(keyActions,
+			map[string]interface{}{"type": "keyDown", "value": string(char)},
+			map[string]interface{}{"type": "keyUp", "value": string(char)},
+		)
+	}
+
+	return []map[string]interface{}{
+		{
+			"type":    "key",
+			"id":      "keyboard",
+			"actions": keyActions,
+		},
+	}
+}
+
+// handleVibiumFill handles the vibium:fill command: it clicks the field,
+// clears it, types "value" and dispatches change.
+func (r *Router) handleVibiumFill(session *BrowserSession, cmd bidiCommand) {
+	selector, _ := cmd.Params["selector"].(string)
+	context, _ := cmd.Params["context"].(string)
+	value, _ := cmd.Params["value"].(string)
+	timeoutMs, _ := cmd.Params["timeout"].(float64)
+
+	timeout := defaultTimeout
+	if timeoutMs > 0 {
+		timeout = time.Duration(timeoutMs) * time.Millisecond
+	}
+
+	// Get context if not provided
+	if context == "" {
+		ctx, err := r.getContext(session)
+		if err != nil {
+			r.sendError(session, cmd.ID, err)
+			return
+		}
+		context = ctx
+	}
+
+	info, err := r.waitForElement(session, context, selector, timeout)
+	if err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+
+	// Click to focus first
+	clickActions, err := r.clickActions(session, info.Box.X+info.Box.Width/2, info.Box.Y+info.Box.Height/2, bidi.ClickOptions{})
+	if err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+	clickParams := map[string]interface{}{
+		"context": context,
+		"actions": clickActions,
+	}
+	if _, err := r.sendInternalCommand(session, "input.performActions", clickParams); err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+
+	if _, err := r.callFormScript(session, bidi.ClearParams(context, selector, value == ""), context, selector); err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+
+	if value != "" {
+		typeParams := map[string]interface{}{
+			"context": context,
+			"actions": typeActions(session, value),
+		}
+		if _, err := r.sendInternalCommand(session, "input.performActions", typeParams); err != nil {
+			r.sendError(session, cmd.ID, err)
+			return
+		}
+		if _, err := r.callFormScript(session, bidi.ChangeEventParams(context, selector), context, selector); err != nil {
+			r.sendError(session, cmd.ID, err)
+			return
+		}
+	}
+
+	r.sendSuccess(session, cmd.ID, map[string]interface{}{"filled": true})
+}
+
+// handleVibiumClear handles the vibium:clear command: it empties a text
+// field and dispatches input and change.
+func (r *Router) handleVibiumClear(session *BrowserSession, cmd bidiCommand) {
+	selector, _ := cmd.Params["selector"].(string)
+	context, _ := cmd.Params["context"].(string)
+	timeoutMs, _ := cmd.Params["timeout"].(float64)
+
+	timeout := defaultTimeout
+	if timeoutMs > 0 {
+		timeout = time.Duration(timeoutMs) * time.Millisecond
+	}
+
+	// Get context if not provided
+	if context == "" {
+		ctx, err := r.getContext(session)
+		if err != nil {
+			r.sendError(session, cmd.ID, err)
+			return
+		}
+		context = ctx
+	}
+
+	if _, err := r.waitForElement(session, context, selector, timeout); err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+
+	if _, err := r.callFormScript(session, bidi.ClearParams(context, selector, true), context, selector); err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+
+	r.sendSuccess(session, cmd.ID, map[string]interface{}{"cleared": true})
+}
+
+// handleVibiumSelect handles the vibium:select command: it selects the
+// options of a <select> matching "values", "labels" or "indexes".
+func (r *Router) handleVibiumSelect(session *BrowserSession, cmd bidiCommand) {
+	selector, _ := cmd.Params["selector"].(string)
+	context, _ := cmd.Params["context"].(string)
+	timeoutMs, _ := cmd.Params["timeout"].(float64)
+
+	var by bidi.SelectBy
+	if values, ok := cmd.Params["values"].([]interface{}); ok {
+		for _, v := range values {
+			if s, ok := v.(string); ok {
+				by.Values = append(by.Values, s)
+			}
+		}
+	}
+	if labels, ok := cmd.Params["labels"].([]interface{}); ok {
+		for _, l := range labels {
+			if s, ok := l.(string); ok {
+				by.Labels = append(by.Labels, s)
+			}
+		}
+	}
+	if indexes, ok := cmd.Params["indexes"].([]interface{}); ok {
+		for _, i := range indexes {
+			if n, ok := i.(float64); ok {
+				by.Indexes = append(by.Indexes, int(n))
+			}
+		}
+	}
+
+	timeout := defaultTimeout
+	if timeoutMs > 0 {
+		timeout = time.Duration(timeoutMs) * time.Millisecond
+	}
+
+	// Get context if not provided
+	if context == "" {
+		ctx, err := r.getContext(session)
+		if err != nil {
+			r.sendError(session, cmd.ID, err)
+			return
+		}
+		context = ctx
+	}
+
+	params, err := bidi.SelectOptionParams(context, selector, by)
+	if err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+
+	if _, err := r.waitForElement(session, context, selector, timeout); err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+
+	result, err := r.callFormScript(session, params, context, selector)
+	if err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+
+	r.sendSuccess(session, cmd.ID, map[string]interface{}{"selected": result.Selected})
+}
+
+// handleVibiumCheck handles the vibium:check command: it clicks a checkbox
+// or radio button if it is not already in the "checked" state (default true).
+func (r *Router) handleVibiumCheck(session *BrowserSession, cmd bidiCommand) {
+	selector, _ := cmd.Params["selector"].(string)
+	context, _ := cmd.Params["context"].(string)
+	timeoutMs, _ := cmd.Params["timeout"].(float64)
+
+	checked := true
+	if val, ok := cmd.Params["checked"].(bool); ok {
+		checked = val
+	}
+
+	timeout := defaultTimeout
+	if timeoutMs > 0 {
+		timeout = time.Duration(timeoutMs) * time.Millisecond
+	}
+
+	// Get context if not provided
+	if context == "" {
+		ctx, err := r.getContext(session)
+		if err != nil {
+			r.sendError(session, cmd.ID, err)
+			return
+		}
+		context = ctx
+	}
+
+	info, err := r.waitForElement(session, context, selector, timeout)
+	if err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+
+	state, err := r.callFormScript(session, bidi.CheckedStateParams(context, selector), context, selector)
+	if err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+
+	if state.Checked != checked {
+		if state.Kind == "radio" && !checked {
+			r.sendError(session, cmd.ID, fmt.Errorf("cannot uncheck radio button %s (check another option instead)", selector))
+			return
+		}
+
+		clickActions, err := r.clickActions(session, info.Box.X+info.Box.Width/2, info.Box.Y+info.Box.Height/2, bidi.ClickOptions{})
+		if err != nil {
+			r.sendError(session, cmd.ID, err)
+			return
+		}
+		clickParams := map[string]interface{}{
+			"context": context,
+			"actions": clickActions,
+		}
+		if _, err := r.sendInternalCommand(session, "input.performActions", clickParams); err != nil {
+			r.sendError(session, cmd.ID, err)
+			return
+		}
+
+		state, err = r.callFormScript(session, bidi.CheckedStateParams(context, selector), context, selector)
+		if err != nil {
+			r.sendError(session, cmd.ID, err)
+			return
+		}
+		if state.Checked != checked {
+			r.sendError(session, cmd.ID, fmt.Errorf("clicking %s did not change its checked state", selector))
+			return
+		}
+	}
+
+	r.sendSuccess(session, cmd.ID, map[string]interface{}{"checked": state.Checked})
+}
+
+// callFormScript runs a form script built by one of the bidi *Params
+// functions and returns its result.
+func (r *Router) callFormScript(session *BrowserSession, params map[string]interface{}, context, selector string) (*bidi.FormResult, error) {
+	resp, err := r.sendInternalCommand(session, "script.callFunction", params)
+	if err == nil {
+		err = internalResponseError(resp)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse script response: %w", err)
+	}
+	return bidi.ParseFormResult(result.Result, context, selector)
+}
+
+// handleVibiumFind handles the vibium:find command with wait-for-selector.
+func (r *Router) handleVibiumFind(session *BrowserSession, cmd bidiCommand) {
+	selector, _ := cmd.Params["selector"].(string)
+	context, _ := cmd.Params["context"].(string)
+	timeoutMs, _ := cmd.Params["timeout"].(float64)
+
+	timeout := defaultTimeout
+	if timeoutMs > 0 {
+		timeout = time.Duration(timeoutMs) * time.Millisecond
+	}
+
+	// Get context if not provided
+	if context == "" {
+		ctx, err := r.getContext(session)
+		if err != nil {
+			r.sendError(session, cmd.ID, err)
+			return
+		}
+		context = ctx
+	}
+
+	// Wait for element
+	info, err := r.waitForElement(session, context, selector, timeout)
+	if err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+
+	r.sendSuccess(session, cmd.ID, map[string]interface{}{
+		"tag":  info.Tag,
+		"text": info.Text,
+		"box": map[string]interface{}{
+			"x":      info.Box.X,
+			"y":      info.Box.Y,
+			"width":  info.Box.Width,
+			"height": info.Box.Height,
+		},
+	})
+}
+
+// handleVibiumTraverseHistory handles vibium:back and vibium:forward.
+func (r *Router) handleVibiumTraverseHistory(session *BrowserSession, cmd bidiCommand, delta int) {
+	context, _ := cmd.Params["context"].(string)
+
+	// Get context if not provided
+	if context == "" {
+		ctx, err := r.getContext(session)
+		if err != nil {
+			r.sendError(session, cmd.ID, err)
+			return
+		}
+		context = ctx
+	}
+
+	settle, err := r.subscribeHistoryEvents(session)
+	if err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+	// Events of earlier navigations must not end the wait
+	for drained := false; !drained; {
+		select {
+		case <-session.historyEvents:
+		default:
+			drained = true
+		}
+	}
+
+	resp, err := r.sendInternalCommand(session, "browsingContext.traverseHistory", map[string]interface{}{
+		"context": context,
+		"delta":   delta,
+	})
+	if err == nil {
+		err = internalResponseError(resp)
+	}
+	if err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+
+	// Wait for the page to settle, as Client.TraverseHistory does, so the
+	// next command sees the new page. Entries that send no event just
+	// end the wait at the timeout.
+	timeout := time.After(settle)
+	for settled := false; !settled; {
+		select {
+		case ctx := <-session.historyEvents:
+			settled = ctx == context
+		case <-timeout:
+			settled = true
+		case <-session.stopChan:
+			return
+		}
+	}
+
+	r.sendSuccess(session, cmd.ID, map[string]interface{}{"delta": delta})
+}
+
+// subscribeHistoryEvents subscribes the session to the events that tell a
+// history traversal settled, once, and returns how long to wait for them.
+func (r *Router) subscribeHistoryEvents(session *BrowserSession) (time.Duration, error) {
+	session.mu.Lock()
+	subscribed, noHistoryUpdated := session.historySubscribed, session.noHistoryUpdated
+	session.mu.Unlock()
+
+	if !subscribed {
+		resp, err := r.sendInternalCommand(session, "session.subscribe", map[string]interface{}{
+			"events": []string{bidi.EventLoad, bidi.EventFragmentNavigated},
+		})
+		if err == nil {
+			err = internalResponseError(resp)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to subscribe to navigation events: %w", err)
+		}
+
+		// Not every browser has historyUpdated yet
+		resp, err = r.sendInternalCommand(session, "session.subscribe", map[string]interface{}{
+			"events": []string{bidi.EventHistoryUpdated},
+		})
+		if err == nil {
+			err = internalResponseError(resp)
+		}
+		noHistoryUpdated = err != nil
+
+		session.mu.Lock()
+		session.historySubscribed = true
+		session.noHistoryUpdated = noHistoryUpdated
+		session.mu.Unlock()
+	}
+
+	if noHistoryUpdated {
+		return bidi.HistoryFallbackTimeout, nil
+	}
+	return bidi.HistorySettleTimeout, nil
+}
+
+// handleVibiumReload handles the vibium:reload command.
+func (r *Router) handleVibiumReload(session *BrowserSession, cmd bidiCommand) {
+	context, _ := cmd.Params["context"].(string)
+	ignoreCache, _ := cmd.Params["ignoreCache"].(bool)
+
+	// Get context if not provided
+	if context == "" {
+		ctx, err := r.getContext(session)
+		if err != nil {
+			r.sendError(session, cmd.ID, err)
+			return
+		}
+		context = ctx
+	}
+
+	params := map[string]interface{}{
+		"context": context,
+		"wait":    "complete",
+	}
+	if ignoreCache {
+		params["ignoreCache"] = true
+	}
+
+	resp, err := r.sendInternalCommand(session, "browsingContext.reload", params)
+	if err == nil {
+		err = internalResponseError(resp)
+	}
+	if err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+
+	var result struct {
+		Result struct {
+			Navigation string `json:"navigation"`
+			URL        string `json:"url"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		r.sendError(session, cmd.ID, fmt.Errorf("failed to parse reload response: %w", err))
+		return
+	}
+
+	r.sendSuccess(session, cmd.ID, map[string]interface{}{
+		"navigation": result.Result.Navigation,
+		"url":        result.Result.URL,
+	})
+}
+
+// handleVibiumStop handles the vibium:stop command by calling window.stop().
+func (r *Router) handleVibiumStop(session *BrowserSession, cmd bidiCommand) {
+	context, _ := cmd.Params["context"].(string)
+
+	// Get context if not provided
+	if context == "" {
+		ctx, err := r.getContext(session)
+		if err != nil {
+			r.sendError(session, cmd.ID, err)
+			return
+		}
+		context = ctx
+	}
+
+	resp, err := r.sendInternalCommand(session, "script.evaluate", map[string]interface{}{
+		"expression":   "window.stop()",
+		"target":       map[string]interface{}{"context": context},
+		"awaitPromise": false,
+	})
+	if err == nil {
+		err = internalResponseError(resp)
+	}
+	if err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+
+	r.sendSuccess(session, cmd.ID, map[string]interface{}{"stopped": true})
+}
+
+// handleVibiumSetViewport handles the vibium:setViewport command.
+// Accepts width/height/devicePixelRatio or a device profile name. A device
+// also sets the profile's user agent and touch support, as --device does at
+// launch; width, height and devicePixelRatio still take precedence.
+func (r *Router) handleVibiumSetViewport(session *BrowserSession, cmd bidiCommand) {
+	context, _ := cmd.Params["context"].(string)
+	width, _ := cmd.Params["width"].(float64)
+	height, _ := cmd.Params["height"].(float64)
+	dpr, _ := cmd.Params["devicePixelRatio"].(float64)
+
+	var profile *browser.LaunchOptions
+	if name, ok := cmd.Params["device"].(string); ok && name != "" {
+		d, err := devices.Lookup(name)
+		if err != nil {
+			r.sendError(session, cmd.ID, err)
+			return
+		}
+		profile = &browser.LaunchOptions{}
+		profile.ApplyDevice(d)
+		if width == 0 || height == 0 {
+			width, height = float64(profile.Viewport.Width), float64(profile.Viewport.Height)
+		}
+		if dpr == 0 {
+			dpr = profile.Viewport.DevicePixelRatio
+		}
+	}
+
+	// Get context if not provided
+	if context == "" {
+		ctx, err := r.getContext(session)
+		if err != nil {
+			r.sendError(session, cmd.ID, err)
+			return
+		}
+		context = ctx
+	}
+
+	params := map[string]interface{}{
+		"context":  context,
+		"viewport": nil,
+	}
+	if width > 0 && height > 0 {
+		params["viewport"] = map[string]interface{}{
+			"width":  int(width),
+			"height": int(height),
+		}
+	}
+	if dpr > 0 {
+		params["devicePixelRatio"] = dpr
+	}
+
+	resp, err := r.sendInternalCommand(session, "browsingContext.setViewport", params)
+	if err == nil {
+		err = internalResponseError(resp)
+	}
+	if err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+
+	result := map[string]interface{}{
+		"width":            int(width),
+		"height":           int(height),
+		"devicePixelRatio": dpr,
+	}
+	if profile != nil {
+		// A profile without a user agent or touch clears what an earlier one set
+		var userAgent, touchPoints interface{}
+		if profile.UserAgent != "" {
+			userAgent = profile.UserAgent
+		}
+		if profile.Touch {
+			touchPoints = devices.TouchPoints
+		}
+
+		resp, err := r.sendInternalCommand(session, "emulation.setUserAgentOverride", map[string]interface{}{
+			"contexts":  []string{context},
+			"userAgent": userAgent,
+		})
+		if err == nil {
+			err = internalResponseError(resp)
+		}
+		if err != nil {
+			r.sendError(session, cmd.ID, fmt.Errorf("emulation.setUserAgentOverride failed: %w", err))
+			return
+		}
+
+		// Browsers without touch emulation still get the rest of the profile
+		resp, err = r.sendInternalCommand(session, "emulation.setTouchOverride", map[string]interface{}{
+			"contexts":       []string{context},
+			"maxTouchPoints": touchPoints,
+		})
+		if err == nil {
+			err = internalResponseError(resp)
+		}
+		if err != nil {
+			fmt.Printf("[router] Touch emulation not applied for client %d: %v\n", session.Client.ID, err)
+		}
+
+		result["userAgent"] = profile.UserAgent
+		result["touch"] = profile.Touch && err == nil
+	}
+
+	r.sendSuccess(session, cmd.ID, result)
+}
+
+// handleVibiumEmulate handles the vibium:emulate command.
+// Each of geolocation, locale, timezone and userAgent is optional; passing
+// null or "" clears that override. If origin is given along with a
+// geolocation, the geolocation permission is granted to that origin.
+func (r *Router) handleVibiumEmulate(session *BrowserSession, cmd bidiCommand) {
+	context, _ := cmd.Params["context"].(string)
+
+	// Get context if not provided
+	if context == "" {
+		ctx, err := r.getContext(session)
+		if err != nil {
+			r.sendError(session, cmd.ID, err)
+			return
+		}
+		context = ctx
+	}
+
+	type override struct {
+		method string
+		params map[string]interface{}
+	}
+	var overrides []override
+
+	if geoVal, ok := cmd.Params["geolocation"]; ok {
+		var coords interface{}
+		if geo, ok := geoVal.(map[string]interface{}); ok {
+			lat, latOK := geo["latitude"].(float64)
+			lon, lonOK := geo["longitude"].(float64)
+			if !latOK || !lonOK {
+				r.sendError(session, cmd.ID, errs.InvalidArgument("geolocation requires latitude and longitude"))
+				return
+			}
+			accuracy, _ := geo["accuracy"].(float64)
+			if err := (&devices.Geolocation{Latitude: lat, Longitude: lon, Accuracy: accuracy}).Validate(); err != nil {
+				r.sendError(session, cmd.ID, err)
+				return
+			}
+			c := map[string]interface{}{"latitude": lat, "longitude": lon}
+			if accuracy > 0 {
+				c["accuracy"] = accuracy
+			}
+			coords = c
+		}
+		overrides = append(overrides, override{"emulation.setGeolocationOverride", map[string]interface{}{
+			"contexts":    []string{context},
+			"coordinates": coords,
+		}})
+
+		if origin, ok := cmd.Params["origin"].(string); ok && origin != "" && coords != nil {
+			overrides = append(overrides, override{"permissions.setPermission", map[string]interface{}{
+				"descriptor": map[string]interface{}{"name": "geolocation"},
+				"state":      "granted",
+				"origin":     origin,
+			}})
+		}
+	}
+
+	for _, o := range []struct{ param, method string }{
+		{"locale", "emulation.setLocaleOverride"},
+		{"timezone", "emulation.setTimezoneOverride"},
+		{"userAgent", "emulation.setUserAgentOverride"},
+	} {
+		val, ok := cmd.Params[o.param]
+		if !ok {
+			continue
+		}
+		var value interface{}
+		if str, ok := val.(string); ok && str != "" {
+			value = str
+		}
+		overrides = append(overrides, override{o.method, map[string]interface{}{
+			"contexts": []string{context},
+			o.param:    value,
+		}})
+	}
+
+	for _, o := range overrides {
+		resp, err := r.sendInternalCommand(session, o.method, o.params)
+		if err == nil {
+			err = internalResponseError(resp)
+		}
+		if err != nil {
+			r.sendError(session, cmd.ID, fmt.Errorf("%s failed: %w", o.method, err))
+			return
+		}
+	}
+
+	r.sendSuccess(session, cmd.ID, map[string]interface{}{"applied": len(overrides)})
+}
+
+// handleVibiumScreenshot handles the vibium:screenshot command.
+// Supports fullPage, clipping to an element (selector or sharedId) or a
+// rectangle (clip), and format/quality for JPEG and WebP output.
+func (r *Router) handleVibiumScreenshot(session *BrowserSession, cmd bidiCommand) {
+	context, _ := cmd.Params["context"].(string)
+	fullPage, _ := cmd.Params["fullPage"].(bool)
+	selector, _ := cmd.Params["selector"].(string)
+	sharedID, _ := cmd.Params["sharedId"].(string)
+	format, _ := cmd.Params["format"].(string)
+	quality, _ := cmd.Params["quality"].(float64)
+
+	mimeType, err := bidi.ScreenshotMimeType(format)
+	if err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+
+	// Get context if not provided
+	if context == "" {
+		ctx, err := r.getContext(session)
+		if err != nil {
+			r.sendError(session, cmd.ID, err)
+			return
+		}
+		context = ctx
+	}
+
+	if selector != "" && sharedID == "" {
+		id, err := r.getElementSharedID(session, context, selector)
+		if err != nil {
+			r.sendError(session, cmd.ID, err)
+			return
+		}
+		sharedID = id
+	}
+
+	params := map[string]interface{}{
+		"context": context,
+	}
+	if fullPage || sharedID != "" {
+		params["origin"] = "document"
+	}
+
+	if sharedID != "" {
+		params["clip"] = map[string]interface{}{
+			"type":    "element",
+			"element": map[string]interface{}{"sharedId": sharedID},
+		}
+	} else if clip, ok := cmd.Params["clip"].(map[string]interface{}); ok {
+		x, _ := clip["x"].(float64)
+		y, _ := clip["y"].(float64)
+		width, _ := clip["width"].(float64)
+		height, _ := clip["height"].(float64)
+		params["clip"] = map[string]interface{}{
+			"type":   "box",
+			"x":      x,
+			"y":      y,
+			"width":  width,
+			"height": height,
+		}
+	}
+
+	if mimeType != "image/png" || quality > 0 {
+		f := map[string]interface{}{"type": mimeType}
+		if quality > 0 {
+			f["quality"] = quality / 100
+		}
+		params["format"] = f
+	}
+
+	resp, err := r.sendInternalCommand(session, "browsingContext.captureScreenshot", params)
+	if err == nil {
+		err = internalResponseError(resp)
+	}
+	if err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+
+	var result struct {
+		Result struct {
+			Data string `json:"data"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		r.sendError(session, cmd.ID, fmt.Errorf("failed to parse screenshot response: %w", err))
+		return
+	}
+
+	r.sendSuccess(session, cmd.ID, map[string]interface{}{
+		"data":     result.Result.Data,
+		"mimeType": mimeType,
+	})
+}
+
+// elementInfo holds parsed element information.
+type elementInfo struct {
+	Tag  string  `json:"tag"`
+	Text string  `json:"text"`
+	Box  boxInfo `json:"box"`
+}
+
+type boxInfo struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// getContext retrieves the first browsing context.
+func (r *Router) getContext(session *BrowserSession) (string, error) {
+	resp, err := r.sendInternalCommand(session, "browsingContext.getTree", map[string]interface{}{})
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Result struct {
+			Contexts []struct {
+				Context string `json:"context"`
+			} `json:"contexts"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return "", fmt.Errorf("failed to parse getTree response: %w", err)
+	}
+	if len(result.Result.Contexts) == 0 {
+		return "", fmt.Errorf("no browsing contexts available")
+	}
+	return result.Result.Contexts[0].Context, nil
+}
+
+// handleVibiumSetFiles handles the vibium:setFiles command.
+// Waits for the file input, then sets its files with input.setFiles.
+func (r *Router) handleVibiumSetFiles(session *BrowserSession, cmd bidiCommand) {
+	selector, _ := cmd.Params["selector"].(string)
+	context, _ := cmd.Params["context"].(string)
+	rawFiles, _ := cmd.Params["files"].([]interface{})
+	timeoutMs, _ := cmd.Params["timeout"].(float64)
+
+	timeout := defaultTimeout
+	if timeoutMs > 0 {
+		timeout = time.Duration(timeoutMs) * time.Millisecond
+	}
+
+	// input.setFiles needs absolute paths that exist on this machine
+	files := make([]string, 0, len(rawFiles))
+	for _, raw := range rawFiles {
+		p, _ := raw.(string)
+		if p == "" {
+			r.sendError(session, cmd.ID, errs.InvalidArgument("files must be non-empty strings"))
+			return
+		}
+		abs, err := filepath.Abs(p)
+		if err == nil {
+			_, err = os.Stat(abs)
+		}
+		if err != nil {
+			r.sendError(session, cmd.ID, fmt.Errorf("cannot upload %q: %w", p, err))
+			return
+		}
+		files = append(files, abs)
+	}
+
+	// Get context if not provided
+	if context == "" {
+		ctx, err := r.getContext(session)
+		if err != nil {
+			r.sendError(session, cmd.ID, err)
+			return
+		}
+		context = ctx
+	}
+
+	// Wait for element to exist (file inputs are often hidden, so no box check)
+	info, err := r.waitForElement(session, context, selector, timeout)
+	if err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+	if info.Tag != "input" {
+		r.sendError(session, cmd.ID, fmt.Errorf("element %s is not an <input type=\"file\">", selector))
+		return
+	}
+
+	sharedID, err := r.getElementSharedID(session, context, selector)
+	if err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+
+	resp, err := r.sendInternalCommand(session, "input.setFiles", map[string]interface{}{
+		"context": context,
+		"element": map[string]interface{}{"sharedId": sharedID},
+		"files":   files,
+	})
+	if err == nil {
+		err = internalResponseError(resp)
+	}
+	if err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+
+	r.sendSuccess(session, cmd.ID, map[string]interface{}{"files": files})
+}
+
+// handleVibiumWaitForDownload handles the vibium:waitForDownload command.
+// Waits for the next download (optionally from one context) to finish and
+// replies with its url, suggestedFilename, path and size.
+func (r *Router) handleVibiumWaitForDownload(session *BrowserSession, cmd bidiCommand) {
+	context, _ := cmd.Params["context"].(string)
+	timeoutMs, _ := cmd.Params["timeout"].(float64)
+
+	timeout := defaultTimeout
+	if timeoutMs > 0 {
+		timeout = time.Duration(timeoutMs) * time.Millisecond
+	}
+
+	dir := r.launchOpts.DownloadDir
+	if dir != "" {
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+	}
+
+	// suggestedFilename of started downloads by navigation id
+	started := make(map[string]string)
+	deadline := time.After(timeout)
+
+	for {
+		select {
+		case ev := <-session.downloadEvents:
+			switch ev.Method {
+			case bidi.EventDownloadWillBegin:
+				var begin bidi.DownloadWillBeginParams
+				if err := json.Unmarshal(ev.Params, &begin); err == nil && (context == "" || begin.Context == context) {
+					started[begin.Navigation] = begin.SuggestedFilename
+				}
+			case bidi.EventDownloadEnd:
+				var end bidi.DownloadEndParams
+				if err := json.Unmarshal(ev.Params, &end); err != nil {
+					continue
+				}
+				name, ok := started[end.Navigation]
+				if !ok {
+					continue
+				}
+				download, err := bidi.CompleteDownload(&end, name, dir)
+				if err != nil {
+					r.sendError(session, cmd.ID, err)
+					return
+				}
+				r.sendSuccess(session, cmd.ID, download)
+				return
+			}
+		case <-deadline:
+			r.sendError(session, cmd.ID, &errs.TimeoutError{Selector: "download", Timeout: timeout, Reason: "no download finished"})
+			return
+		case <-session.stopChan:
+			return
+		}
+	}
+}
+
+// handleVibiumHandleDialog handles the vibium:handleDialog command.
+// Accepts or dismisses the open dialog; text fills prompt() dialogs.
+func (r *Router) handleVibiumHandleDialog(session *BrowserSession, cmd bidiCommand) {
+	context, _ := cmd.Params["context"].(string)
+	accept, _ := cmd.Params["accept"].(bool)
+	text, _ := cmd.Params["text"].(string)
+
+	if context == "" {
+		session.mu.Lock()
+		context = session.openDialogContext
+		session.mu.Unlock()
+	}
+	if context == "" {
+		ctx, err := r.getContext(session)
+		if err != nil {
+			r.sendError(session, cmd.ID, err)
+			return
+		}
+		context = ctx
+	}
+
+	if err := r.handleUserPrompt(session, context, accept, text); err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+
+	r.sendSuccess(session, cmd.ID, map[string]interface{}{"accepted": accept})
+}
+
+// handleVibiumSetDialogPolicy handles the vibium:setDialogPolicy command.
+// policy is "accept", "dismiss" or "fail" and applies to dialogs opened later.
+func (r *Router) handleVibiumSetDialogPolicy(session *BrowserSession, cmd bidiCommand) {
+	policy, _ := cmd.Params["policy"].(string)
+
+	parsed, err := bidi.ParseDialogPolicy(policy)
+	if err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+
+	session.mu.Lock()
+	session.dialogPolicy = parsed
+	session.mu.Unlock()
+
+	r.sendSuccess(session, cmd.ID, map[string]interface{}{"policy": parsed})
+}
+
+// handleVibiumPDF handles the vibium:pdf command.
+// Accepts paper or width/height (cm), margin {top,right,bottom,left} (cm),
+// landscape, scale, background and pageRanges. Replies with base64 data.
+func (r *Router) handleVibiumPDF(session *BrowserSession, cmd bidiCommand) {
+	context, _ := cmd.Params["context"].(string)
+
+	opts := bidi.PDFOptions{}
+	opts.PageWidth, _ = cmd.Params["width"].(float64)
+	opts.PageHeight, _ = cmd.Params["height"].(float64)
+	opts.Landscape, _ = cmd.Params["landscape"].(bool)
+	opts.Scale, _ = cmd.Params["scale"].(float64)
+	opts.Background, _ = cmd.Params["background"].(bool)
+	opts.PageRanges, _ = cmd.Params["pageRanges"].(string)
+
+	if paper, ok := cmd.Params["paper"].(string); ok && paper != "" {
+		w, h, err := bidi.PaperSize(paper)
+		if err != nil {
+			r.sendError(session, cmd.ID, err)
+			return
+		}
+		opts.PageWidth, opts.PageHeight = w, h
+	}
+
+	if margin, ok := cmd.Params["margin"].(map[string]interface{}); ok {
+		m := &bidi.PDFMargin{}
+		m.Top, _ = margin["top"].(float64)
+		m.Right, _ = margin["right"].(float64)
+		m.Bottom, _ = margin["bottom"].(float64)
+		m.Left, _ = margin["left"].(float64)
+		opts.Margin = m
+	}
+
+	// Get context if not provided
+	if context == "" {
+		ctx, err := r.getContext(session)
+		if err != nil {
+			r.sendError(session, cmd.ID, err)
+			return
+		}
+		context = ctx
+	}
+
+	params, err := bidi.PrintParams(context, opts)
+	if err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+
+	resp, err := r.sendInternalCommand(session, "browsingContext.print", params)
+	if err == nil {
+		err = internalResponseError(resp)
+	}
+	if err != nil {
+		r.sendError(session, cmd.ID, err)
+		return
+	}
+
+	var result struct {
+		Result bidi.PrintResult `json:"result"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		r.sendError(session, cmd.ID, fmt.Errorf("failed to parse print response: %w", err))
+		return
+	}
+
+	r.sendSuccess(session, cmd.ID, map[string]interface{}{
+		"data": result.Result.Data,
+	})
+}
+
+// getElementSharedID returns the BiDi sharedId of the first element matching selector.
+func (r *Router) getElementSharedID(session *BrowserSession, context, selector string) (string, error) {
+	params := map[string]interface{}{
+		"functionDeclaration": `(selector) => document.querySelector(selector)`,
+		"target":              map[string]interface{}{"context": context},
+		"arguments": []map[string]interface{}{
+			{"type": "string", "value": selector},
+		},
+		"awaitPromise":    false,
+		"resultOwnership": "root",
+	}
+
+	resp, err := r.sendInternalCommand(session, "script.callFunction", params)
+	if err == nil {
+		err = internalResponseError(resp)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Result struct {
+			Result struct {
+				Type     string `json:"type"`
+				SharedID string `json:"sharedId"`
+			} `json:"result"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return "", fmt.Errorf("failed to parse element reference: %w", err)
+	}
+	if result.Result.Result.Type != "node" || result.Result.Result.SharedID == "" {
+		return "", &errs.ElementNotFoundError{Selector: selector, Context: context}
+	}
+
+	return result.Result.Result.SharedID, nil
+}
+
+// waitForElement polls until an element is found or timeout.
+func (r *Router) waitForElement(session *BrowserSession, context, selector string, timeout time.Duration) (*elementInfo, error) {
+	deadline := time.Now().Add(timeout)
+	interval := 100 * time.Millisecond
+
+	findScript := `
+		(selector) => {
+			const el = document.querySelector(selector);
+			if (!el) return null;
+			const rect = el.getBoundingClientRect();
+			return JSON.stringify({
+				tag: el.tagName.toLowerCase(),
+				text: (el.textContent || '').trim().substring(0, 100),
+				box: {
+					x: rect.x,
+					y: rect.y,
+					width: rect.width,
+					height: rect.height
+				}
+			});
+		}
+	`
+
+	for {
+		params := map[string]interface{}{
+			"functionDeclaration": findScript,
+			"target":              map[string]interface{}{"context": context},
+			"arguments": []map[string]interface{}{
+				{"type": "string", "value": selector},
+			},
+			"awaitPromise":    false,
+			"resultOwnership": "root",
+		}
+
+		resp, err := r.sendInternalCommand(session, "script.callFunction", params)
+		if err == nil {
+			// The BiDi response structure is nested:
+			// { "result": { "realm": "...", "result": { "type": "string", "value": "..." } } }
+			var result struct {
+				Result struct {
+					Result struct {
+						Type  string `json:"type"`
+						Value string `json:"value,omitempty"`
+					} `json:"result"`
+				} `json:"result"`
+			}
+			if err := json.Unmarshal(resp, &result); err == nil {
+				if result.Result.Result.Type == "string" && result.Result.Result.Value != "" {
+					var info elementInfo
+					if err := json.Unmarshal([]byte(result.Result.Result.Value), &info); err == nil {
+						return &info, nil
+					}
+				}
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return nil, &errs.TimeoutError{
+				Selector: selector,
+				Timeout:  timeout,
+				Reason:   "element not found",
+				Cause:    &errs.ElementNotFoundError{Selector: selector, Context: context},
+			}
+		}
+
+		time.Sleep(interval)
+	}
+}
+
+// internalResponseError returns the error carried by a BiDi error response, if any.
+func internalResponseError(resp json.RawMessage) error {
+	var result struct {
+		Type    string `json:"type"`
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	if result.Type == "error" {
+		return &errs.ProtocolError{BiDiCode: result.Error, Message: result.Message}
+	}
+	return nil
+}
+
+// connectError builds the message sent to a client whose browser could not
+// be set up. Its error field is the code of err, as in sendError.
+func connectError(message string, err error) string {
+	data, _ := json.Marshal(map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    -32000,
+			"error":   string(errs.CodeOf(err)),
+			"message": message + ": " + err.Error(),
+		},
+	})
+	return string(data)
+}
+
+// closingError builds the last message sent to a client before the proxy
+// closes its connection because of err: an error response with a null id,
+// as BiDi sends for errors that belong to no command.
+func closingError(err error) string {
+	data, _ := json.Marshal(map[string]interface{}{
+		"id":   nil,
+		"type": "error",
+		"error": &bidiError{
+			Error:   string(errs.CodeOf(err)),
+			Message: err.Error(),
+		},
+	})
+	return string(data)
+}
+
+// sendSuccess sends a successful response to the client.
+func (r *Router) sendSuccess(session *BrowserSession, id int, result interface{}) {
+	resp := bidiResponse{ID: id, Type: "success", Result: result}
+	data, _ := json.Marshal(resp)
+	session.Client.Send(string(data))
+}
+
+// sendError sends an error response to the client. Its error field is the
+// code of err (see errs.CodeOf), e.g. "element_not_found".
+func (r *Router) sendError(session *BrowserSession, id int, err error) {
+	resp := bidiResponse{
 		ID:   id,
 		Type: "error",
 		Error: &bidiError{
-			Error:   "timeout",
+			Error:   string(errs.CodeOf(err)),
 			Message: err.Error(),
 		},
 	}
@@ -501,7 +1911,12 @@ func (r *Router) routeBrowserToClient(session *BrowserSession) {
 
 			if !closed {
 				fmt.Printf("[router] Browser connection closed for client %d: %v\n", session.Client.ID, err)
-				// Browser died, close the client
+				// Browser died: tell the client why, then close it
+				var reason error = &errs.ConnectionError{URL: session.LaunchResult.WebSocketURL, Cause: err}
+				if crash := session.LaunchResult.CrashError(); crash != nil {
+					reason = crash
+				}
+				session.Client.Send(closingError(reason))
 				session.Client.Close()
 			}
 			return
@@ -523,6 +1938,9 @@ func (r *Router) routeBrowserToClient(session *BrowserSession) {
 			}
 		}
 
+		// Track download, history and dialog events (they are still forwarded)
+		r.observeBrowserEvent(session, msg)
+
 		// Forward message to client
 		if err := session.Client.Send(msg); err != nil {
 			fmt.Printf("[router] Failed to send to client %d: %v\n", session.Client.ID, err)
@@ -531,6 +1949,102 @@ func (r *Router) routeBrowserToClient(session *BrowserSession) {
 	}
 }
 
+// observeBrowserEvent queues download events for vibium:waitForDownload and
+// history events for vibium:back and vibium:forward, and applies the
+// session's dialog policy to dialogs.
+func (r *Router) observeBrowserEvent(session *BrowserSession, msg string) {
+	var ev bidi.Event
+	if err := json.Unmarshal([]byte(msg), &ev); err != nil {
+		return
+	}
+
+	switch ev.Method {
+	case bidi.EventDownloadWillBegin, bidi.EventDownloadEnd:
+		r.queueDownloadEvent(session, &ev)
+
+	case bidi.EventLoad, bidi.EventFragmentNavigated, bidi.EventHistoryUpdated:
+		var p struct {
+			Context string `json:"context"`
+		}
+		if json.Unmarshal(ev.Params, &p) == nil {
+			r.queueHistoryEvent(session, p.Context)
+		}
+
+	case bidi.EventUserPromptOpened:
+		var p bidi.UserPromptOpenedParams
+		if err := json.Unmarshal(ev.Params, &p); err != nil || (p.Handler != "" && p.Handler != "ignore") {
+			return
+		}
+		session.mu.Lock()
+		session.openDialogContext = p.Context
+		policy := session.dialogPolicy
+		session.mu.Unlock()
+
+		// Under the fail policy the dialog stays open for vibium:handleDialog.
+		// Handle it in the background: the response arrives through this loop.
+		if policy == bidi.DialogAccept || policy == bidi.DialogDismiss {
+			go r.handleUserPrompt(session, p.Context, policy == bidi.DialogAccept, "")
+		}
+
+	case bidi.EventUserPromptClosed:
+		session.mu.Lock()
+		session.openDialogContext = ""
+		session.mu.Unlock()
+	}
+}
+
+// handleUserPrompt accepts or dismisses the dialog in context.
+func (r *Router) handleUserPrompt(session *BrowserSession, context string, accept bool, text string) error {
+	params := map[string]interface{}{
+		"context": context,
+		"accept":  accept,
+	}
+	if text != "" {
+		params["userText"] = text
+	}
+
+	resp, err := r.sendInternalCommand(session, "browsingContext.handleUserPrompt", params)
+	if err == nil {
+		err = internalResponseError(resp)
+	}
+	if err != nil {
+		fmt.Printf("[router] Failed to handle dialog for client %d: %v\n", session.Client.ID, err)
+	}
+	return err
+}
+
+// queueDownloadEvent queues ev for vibium:waitForDownload.
+// The oldest events are dropped when nobody waits for them.
+func (r *Router) queueDownloadEvent(session *BrowserSession, ev *bidi.Event) {
+	for {
+		select {
+		case session.downloadEvents <- ev:
+			return
+		default:
+		}
+		select {
+		case <-session.downloadEvents:
+		default:
+		}
+	}
+}
+
+// queueHistoryEvent queues the context of a history event for
+// vibium:back and vibium:forward, dropping the oldest if the queue is full.
+func (r *Router) queueHistoryEvent(session *BrowserSession, context string) {
+	for {
+		select {
+		case session.historyEvents <- context:
+			return
+		default:
+		}
+		select {
+		case <-session.historyEvents:
+		default:
+		}
+	}
+}
+
 // sendInternalCommand sends a BiDi command and waits for the response.
 func (r *Router) sendInternalCommand(session *BrowserSession, method string, params map[string]interface{}) (json.RawMessage, error) {
 	session.internalCmdsMu.Lock()
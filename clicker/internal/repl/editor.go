@@ -0,0 +1,372 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// ErrInterrupted is returned by ReadLine when the user presses Ctrl+C.
+var ErrInterrupted = errors.New("interrupted")
+
+// maxHistory caps the number of lines kept in the history file.
+const maxHistory = 1000
+
+// Editor reads lines from a terminal with basic line editing: cursor
+// movement, history (Up/Down) and completion (Tab). When input is not a
+// terminal it reads plain lines without a prompt.
+type Editor struct {
+	in  *os.File
+	r   *bufio.Reader
+	out io.Writer
+	tty bool
+
+	prompt      string
+	complete    func(head string) []string
+	history     []string
+	historyFile string
+
+	// mu guards output and the line being edited, so PrintAbove can print
+	// while ReadLine waits for keys.
+	mu      sync.Mutex
+	reading bool
+	buf     []rune
+	pos     int
+}
+
+// NewEditor creates an editor reading from in and writing to out. History
+// is loaded from and appended to historyFile, if not empty. complete, if not
+// nil, returns the completions of the text before the cursor.
+func NewEditor(in *os.File, out io.Writer, prompt, historyFile string, complete func(head string) []string) *Editor {
+	e := &Editor{
+		in:          in,
+		r:           bufio.NewReader(in),
+		out:         out,
+		tty:         isTerminal(in.Fd()),
+		prompt:      prompt,
+		complete:    complete,
+		historyFile: historyFile,
+	}
+	e.loadHistory()
+	return e
+}
+
+// Interactive reports whether the editor reads from a terminal.
+func (e *Editor) Interactive() bool {
+	return e.tty
+}
+
+// loadHistory reads the history file, trimming it if it grew too long.
+func (e *Editor) loadHistory() {
+	if e.historyFile == "" {
+		return
+	}
+	data, err := os.ReadFile(e.historyFile)
+	if err != nil {
+		return
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			e.history = append(e.history, line)
+		}
+	}
+	if len(e.history) > maxHistory {
+		e.history = e.history[len(e.history)-maxHistory:]
+		os.WriteFile(e.historyFile, []byte(strings.Join(e.history, "\n")+"\n"), 0600)
+	}
+}
+
+// AddHistory records a line for Up/Down and appends it to the history file.
+// Empty lines and repeats of the previous line are skipped.
+func (e *Editor) AddHistory(line string) {
+	if strings.TrimSpace(line) == "" || strings.Contains(line, "\n") {
+		return
+	}
+	if n := len(e.history); n > 0 && e.history[n-1] == line {
+		return
+	}
+	e.history = append(e.history, line)
+
+	if e.historyFile == "" {
+		return
+	}
+	f, err := os.OpenFile(e.historyFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintln(f, line)
+}
+
+// PrintAbove prints text without disturbing a line being edited: the line is
+// cleared, text printed, and the prompt and line redrawn below it.
+func (e *Editor) PrintAbove(text string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	if !e.reading || !e.tty {
+		io.WriteString(e.out, text)
+		return
+	}
+	io.WriteString(e.out, "\r\x1b[K"+text)
+	e.redraw()
+}
+
+// ReadLine reads a line. It returns io.EOF at the end of input (Ctrl+D on an
+// empty line) and ErrInterrupted on Ctrl+C.
+func (e *Editor) ReadLine() (string, error) {
+	if !e.tty {
+		line, err := e.r.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	restore, err := makeRaw(e.in.Fd())
+	if err != nil {
+		e.tty = false
+		return e.ReadLine()
+	}
+	defer restore()
+
+	e.mu.Lock()
+	e.reading = true
+	e.buf = e.buf[:0]
+	e.pos = 0
+	e.redraw()
+	e.mu.Unlock()
+
+	defer func() {
+		e.mu.Lock()
+		e.reading = false
+		e.mu.Unlock()
+	}()
+
+	// histPos indexes history while browsing it; len(history) is the new line
+	histPos := len(e.history)
+	var draft []rune
+
+	for {
+		r, _, err := e.r.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		e.mu.Lock()
+		switch r {
+		case '\r', '\n':
+			line := string(e.buf)
+			io.WriteString(e.out, "\n")
+			e.mu.Unlock()
+			return line, nil
+
+		case 3: // Ctrl+C
+			io.WriteString(e.out, "^C\n")
+			e.mu.Unlock()
+			return "", ErrInterrupted
+
+		case 4: // Ctrl+D
+			if len(e.buf) == 0 {
+				io.WriteString(e.out, "\n")
+				e.mu.Unlock()
+				return "", io.EOF
+			}
+			e.deleteAt(e.pos)
+
+		case 127, 8: // Backspace
+			if e.pos > 0 {
+				e.pos--
+				e.deleteAt(e.pos)
+			}
+
+		case 1: // Ctrl+A
+			e.pos = 0
+		case 5: // Ctrl+E
+			e.pos = len(e.buf)
+		case 2: // Ctrl+B
+			e.moveBy(-1)
+		case 6: // Ctrl+F
+			e.moveBy(1)
+		case 11: // Ctrl+K
+			e.buf = e.buf[:e.pos]
+		case 21: // Ctrl+U
+			e.buf = append(e.buf[:0], e.buf[e.pos:]...)
+			e.pos = 0
+		case 23: // Ctrl+W
+			e.deleteWord()
+		case 12: // Ctrl+L
+			io.WriteString(e.out, "\x1b[H\x1b[2J")
+
+		case 16: // Ctrl+P
+			histPos, draft = e.browseHistory(histPos, -1, draft)
+		case 14: // Ctrl+N
+			histPos, draft = e.browseHistory(histPos, 1, draft)
+
+		case '\t':
+			e.completeLine()
+
+		case 27: // Escape sequence
+			e.mu.Unlock()
+			seq := e.readEscape()
+			e.mu.Lock()
+			switch seq {
+			case "[A", "OA":
+				histPos, draft = e.browseHistory(histPos, -1, draft)
+			case "[B", "OB":
+				histPos, draft = e.browseHistory(histPos, 1, draft)
+			case "[C", "OC":
+				e.moveBy(1)
+			case "[D", "OD":
+				e.moveBy(-1)
+			case "[H", "OH", "[1~", "[7~":
+				e.pos = 0
+			case "[F", "OF", "[4~", "[8~":
+				e.pos = len(e.buf)
+			case "[3~":
+				e.deleteAt(e.pos)
+			}
+
+		default:
+			if unicode.IsPrint(r) {
+				e.buf = append(e.buf, 0)
+				copy(e.buf[e.pos+1:], e.buf[e.pos:])
+				e.buf[e.pos] = r
+				e.pos++
+			}
+		}
+		e.redraw()
+		e.mu.Unlock()
+	}
+}
+
+// readEscape reads the rest of an escape sequence, e.g. "[A" for Up.
+func (e *Editor) readEscape() string {
+	first, _, err := e.r.ReadRune()
+	if err != nil || (first != '[' && first != 'O') {
+		return ""
+	}
+
+	seq := []rune{first}
+	for {
+		r, _, err := e.r.ReadRune()
+		if err != nil {
+			return ""
+		}
+		seq = append(seq, r)
+		// Parameters are digits and ';'; the final byte ends the sequence
+		if r >= 0x40 && r <= 0x7e {
+			return string(seq)
+		}
+	}
+}
+
+// redraw redraws the prompt and line and places the cursor.
+// The caller must hold mu.
+func (e *Editor) redraw() {
+	s := "\r\x1b[K" + e.prompt + string(e.buf)
+	if back := len(e.buf) - e.pos; back > 0 {
+		s += fmt.Sprintf("\x1b[%dD", back)
+	}
+	io.WriteString(e.out, s)
+}
+
+// moveBy moves the cursor by delta runes. The caller must hold mu.
+func (e *Editor) moveBy(delta int) {
+	e.pos += delta
+	if e.pos < 0 {
+		e.pos = 0
+	}
+	if e.pos > len(e.buf) {
+		e.pos = len(e.buf)
+	}
+}
+
+// deleteAt deletes the rune at i, if any. The caller must hold mu.
+func (e *Editor) deleteAt(i int) {
+	if i < len(e.buf) {
+		e.buf = append(e.buf[:i], e.buf[i+1:]...)
+	}
+}
+
+// deleteWord deletes the word before the cursor. The caller must hold mu.
+func (e *Editor) deleteWord() {
+	start := e.pos
+	for start > 0 && e.buf[start-1] == ' ' {
+		start--
+	}
+	for start > 0 && e.buf[start-1] != ' ' {
+		start--
+	}
+	e.buf = append(e.buf[:start], e.buf[e.pos:]...)
+	e.pos = start
+}
+
+// browseHistory moves through the history by delta, keeping the line being
+// typed as draft so coming back down restores it. The caller must hold mu.
+func (e *Editor) browseHistory(histPos, delta int, draft []rune) (int, []rune) {
+	next := histPos + delta
+	if next < 0 || next > len(e.history) {
+		return histPos, draft
+	}
+	if histPos == len(e.history) {
+		draft = append([]rune(nil), e.buf...)
+	}
+
+	if next == len(e.history) {
+		e.buf = append(e.buf[:0], draft...)
+	} else {
+		e.buf = append(e.buf[:0], []rune(e.history[next])...)
+	}
+	e.pos = len(e.buf)
+	return next, draft
+}
+
+// completeLine completes the text before the cursor: a single candidate is
+// inserted, several are listed and their common prefix inserted.
+// The caller must hold mu.
+func (e *Editor) completeLine() {
+	if e.complete == nil {
+		return
+	}
+	head := string(e.buf[:e.pos])
+	candidates := e.complete(head)
+
+	var completion string
+	switch len(candidates) {
+	case 0:
+		return
+	case 1:
+		completion = candidates[0] + " "
+	default:
+		completion = commonPrefix(candidates)
+		io.WriteString(e.out, "\r\x1b[K"+strings.Join(candidates, "  ")+"\n")
+	}
+
+	if strings.HasPrefix(completion, head) {
+		insert := []rune(completion[len(head):])
+		rest := append(insert, e.buf[e.pos:]...)
+		e.buf = append(e.buf[:e.pos], rest...)
+		e.pos += len(insert)
+	}
+}
+
+// commonPrefix returns the longest common prefix of words.
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, w := range words[1:] {
+		for !strings.HasPrefix(w, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
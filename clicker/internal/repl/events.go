@@ -0,0 +1,64 @@
+package repl
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/vibium/clicker/internal/bidi"
+)
+
+// maxEventParams caps how much of an unfamiliar event's params is printed.
+const maxEventParams = 300
+
+// FormatEvent renders a browser event as one readable line, e.g.
+// "[console.log] hello" or "[load] https://example.com/".
+func FormatEvent(ev *bidi.Event) string {
+	var p struct {
+		// log.entryAdded
+		Type  string `json:"type"`
+		Level string `json:"level"`
+		Text  string `json:"text"`
+
+		// browsingContext events
+		Context string `json:"context"`
+		URL     string `json:"url"`
+
+		// dialogs and downloads
+		Message           string `json:"message"`
+		Accepted          bool   `json:"accepted"`
+		SuggestedFilename string `json:"suggestedFilename"`
+		Status            string `json:"status"`
+	}
+	json.Unmarshal(ev.Params, &p)
+
+	switch ev.Method {
+	case "log.entryAdded":
+		if p.Type == "javascript" {
+			return fmt.Sprintf("[page error] %s", p.Text)
+		}
+		return fmt.Sprintf("[console.%s] %s", p.Level, p.Text)
+	case "browsingContext.load":
+		return fmt.Sprintf("[load] %s", p.URL)
+	case "browsingContext.contextCreated":
+		return fmt.Sprintf("[tab opened] %s %s", p.Context, p.URL)
+	case "browsingContext.contextDestroyed":
+		return fmt.Sprintf("[tab closed] %s", p.Context)
+	case bidi.EventUserPromptOpened:
+		return fmt.Sprintf("[dialog] %s: %s", p.Type, p.Message)
+	case bidi.EventUserPromptClosed:
+		if p.Accepted {
+			return "[dialog] accepted"
+		}
+		return "[dialog] dismissed"
+	case bidi.EventDownloadWillBegin:
+		return fmt.Sprintf("[download] %s from %s", p.SuggestedFilename, p.URL)
+	case bidi.EventDownloadEnd:
+		return fmt.Sprintf("[download] %s", p.Status)
+	}
+
+	params := string(ev.Params)
+	if len(params) > maxEventParams {
+		params = params[:maxEventParams] + "..."
+	}
+	return fmt.Sprintf("[%s] %s", ev.Method, params)
+}
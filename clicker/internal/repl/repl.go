@@ -0,0 +1,386 @@
+// Package repl implements `clicker repl`, an interactive shell for driving
+// a browser session: high-level commands such as `go`, `click` and `eval`,
+// raw BiDi JSON, and a live view of browser events.
+package repl
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vibium/clicker/internal/bidi"
+	"github.com/vibium/clicker/internal/features"
+)
+
+// Events shown while the REPL runs, in addition to the dialog and download
+// events the session already tracks.
+var watchedEvents = []string{
+	"log.entryAdded",
+	"browsingContext.load",
+	"browsingContext.contextCreated",
+	"browsingContext.contextDestroyed",
+}
+
+// pollInterval is how long the event watcher waits for events at a time.
+// A command typed meanwhile waits at most this long to start.
+const pollInterval = 100 * time.Millisecond
+
+// command is a REPL command.
+type command struct {
+	name  string
+	usage string
+	help  string
+	run   func(r *REPL, arg string) error
+}
+
+// commands lists the REPL commands. init fills it in, as help refers to it.
+var commands []command
+
+func init() {
+	commands = []command{
+		{"go", "go <url>", "Navigate the current tab to url", (*REPL).navigate},
+		{"click", "click <selector>", "Wait until the element is actionable and click it", (*REPL).click},
+		{"type", "type <selector> <text>", "Click the element and type text (quote selectors with spaces)", (*REPL).typeText},
+		{"find", "find <selector>", "Show the tag, text and box of the first match", (*REPL).find},
+		{"eval", "eval <js>", "Evaluate a JavaScript expression and print the result", (*REPL).eval},
+		{"shot", "shot [file]", "Save a screenshot (default screenshot.png)", (*REPL).shot},
+		{"tabs", "tabs", "List open tabs", (*REPL).tabs},
+		{"events", "events [on|off]", "Show or hide browser events (default on)", (*REPL).toggleEvents},
+		{"help", "help", "Show this help", (*REPL).help},
+		{"exit", "exit", "Leave the REPL (also: quit, Ctrl+D)", nil},
+	}
+}
+
+// REPL is an interactive session with a browser.
+type REPL struct {
+	client  *bidi.Client
+	editor  *Editor
+	out     io.Writer
+	timeout time.Duration
+
+	// mu serializes use of the client between commands and the event watcher.
+	mu         sync.Mutex
+	showEvents bool
+}
+
+// New creates a REPL for client reading from in and writing to out.
+// History is kept in historyFile; timeout bounds actionability waits.
+func New(client *bidi.Client, in *os.File, out io.Writer, historyFile string, timeout time.Duration) *REPL {
+	r := &REPL{
+		client:     client,
+		out:        out,
+		timeout:    timeout,
+		showEvents: true,
+	}
+	r.editor = NewEditor(in, out, "clicker> ", historyFile, completeCommand)
+	return r
+}
+
+// completeCommand completes command names in the first word of a line.
+func completeCommand(head string) []string {
+	if strings.ContainsAny(head, " \t") || strings.HasPrefix(head, "{") {
+		return nil
+	}
+
+	var matches []string
+	for _, c := range commands {
+		if strings.HasPrefix(c.name, head) {
+			matches = append(matches, c.name)
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// Run reads and runs commands until exit or the end of input.
+func (r *REPL) Run() error {
+	if err := r.client.Subscribe(watchedEvents...); err != nil {
+		fmt.Fprintf(r.out, "Events unavailable: %v\n", err)
+	}
+
+	if r.editor.Interactive() {
+		fmt.Fprintln(r.out, "Type 'help' for commands, or a raw BiDi command as JSON. Ctrl+D exits.")
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go r.watchEvents(stop)
+
+	for {
+		line, err := r.editor.ReadLine()
+		if err == ErrInterrupted {
+			continue
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		r.editor.AddHistory(line)
+		if line == "exit" || line == "quit" {
+			return nil
+		}
+
+		r.mu.Lock()
+		if err := r.execute(line); err != nil {
+			fmt.Fprintf(r.out, "Error: %v\n", err)
+		}
+		// Show what the command caused right away
+		r.printEvents(time.Millisecond)
+		r.mu.Unlock()
+	}
+}
+
+// watchEvents prints browser events as they arrive, until stop is closed
+// or the connection is lost.
+func (r *REPL) watchEvents(stop chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		r.mu.Lock()
+		err := r.printEvents(pollInterval)
+		r.mu.Unlock()
+		if err != nil {
+			r.editor.PrintAbove(fmt.Sprintf("[disconnected] %v", err))
+			return
+		}
+	}
+}
+
+// printEvents waits up to timeout for events and prints them, unless they
+// are turned off. The caller must hold mu.
+func (r *REPL) printEvents(timeout time.Duration) error {
+	events, err := r.client.TakeEvents(timeout)
+	if err != nil {
+		return err
+	}
+	if r.showEvents {
+		for _, ev := range events {
+			r.editor.PrintAbove(FormatEvent(ev))
+		}
+	}
+	return nil
+}
+
+// execute runs one line: raw JSON or a command. The caller must hold mu.
+func (r *REPL) execute(line string) error {
+	if strings.HasPrefix(line, "{") {
+		return r.raw(line)
+	}
+
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+	for _, c := range commands {
+		if c.name == name && c.run != nil {
+			return c.run(r, arg)
+		}
+	}
+	return fmt.Errorf("unknown command %q (type 'help')", name)
+}
+
+// raw sends a BiDi command given as {"method": ..., "params": ...} and
+// prints the result. Any "id" is replaced with the client's own.
+func (r *REPL) raw(line string) error {
+	var cmd struct {
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	}
+	if err := json.Unmarshal([]byte(line), &cmd); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	if cmd.Method == "" {
+		return fmt.Errorf("missing \"method\"")
+	}
+	params := cmd.Params
+	if len(params) == 0 {
+		params = json.RawMessage("{}")
+	}
+
+	msg, err := r.client.SendCommand(cmd.Method, params)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(r.out, indentJSON(msg.Result))
+	return nil
+}
+
+// requireArg returns an error naming the usage if arg is empty.
+func requireArg(arg, usage string) error {
+	if arg == "" {
+		return fmt.Errorf("usage: %s", usage)
+	}
+	return nil
+}
+
+func (r *REPL) navigate(url string) error {
+	if err := requireArg(url, "go <url>"); err != nil {
+		return err
+	}
+	if !strings.Contains(url, "://") && !strings.HasPrefix(url, "about:") && !strings.HasPrefix(url, "data:") {
+		url = "https://" + url
+	}
+
+	result, err := r.client.Navigate("", url)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(r.out, "Navigated to %s\n", result.URL)
+	return nil
+}
+
+func (r *REPL) click(selector string) error {
+	if err := requireArg(selector, "click <selector>"); err != nil {
+		return err
+	}
+	if err := features.WaitForClick(r.client, "", selector, features.WaitOptions{Timeout: r.timeout}); err != nil {
+		return err
+	}
+	if err := r.client.ClickElement("", selector); err != nil {
+		return err
+	}
+	fmt.Fprintf(r.out, "Clicked %s\n", selector)
+	return nil
+}
+
+func (r *REPL) typeText(arg string) error {
+	selector, text, err := splitSelector(arg)
+	if err != nil || selector == "" || text == "" {
+		return fmt.Errorf("usage: type <selector> <text>")
+	}
+	if err := features.WaitForType(r.client, "", selector, features.WaitOptions{Timeout: r.timeout}); err != nil {
+		return err
+	}
+	if err := r.client.TypeIntoElement("", selector, text); err != nil {
+		return err
+	}
+
+	value, err := r.client.GetElementValue("", selector)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(r.out, "Value is now: %s\n", value)
+	return nil
+}
+
+func (r *REPL) find(selector string) error {
+	if err := requireArg(selector, "find <selector>"); err != nil {
+		return err
+	}
+	info, err := r.client.FindElement("", selector)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(r.out, "tag=%s, text=%q, box={x:%.0f, y:%.0f, w:%.0f, h:%.0f}\n",
+		info.Tag, info.Text, info.Box.X, info.Box.Y, info.Box.Width, info.Box.Height)
+	return nil
+}
+
+func (r *REPL) eval(expression string) error {
+	if err := requireArg(expression, "eval <js>"); err != nil {
+		return err
+	}
+	result, err := r.client.Evaluate("", expression)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(r.out, "%v\n", result)
+	return nil
+}
+
+func (r *REPL) shot(path string) error {
+	if path == "" {
+		path = "screenshot.png"
+	}
+	data, err := r.client.CaptureScreenshot("")
+	if err != nil {
+		return err
+	}
+	image, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return fmt.Errorf("failed to decode screenshot: %w", err)
+	}
+	if err := os.WriteFile(path, image, 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(r.out, "Saved %s (%d bytes)\n", path, len(image))
+	return nil
+}
+
+func (r *REPL) tabs(string) error {
+	contexts, err := r.client.ListContexts()
+	if err != nil {
+		return err
+	}
+	for i, ctx := range contexts {
+		fmt.Fprintf(r.out, "[%d] %s %s\n", i, ctx.Context, ctx.URL)
+	}
+	return nil
+}
+
+func (r *REPL) toggleEvents(arg string) error {
+	switch arg {
+	case "", "on":
+		arg = "on"
+		r.showEvents = true
+	case "off":
+		r.showEvents = false
+	default:
+		return fmt.Errorf("usage: events [on|off]")
+	}
+	fmt.Fprintf(r.out, "Events %s\n", arg)
+	return nil
+}
+
+func (r *REPL) help(string) error {
+	for _, c := range commands {
+		fmt.Fprintf(r.out, "  %-24s %s\n", c.usage, c.help)
+	}
+	fmt.Fprintf(r.out, "  %-24s %s\n", `{"method": ...}`, "Send a raw BiDi command, e.g. {\"method\":\"browsingContext.getTree\",\"params\":{}}")
+	return nil
+}
+
+// splitSelector splits "selector rest" where the selector may be quoted
+// with ' or " to include spaces.
+func splitSelector(arg string) (string, string, error) {
+	if arg == "" {
+		return "", "", nil
+	}
+	if q := arg[0]; q == '"' || q == '\'' {
+		end := strings.IndexByte(arg[1:], q)
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated quote")
+		}
+		return arg[1 : end+1], strings.TrimSpace(arg[end+2:]), nil
+	}
+	selector, rest, _ := strings.Cut(arg, " ")
+	return selector, strings.TrimSpace(rest), nil
+}
+
+// indentJSON pretty-prints JSON, or returns it unchanged if it is invalid.
+func indentJSON(data []byte) string {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return string(data)
+	}
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return string(data)
+	}
+	return string(out)
+}
@@ -0,0 +1,16 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd
+
+package repl
+
+import "errors"
+
+// isTerminal reports false: line editing is not supported here, so input is
+// read line by line as typed.
+func isTerminal(fd uintptr) bool {
+	return false
+}
+
+// makeRaw is not supported on this platform.
+func makeRaw(fd uintptr) (func(), error) {
+	return nil, errors.New("raw terminal mode is not supported on this platform")
+}
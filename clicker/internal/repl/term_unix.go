@@ -0,0 +1,46 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package repl
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// ioctl gets or sets terminal attributes.
+func ioctl(fd uintptr, request uintptr, t *syscall.Termios) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, uintptr(unsafe.Pointer(t)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// isTerminal reports whether fd is a terminal.
+func isTerminal(fd uintptr) bool {
+	var t syscall.Termios
+	return ioctl(fd, ioctlGetTermios, &t) == nil
+}
+
+// makeRaw puts the terminal into raw mode, so keys arrive one at a time and
+// are not echoed, and returns a function that restores the previous mode.
+// Output processing stays on, so "\n" still starts a new line.
+func makeRaw(fd uintptr) (func(), error) {
+	var old syscall.Termios
+	if err := ioctl(fd, ioctlGetTermios, &old); err != nil {
+		return nil, err
+	}
+
+	raw := old
+	raw.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP | syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+	raw.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cflag &^= syscall.CSIZE | syscall.PARENB
+	raw.Cflag |= syscall.CS8
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := ioctl(fd, ioctlSetTermios, &raw); err != nil {
+		return nil, err
+	}
+
+	return func() { ioctl(fd, ioctlSetTermios, &old) }, nil
+}
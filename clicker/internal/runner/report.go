@@ -0,0 +1,145 @@
+package runner
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Step statuses.
+const (
+	StatusPassed  = "passed"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped" // not run because an earlier step failed
+)
+
+// StepResult is the outcome of one step.
+type StepResult struct {
+	Index      int    `json:"index"` // 1-based
+	Name       string `json:"name"`
+	Action     string `json:"action"`
+	Status     string `json:"status"`
+	DurationMs int64  `json:"durationMs"`
+	Error      string `json:"error,omitempty"`
+	Code       string `json:"code,omitempty"`   // error code, e.g. "element_not_found"
+	Output     string `json:"output,omitempty"` // eval result or screenshot path
+}
+
+// Report is the outcome of a run.
+type Report struct {
+	Name       string       `json:"name"`
+	Started    time.Time    `json:"started"`
+	DurationMs int64        `json:"durationMs"`
+	Passed     int          `json:"passed"`
+	Failed     int          `json:"failed"`
+	Skipped    int          `json:"skipped"`
+	Steps      []StepResult `json:"steps"`
+}
+
+// add appends a step result and updates the counts.
+func (r *Report) add(result StepResult) {
+	switch result.Status {
+	case StatusPassed:
+		r.Passed++
+	case StatusFailed:
+		r.Failed++
+	case StatusSkipped:
+		r.Skipped++
+	}
+	r.Steps = append(r.Steps, result)
+}
+
+// OK reports whether every step passed.
+func (r *Report) OK() bool {
+	return r.Failed == 0 && r.Skipped == 0
+}
+
+// Summary returns a one-line summary such as "3 passed, 1 failed, 2 skipped".
+func (r *Report) Summary() string {
+	return fmt.Sprintf("%d passed, %d failed, %d skipped in %s",
+		r.Passed, r.Failed, r.Skipped, time.Duration(r.DurationMs)*time.Millisecond)
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// JUnit XML elements, as understood by common CI systems.
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Skipped   int         `xml:"skipped,attr"`
+	Time      string      `xml:"time,attr"`
+	Timestamp string      `xml:"timestamp,attr"`
+	Cases     []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// seconds formats milliseconds as JUnit's decimal seconds.
+func seconds(ms int64) string {
+	return fmt.Sprintf("%.3f", float64(ms)/1000)
+}
+
+// WriteJUnit writes the report as JUnit XML, one test case per step.
+func (r *Report) WriteJUnit(w io.Writer) error {
+	suite := junitSuite{
+		Name:      r.Name,
+		Tests:     len(r.Steps),
+		Failures:  r.Failed,
+		Skipped:   r.Skipped,
+		Time:      seconds(r.DurationMs),
+		Timestamp: r.Started.Format("2006-01-02T15:04:05"),
+	}
+
+	for _, step := range r.Steps {
+		c := junitCase{
+			Name:      fmt.Sprintf("%d. %s", step.Index, step.Name),
+			ClassName: r.Name,
+			Time:      seconds(step.DurationMs),
+			SystemOut: step.Output,
+		}
+		switch step.Status {
+		case StatusFailed:
+			c.Failure = &junitFailure{Message: step.Error, Type: step.Action, Text: step.Error}
+		case StatusSkipped:
+			c.Skipped = &struct{}{}
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitSuites{Suites: []junitSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
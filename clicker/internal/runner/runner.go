@@ -0,0 +1,526 @@
+package runner
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vibium/clicker/internal/bidi"
+	errs "github.com/vibium/clicker/internal/errors"
+	"github.com/vibium/clicker/internal/features"
+)
+
+// DefaultTimeout is the per-step timeout when neither the step, the script
+// nor the options set one.
+const DefaultTimeout = 30 * time.Second
+
+// Options configures a Runner.
+type Options struct {
+	// Timeout is the default per-step timeout. The script's timeout and each
+	// step's own timeout take precedence.
+	Timeout time.Duration
+	// ContinueOnError keeps running after a failed step.
+	ContinueOnError bool
+	// BaseDir resolves relative screenshot paths. Empty = working directory.
+	BaseDir string
+	// Progress receives a line per step. nil = no output.
+	Progress io.Writer
+	// BeforeNavigate is called with the URL before each navigate step,
+	// e.g. to grant permissions for its origin.
+	BeforeNavigate func(url string) error
+}
+
+// Runner runs scripts in one browser session.
+type Runner struct {
+	client  *bidi.Client
+	context string
+	opts    Options
+}
+
+// New creates a Runner that drives the browsing context that is first in
+// client's tree when it is created, so tabs opened by the script later do not
+// take over. If the tree cannot be read, each command falls back to the first
+// context at the time it runs.
+func New(client *bidi.Client, opts Options) *Runner {
+	r := &Runner{client: client, opts: opts}
+	if tree, err := client.GetTree(); err == nil && len(tree.Contexts) > 0 {
+		r.context = tree.Contexts[0].Context
+	}
+	return r
+}
+
+// abortError is a step failure that leaves the session unusable, such as a
+// command that is still running after its timeout. It stops the run even
+// with continue-on-error.
+type abortError struct {
+	err error
+}
+
+func (e *abortError) Error() string { return e.err.Error() }
+
+// ErrorCode reports aborted steps as timeouts, the only reason to abort.
+func (e *abortError) ErrorCode() errs.Code { return errs.CodeTimeout }
+
+// Run executes the steps of script in order and reports the outcome of each.
+func (r *Runner) Run(script *Script) *Report {
+	report := &Report{
+		Name:    script.Name,
+		Started: time.Now(),
+	}
+	if report.Name == "" {
+		report.Name = "clicker run"
+	}
+
+	continueOnError := r.opts.ContinueOnError || script.ContinueOnError
+	stopped := false
+
+	for i := range script.Steps {
+		step := &script.Steps[i]
+		result := StepResult{
+			Index:  i + 1,
+			Name:   step.Describe(),
+			Action: step.Action(),
+		}
+
+		if stopped {
+			result.Status = StatusSkipped
+			report.add(result)
+			r.progress("SKIP %d. %s\n", result.Index, result.Name)
+			continue
+		}
+
+		timeout := r.stepTimeout(script, step)
+		start := time.Now()
+		output, err := r.runStep(step, timeout)
+		result.DurationMs = time.Since(start).Milliseconds()
+		result.Output = output
+
+		if err != nil {
+			result.Status = StatusFailed
+			result.Error = err.Error()
+			result.Code = string(errs.CodeOf(err))
+			r.progress("FAIL %d. %s: %v\n", result.Index, result.Name, err)
+
+			keepGoing := continueOnError
+			if step.ContinueOnError != nil {
+				keepGoing = *step.ContinueOnError
+			}
+			// Nothing can run in a crashed browser
+			crashed := errs.CodeOf(err) == errs.CodeBrowserCrashed
+			if _, aborted := err.(*abortError); aborted || crashed || !keepGoing {
+				stopped = true
+			}
+		} else {
+			result.Status = StatusPassed
+			if output != "" {
+				r.progress("PASS %d. %s -> %s\n", result.Index, result.Name, output)
+			} else {
+				r.progress("PASS %d. %s\n", result.Index, result.Name)
+			}
+		}
+		report.add(result)
+	}
+
+	report.DurationMs = time.Since(report.Started).Milliseconds()
+	return report
+}
+
+// stepTimeout picks the timeout for step.
+func (r *Runner) stepTimeout(script *Script, step *Step) time.Duration {
+	switch {
+	case step.Timeout > 0:
+		return time.Duration(step.Timeout)
+	case script.Timeout > 0:
+		return time.Duration(script.Timeout)
+	case r.opts.Timeout > 0:
+		return r.opts.Timeout
+	default:
+		return DefaultTimeout
+	}
+}
+
+// progress writes a progress line if a writer is configured.
+func (r *Runner) progress(format string, args ...interface{}) {
+	if r.opts.Progress != nil {
+		fmt.Fprintf(r.opts.Progress, format, args...)
+	}
+}
+
+// runStep executes one step and returns its output, if any.
+func (r *Runner) runStep(step *Step, timeout time.Duration) (string, error) {
+	deadline := time.Now().Add(timeout)
+
+	switch step.Action() {
+	case "navigate":
+		if r.opts.BeforeNavigate != nil {
+			if err := r.opts.BeforeNavigate(step.Navigate); err != nil {
+				return "", err
+			}
+		}
+		return "", withTimeout(timeout, func() error {
+			_, err := r.client.Navigate(r.context, step.Navigate)
+			return err
+		})
+
+	case "click":
+		if err := r.waitActionable(step.Click.Selector, features.ClickChecks, deadline); err != nil {
+			return "", err
+		}
+		return "", withTimeout(remaining(deadline), func() error {
+			return r.client.ClickElement(r.context, step.Click.Selector)
+		})
+
+	case "type":
+		if err := r.waitActionable(step.Type.Selector, features.TypeChecks, deadline); err != nil {
+			return "", err
+		}
+		return "", withTimeout(remaining(deadline), func() error {
+			if step.Type.Clear {
+				return r.client.Fill(r.context, step.Type.Selector, step.Type.Text)
+			}
+			return r.client.TypeIntoElement(r.context, step.Type.Selector, step.Type.Text)
+		})
+
+	case "press":
+		if step.Press.Selector != "" {
+			if err := r.waitActionable(step.Press.Selector, features.ClickChecks, deadline); err != nil {
+				return "", err
+			}
+			err := withTimeout(remaining(deadline), func() error {
+				return r.client.ClickElement(r.context, step.Press.Selector)
+			})
+			if err != nil {
+				return "", err
+			}
+		}
+		return "", withTimeout(remaining(deadline), func() error {
+			return r.client.PressChord(r.context, step.Press.Keys)
+		})
+
+	case "wait":
+		return "", r.wait(step.Wait, timeout)
+
+	case "assert":
+		return "", r.assert(step.Assert, timeout)
+
+	case "screenshot":
+		return r.screenshot(step.Screenshot, timeout)
+
+	case "eval":
+		var result interface{}
+		err := withTimeout(timeout, func() error {
+			var err error
+			result, err = r.client.Evaluate(r.context, step.Eval)
+			return err
+		})
+		if err != nil {
+			return "", err
+		}
+		return formatValue(result), nil
+	}
+
+	return "", fmt.Errorf("unknown step")
+}
+
+// withTimeout runs fn and fails with an abortError if it takes longer than
+// timeout. fn keeps running in the background, so the session must not be
+// used afterwards.
+func withTimeout(timeout time.Duration, fn func() error) error {
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+		return &abortError{err: fmt.Errorf("timed out after %s (the browser is still busy, stopping)", timeout)}
+	}
+}
+
+// minCommandTimeout is the least time a command gets, so a step that spent
+// its timeout waiting or polling still runs its last command instead of
+// aborting the run.
+const minCommandTimeout = time.Second
+
+// remaining returns the time a command may take to finish by deadline.
+func remaining(deadline time.Time) time.Duration {
+	if d := time.Until(deadline); d > minCommandTimeout {
+		return d
+	}
+	return minCommandTimeout
+}
+
+// waitFor runs a features wait with the time left until deadline. The wait
+// reports its own timeout; only a poll that hangs, e.g. on a page stuck in a
+// script, aborts the run once the wait overruns by minCommandTimeout.
+func waitFor(deadline time.Time, wait func(features.WaitOptions) error) error {
+	opts := features.WaitOptions{Timeout: remaining(deadline)}
+	return withTimeout(opts.Timeout+minCommandTimeout, func() error {
+		return wait(opts)
+	})
+}
+
+// waitActionable waits until selector exists and passes checks, sharing the
+// step's time between both waits.
+func (r *Runner) waitActionable(selector string, checks []features.Check, deadline time.Time) error {
+	err := waitFor(deadline, func(opts features.WaitOptions) error {
+		return features.WaitForSelector(r.client, r.context, selector, opts)
+	})
+	if err != nil {
+		return err
+	}
+	return waitFor(deadline, func(opts features.WaitOptions) error {
+		return features.WaitForActionable(r.client, r.context, selector, checks, opts)
+	})
+}
+
+// currentURL returns the URL of the runner's tab.
+func (r *Runner) currentURL() (string, error) {
+	if r.context == "" {
+		return r.client.GetCurrentURL()
+	}
+	contexts, err := r.client.ListContexts()
+	if err != nil {
+		return "", err
+	}
+	for _, c := range contexts {
+		if c.Context == r.context {
+			return c.URL, nil
+		}
+	}
+	return "", fmt.Errorf("tab %s was closed", r.context)
+}
+
+// wait handles wait steps.
+func (r *Runner) wait(w *WaitStep, timeout time.Duration) error {
+	switch {
+	case w.Selector != "":
+		return waitFor(time.Now().Add(timeout), func(opts features.WaitOptions) error {
+			return features.WaitForSelector(r.client, r.context, w.Selector, opts)
+		})
+
+	case w.URL != "":
+		deadline := time.Now().Add(timeout)
+		for {
+			var url string
+			err := withTimeout(remaining(deadline), func() error {
+				var err error
+				url, err = r.currentURL()
+				return err
+			})
+			if _, aborted := err.(*abortError); aborted {
+				return err
+			}
+			if err == nil && strings.Contains(url, w.URL) {
+				return nil
+			}
+			if time.Now().After(deadline) {
+				return fmt.Errorf("timeout after %s waiting for URL to contain %q (last: %s)", timeout, w.URL, url)
+			}
+			time.Sleep(features.DefaultInterval)
+		}
+
+	default:
+		time.Sleep(time.Duration(w.Time))
+		return nil
+	}
+}
+
+// assertStateScript reads the page state an assertion checks.
+const assertStateScript = `
+	(selector, expression) => {
+		const state = { url: location.href, title: document.title };
+		if (selector) {
+			const el = document.querySelector(selector);
+			state.found = !!el;
+			if (el) {
+				const style = getComputedStyle(el);
+				const rect = el.getBoundingClientRect();
+				state.visible = rect.width > 0 && rect.height > 0 && style.visibility !== 'hidden' && style.display !== 'none';
+				state.text = (el.innerText ?? el.textContent ?? '');
+				state.value = 'value' in el ? String(el.value) : '';
+			}
+		}
+		if (expression) {
+			try {
+				state.eval = !!(0, eval)(expression);
+			} catch (e) {
+				state.evalError = String(e);
+			}
+		}
+		return JSON.stringify(state);
+	}
+`
+
+// pageState is the result of assertStateScript.
+type pageState struct {
+	URL       string `json:"url"`
+	Title     string `json:"title"`
+	Found     bool   `json:"found"`
+	Visible   bool   `json:"visible"`
+	Text      string `json:"text"`
+	Value     string `json:"value"`
+	Eval      bool   `json:"eval"`
+	EvalError string `json:"evalError"`
+}
+
+// assert polls the page until all checks of a hold or the timeout expires.
+func (r *Runner) assert(a *AssertStep, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		var result interface{}
+		err := withTimeout(remaining(deadline), func() error {
+			var err error
+			result, err = r.client.CallFunction(r.context, assertStateScript, []interface{}{a.Selector, a.Eval})
+			return err
+		})
+		if _, aborted := err.(*abortError); aborted {
+			return err
+		}
+		var failure error
+		if err != nil {
+			failure = err
+		} else {
+			var state pageState
+			if err := json.Unmarshal([]byte(fmt.Sprintf("%v", result)), &state); err != nil {
+				return fmt.Errorf("failed to parse page state: %w", err)
+			}
+			failure = checkAssertion(a, &state)
+		}
+
+		if failure == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("assertion failed: %w", failure)
+		}
+		time.Sleep(features.DefaultInterval)
+	}
+}
+
+// checkAssertion returns why state does not satisfy a, or nil.
+func checkAssertion(a *AssertStep, state *pageState) error {
+	if a.Selector != "" {
+		if !state.Found {
+			if a.Visible != nil && !*a.Visible {
+				return nil // absent counts as not visible
+			}
+			return fmt.Errorf("element %s not found", a.Selector)
+		}
+		if a.Visible != nil && state.Visible != *a.Visible {
+			return fmt.Errorf("expected %s visible=%v, got %v", a.Selector, *a.Visible, state.Visible)
+		}
+		if a.Text != "" && !strings.Contains(state.Text, a.Text) {
+			return fmt.Errorf("expected text of %s to contain %q, got %q", a.Selector, a.Text, truncate(state.Text))
+		}
+		if a.Equals != "" && strings.TrimSpace(state.Text) != a.Equals {
+			return fmt.Errorf("expected text of %s to equal %q, got %q", a.Selector, a.Equals, truncate(state.Text))
+		}
+		if a.Value != "" && state.Value != a.Value {
+			return fmt.Errorf("expected value of %s to equal %q, got %q", a.Selector, a.Value, state.Value)
+		}
+	}
+	if a.URL != "" && !strings.Contains(state.URL, a.URL) {
+		return fmt.Errorf("expected URL to contain %q, got %q", a.URL, state.URL)
+	}
+	if a.Title != "" && !strings.Contains(state.Title, a.Title) {
+		return fmt.Errorf("expected title to contain %q, got %q", a.Title, state.Title)
+	}
+	if a.Eval != "" {
+		if state.EvalError != "" {
+			return fmt.Errorf("%s threw %s", a.Eval, state.EvalError)
+		}
+		if !state.Eval {
+			return fmt.Errorf("expected %s to be truthy", a.Eval)
+		}
+	}
+	return nil
+}
+
+// truncate shortens long page text in messages.
+func truncate(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) > 200 {
+		return s[:200] + "..."
+	}
+	return s
+}
+
+// screenshot handles screenshot steps and returns the saved path.
+func (r *Runner) screenshot(s *ScreenshotStep, timeout time.Duration) (string, error) {
+	path := s.Path
+	if path == "" {
+		path = fmt.Sprintf("screenshot-%d.png", time.Now().UnixNano())
+	}
+	if !filepath.IsAbs(path) && r.opts.BaseDir != "" {
+		path = filepath.Join(r.opts.BaseDir, path)
+	}
+
+	opts := bidi.ScreenshotOptions{FullPage: s.FullPage}
+	if ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), "."); ext != "" {
+		if _, err := bidi.ScreenshotMimeType(ext); err == nil {
+			opts.Format = ext
+		}
+	}
+
+	if s.Selector != "" {
+		err := waitFor(time.Now().Add(timeout), func(opts features.WaitOptions) error {
+			return features.WaitForSelector(r.client, r.context, s.Selector, opts)
+		})
+		if err != nil {
+			return "", err
+		}
+		var id string
+		err = withTimeout(timeout, func() error {
+			var err error
+			id, err = r.client.GetElementSharedID(r.context, s.Selector)
+			return err
+		})
+		if err != nil {
+			return "", err
+		}
+		opts.ElementID = id
+	}
+
+	var data string
+	err := withTimeout(timeout, func() error {
+		var err error
+		data, err = r.client.CaptureScreenshotWithOptions(r.context, opts)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode screenshot: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, decoded, 0644); err != nil {
+		return "", fmt.Errorf("failed to save screenshot: %w", err)
+	}
+	return path, nil
+}
+
+// formatValue renders an eval result for output.
+func formatValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
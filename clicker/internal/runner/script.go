@@ -0,0 +1,359 @@
+// Package runner executes scripted browser flows: a YAML or JSON list of
+// steps (navigate, click, type, press, wait, assert, screenshot, eval) run
+// in one session, with a JUnit XML or JSON report.
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Script is a parsed script file. A file may also be just a list of steps.
+type Script struct {
+	Name            string   `json:"name,omitempty"`
+	Timeout         Duration `json:"timeout,omitempty"`         // default per-step timeout
+	ContinueOnError bool     `json:"continueOnError,omitempty"` // keep going after a failed step
+	Steps           []Step   `json:"steps"`
+}
+
+// Step is one action. Exactly one of the action fields must be set.
+type Step struct {
+	Name string `json:"name,omitempty"`
+
+	Navigate   string          `json:"navigate,omitempty"`
+	Click      *ClickStep      `json:"click,omitempty"`
+	Type       *TypeStep       `json:"type,omitempty"`
+	Press      *PressStep      `json:"press,omitempty"`
+	Wait       *WaitStep       `json:"wait,omitempty"`
+	Assert     *AssertStep     `json:"assert,omitempty"`
+	Screenshot *ScreenshotStep `json:"screenshot,omitempty"`
+	Eval       string          `json:"eval,omitempty"`
+
+	Timeout         Duration `json:"timeout,omitempty"`
+	ContinueOnError *bool    `json:"continueOnError,omitempty"`
+}
+
+// ClickStep clicks an element. Shorthand: `click: "#selector"`.
+type ClickStep struct {
+	Selector string `json:"selector"`
+}
+
+// TypeStep types text into an element; Clear replaces its content instead
+// of appending.
+type TypeStep struct {
+	Selector string `json:"selector"`
+	Text     string `json:"text"`
+	Clear    bool   `json:"clear,omitempty"`
+}
+
+// PressStep presses a key chord, optionally clicking an element first.
+// Shorthand: `press: "Control+A"`.
+type PressStep struct {
+	Keys     string `json:"keys"`
+	Selector string `json:"selector,omitempty"`
+}
+
+// WaitStep waits for an element to exist, for the URL to contain a string,
+// or for a fixed time. Shorthand: `wait: "#selector"`.
+type WaitStep struct {
+	Selector string   `json:"selector,omitempty"`
+	URL      string   `json:"url,omitempty"`
+	Time     Duration `json:"time,omitempty"`
+}
+
+// AssertStep checks the page, retrying until the step times out. All set
+// fields must hold.
+type AssertStep struct {
+	Selector string `json:"selector,omitempty"`
+	Text     string `json:"text,omitempty"`    // element text contains
+	Equals   string `json:"equals,omitempty"`  // element text (trimmed) equals
+	Value    string `json:"value,omitempty"`   // input value equals
+	Visible  *bool  `json:"visible,omitempty"` // element is (not) visible
+	URL      string `json:"url,omitempty"`     // page URL contains
+	Title    string `json:"title,omitempty"`   // page title contains
+	Eval     string `json:"eval,omitempty"`    // JavaScript expression is truthy
+}
+
+// ScreenshotStep saves a screenshot. The format follows the file extension.
+// Shorthand: `screenshot: "out.png"`.
+type ScreenshotStep struct {
+	Path     string `json:"path,omitempty"`
+	FullPage bool   `json:"fullPage,omitempty"`
+	Selector string `json:"selector,omitempty"`
+}
+
+// Duration is a time.Duration written as "5s" or as milliseconds.
+type Duration time.Duration
+
+// UnmarshalJSON accepts "500ms"-style strings and numbers of milliseconds.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		v, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", s)
+		}
+		*d = Duration(v)
+		return nil
+	}
+
+	var ms float64
+	if err := json.Unmarshal(data, &ms); err != nil {
+		return fmt.Errorf("invalid duration %s", string(data))
+	}
+	*d = Duration(time.Duration(ms * float64(time.Millisecond)))
+	return nil
+}
+
+// UnmarshalJSON accepts a selector string or an object.
+func (s *ClickStep) UnmarshalJSON(data []byte) error {
+	type plain ClickStep
+	return unmarshalShorthand(data, &s.Selector, (*plain)(s))
+}
+
+// UnmarshalJSON accepts a key chord string or an object.
+func (s *PressStep) UnmarshalJSON(data []byte) error {
+	type plain PressStep
+	return unmarshalShorthand(data, &s.Keys, (*plain)(s))
+}
+
+// UnmarshalJSON accepts a selector string or an object.
+func (s *WaitStep) UnmarshalJSON(data []byte) error {
+	type plain WaitStep
+	return unmarshalShorthand(data, &s.Selector, (*plain)(s))
+}
+
+// UnmarshalJSON accepts a path string or an object.
+func (s *ScreenshotStep) UnmarshalJSON(data []byte) error {
+	type plain ScreenshotStep
+	return unmarshalShorthand(data, &s.Path, (*plain)(s))
+}
+
+// UnmarshalJSON rejects unknown fields, so typos in step options are reported.
+func (s *TypeStep) UnmarshalJSON(data []byte) error {
+	type plain TypeStep
+	return strictUnmarshal(data, (*plain)(s))
+}
+
+// UnmarshalJSON rejects unknown fields, so typos in assertions are reported.
+func (s *AssertStep) UnmarshalJSON(data []byte) error {
+	type plain AssertStep
+	return strictUnmarshal(data, (*plain)(s))
+}
+
+// unmarshalShorthand stores a JSON string in field, or decodes an object into v.
+func unmarshalShorthand(data []byte, field *string, v interface{}) error {
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, field)
+	}
+	return strictUnmarshal(data, v)
+}
+
+// strictUnmarshal decodes data into v, rejecting unknown fields.
+func strictUnmarshal(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// Action returns the name of the step's action, e.g. "click".
+func (s *Step) Action() string {
+	actions := s.actions()
+	if len(actions) == 0 {
+		return ""
+	}
+	return actions[0]
+}
+
+// actions lists the action fields that are set.
+func (s *Step) actions() []string {
+	var actions []string
+	if s.Navigate != "" {
+		actions = append(actions, "navigate")
+	}
+	if s.Click != nil {
+		actions = append(actions, "click")
+	}
+	if s.Type != nil {
+		actions = append(actions, "type")
+	}
+	if s.Press != nil {
+		actions = append(actions, "press")
+	}
+	if s.Wait != nil {
+		actions = append(actions, "wait")
+	}
+	if s.Assert != nil {
+		actions = append(actions, "assert")
+	}
+	if s.Screenshot != nil {
+		actions = append(actions, "screenshot")
+	}
+	if s.Eval != "" {
+		actions = append(actions, "eval")
+	}
+	return actions
+}
+
+// Describe returns a short description of the step for progress output and
+// reports: its name if set, or the action and its main argument.
+func (s *Step) Describe() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	switch s.Action() {
+	case "navigate":
+		return "navigate " + s.Navigate
+	case "click":
+		return "click " + s.Click.Selector
+	case "type":
+		return "type into " + s.Type.Selector // the text may be a secret
+	case "press":
+		return "press " + s.Press.Keys
+	case "wait":
+		switch {
+		case s.Wait.Selector != "":
+			return "wait for " + s.Wait.Selector
+		case s.Wait.URL != "":
+			return "wait for URL " + s.Wait.URL
+		default:
+			return "wait " + time.Duration(s.Wait.Time).String()
+		}
+	case "assert":
+		if s.Assert.Selector != "" {
+			return "assert " + s.Assert.Selector
+		}
+		return "assert page"
+	case "screenshot":
+		return "screenshot " + s.Screenshot.Path
+	case "eval":
+		return "eval " + s.Eval
+	}
+	return "step"
+}
+
+// validate checks that the step has exactly one action with its required fields.
+func (s *Step) validate() error {
+	actions := s.actions()
+	switch len(actions) {
+	case 0:
+		return fmt.Errorf("no action (expected one of navigate, click, type, press, wait, assert, screenshot, eval)")
+	case 1:
+	default:
+		return fmt.Errorf("more than one action: %s", strings.Join(actions, ", "))
+	}
+
+	switch {
+	case s.Click != nil && s.Click.Selector == "":
+		return fmt.Errorf("click needs a selector")
+	case s.Type != nil && s.Type.Selector == "":
+		return fmt.Errorf("type needs a selector")
+	case s.Press != nil && s.Press.Keys == "":
+		return fmt.Errorf("press needs keys")
+	case s.Wait != nil && s.Wait.Selector == "" && s.Wait.URL == "" && s.Wait.Time <= 0:
+		return fmt.Errorf("wait needs a selector, url or time")
+	case s.Assert != nil && *s.Assert == (AssertStep{}):
+		return fmt.Errorf("assert needs at least one check")
+	case s.Assert != nil && s.Assert.Selector == "" &&
+		(s.Assert.Text != "" || s.Assert.Equals != "" || s.Assert.Value != "" || s.Assert.Visible != nil):
+		return fmt.Errorf("assert on text, equals, value or visible needs a selector")
+	}
+	return nil
+}
+
+// Load reads a script from a YAML or JSON file. ${VAR} and ${VAR:-default}
+// in string values are replaced using lookup (normally os.LookupEnv).
+func Load(path string, lookup func(string) (string, bool)) (*Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script: %w", err)
+	}
+	return Parse(data, lookup)
+}
+
+// Parse parses a YAML or JSON script. See Load.
+func Parse(data []byte, lookup func(string) (string, bool)) (*Script, error) {
+	// JSON is valid YAML, so one parser handles both
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse script: %w", err)
+	}
+
+	missing := map[string]bool{}
+	doc = expandVariables(doc, lookup, missing)
+	if len(missing) > 0 {
+		names := make([]string, 0, len(missing))
+		for name := range missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("undefined variables: %s (set them in the environment or use ${NAME:-default})", strings.Join(names, ", "))
+	}
+
+	// A bare list of steps
+	if list, ok := doc.([]interface{}); ok {
+		doc = map[string]interface{}{"steps": list}
+	}
+
+	normalized, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse script: %w", err)
+	}
+
+	var script Script
+	if err := strictUnmarshal(normalized, &script); err != nil {
+		return nil, fmt.Errorf("invalid script: %w", err)
+	}
+	if len(script.Steps) == 0 {
+		return nil, fmt.Errorf("invalid script: no steps")
+	}
+	for i := range script.Steps {
+		if err := script.Steps[i].validate(); err != nil {
+			return nil, fmt.Errorf("invalid step %d: %w", i+1, err)
+		}
+	}
+
+	return &script, nil
+}
+
+// variablePattern matches ${NAME} and ${NAME:-default}.
+var variablePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// expandVariables replaces variables in every string of a parsed document,
+// recording names that are neither set nor have a default in missing.
+func expandVariables(v interface{}, lookup func(string) (string, bool), missing map[string]bool) interface{} {
+	switch val := v.(type) {
+	case string:
+		return variablePattern.ReplaceAllStringFunc(val, func(m string) string {
+			parts := variablePattern.FindStringSubmatch(m)
+			if value, ok := lookup(parts[1]); ok {
+				return value
+			}
+			if strings.Contains(m, ":-") {
+				return parts[2]
+			}
+			missing[parts[1]] = true
+			return m
+		})
+	case []interface{}:
+		for i := range val {
+			val[i] = expandVariables(val[i], lookup, missing)
+		}
+		return val
+	case map[string]interface{}:
+		for k := range val {
+			val[k] = expandVariables(val[k], lookup, missing)
+		}
+		return val
+	default:
+		return v
+	}
+}
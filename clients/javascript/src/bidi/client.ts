@@ -30,6 +30,18 @@ export class BiDiClient {
   }
 
   private handleResponse(response: BiDiResponse): void {
+    // An error without an id ends the session, e.g. when the browser crashed
+    if (response.id === null) {
+      const error = response.error
+        ? new Error(`${response.error.error}: ${response.error.message}`)
+        : new Error('Connection closed');
+      for (const [id, pending] of this.pendingCommands) {
+        pending.reject(error);
+        this.pendingCommands.delete(id);
+      }
+      return;
+    }
+
     const pending = this.pendingCommands.get(response.id);
     if (!pending) {
       console.warn('Received response for unknown command:', response.id);
@@ -7,7 +7,7 @@ export interface BiDiCommand {
 }
 
 export interface BiDiResponse {
-  id: number;
+  id: number | null; // null for errors that belong to no command
   type: 'success' | 'error';
   result?: unknown;
   error?: BiDiError;
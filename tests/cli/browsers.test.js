@@ -0,0 +1,115 @@
+/**
+ * CLI Tests: browsers list/prune and --chrome-version
+ * Tests version selection against a fake Chrome for Testing cache
+ * (XDG_CACHE_HOME only moves the cache on Linux)
+ */
+
+const { test, describe, before, after } = require('node:test');
+const assert = require('node:assert');
+const { spawnSync } = require('node:child_process');
+const fs = require('node:fs');
+const os = require('node:os');
+const path = require('node:path');
+
+const CLICKER = path.join(__dirname, '../../clicker/bin/clicker');
+const skip = process.platform !== 'linux' && 'cache location is only configurable on Linux';
+
+describe('CLI: browsers', { skip }, () => {
+  let cacheHome;
+
+  function clicker(args, env = {}) {
+    const result = spawnSync(CLICKER, [...args, '--json'], {
+      encoding: 'utf-8',
+      timeout: 30000,
+      env: { ...process.env, XDG_CACHE_HOME: cacheHome, VIBIUM_CHROME_VERSION: '', ...env },
+    });
+    return { status: result.status, doc: JSON.parse(result.stdout) };
+  }
+
+  before(() => {
+    cacheHome = fs.mkdtempSync(path.join(os.tmpdir(), 'clicker-cache-'));
+    for (const version of ['99.0.4844.51', '131.0.6778.85', '131.0.6778.204', '132.0.6834.83']) {
+      const dir = path.join(cacheHome, 'vibium', 'chrome-for-testing', version);
+      fs.mkdirSync(path.join(dir, 'chrome-linux64'), { recursive: true });
+      fs.mkdirSync(path.join(dir, 'chromedriver-linux64'), { recursive: true });
+      fs.writeFileSync(path.join(dir, 'chrome-linux64', 'chrome'), '');
+      fs.writeFileSync(path.join(dir, 'chromedriver-linux64', 'chromedriver'), '');
+      // 131.0.6778.204 was installed before completion markers existed
+      if (version !== '131.0.6778.204') fs.writeFileSync(path.join(dir, '.installed'), version);
+    }
+  });
+
+  after(() => {
+    fs.rmSync(cacheHome, { recursive: true, force: true });
+  });
+
+  test('list sorts versions numerically and marks the newest as default', () => {
+    const { doc } = clicker(['browsers', 'list']);
+    assert.deepStrictEqual(
+      doc.browsers.map((b) => b.version),
+      ['132.0.6834.83', '131.0.6778.204', '131.0.6778.85', '99.0.4844.51']
+    );
+    assert.strictEqual(doc.browsers.find((b) => b.default).version, '132.0.6834.83');
+  });
+
+  test('a complete install without the completion marker is still found', () => {
+    const { doc } = clicker(['paths', '--chrome-version', '131.0.6778.204']);
+    assert.match(doc.chrome, /131\.0\.6778\.204/);
+    assert.match(doc.chromedriver, /131\.0\.6778\.204/);
+  });
+
+  test('--chrome-version and VIBIUM_CHROME_VERSION pick an installed version', () => {
+    let { doc } = clicker(['paths', '--chrome-version', '131']);
+    assert.match(doc.chrome, /131\.0\.6778\.204/, 'A prefix should pick the newest match');
+
+    ({ doc } = clicker(['paths'], { VIBIUM_CHROME_VERSION: '131.0.6778.85' }));
+    assert.match(doc.chrome, /131\.0\.6778\.85/);
+    assert.match(doc.chromedriver, /131\.0\.6778\.85/, 'Should use the chromedriver of the same version');
+  });
+
+  test('a pinned version that is not installed fails to launch', () => {
+    const { status, doc } = clicker(['navigate', 'https://example.com', '--chrome-version', '120']);
+    assert.strictEqual(status, 10);
+    assert.match(doc.error.message, /clicker install --version 120/);
+  });
+
+  test('prune keeps the newest versions and the pinned one', () => {
+    const { doc } = clicker(['browsers', 'prune', '--keep', '2'], { VIBIUM_CHROME_VERSION: '99' });
+    assert.deepStrictEqual(doc.removed, ['131.0.6778.85']);
+
+    const list = clicker(['browsers', 'list']).doc;
+    assert.deepStrictEqual(
+      list.browsers.map((b) => b.version),
+      ['132.0.6834.83', '131.0.6778.204', '99.0.4844.51']
+    );
+  });
+
+  test('prune removes partial installs and interrupted downloads', () => {
+    const cft = path.join(cacheHome, 'vibium', 'chrome-for-testing');
+    fs.mkdirSync(path.join(cft, '133.0.6943.53', 'chrome-linux64'), { recursive: true });
+    fs.writeFileSync(path.join(cft, '133.0.6943.53', 'chrome-linux64', 'chrome'), '');
+    fs.mkdirSync(path.join(cft, '.downloads'), { recursive: true });
+    fs.writeFileSync(path.join(cft, '.downloads', '133.0.6943.53-linux64-chrome-linux64.zip.part'), 'partial');
+    fs.mkdirSync(path.join(cft, '.tmp-133.0.6943.53'));
+
+    const { doc } = clicker(['browsers', 'prune', '--keep', '10']);
+    assert.deepStrictEqual(doc.removed, ['133.0.6943.53']);
+    assert.ok(!fs.existsSync(path.join(cft, '.downloads')));
+    assert.ok(!fs.existsSync(path.join(cft, '.tmp-133.0.6943.53')));
+    assert.strictEqual(clicker(['browsers', 'list']).doc.browsers.length, 3);
+  });
+
+  test('prune keeps the version --chrome-version pins', () => {
+    const { doc } = clicker(['browsers', 'prune', '--keep', '1', '--chrome-version', '99']);
+    assert.deepStrictEqual(doc.removed, ['131.0.6778.204']);
+
+    const list = clicker(['browsers', 'list']).doc;
+    assert.deepStrictEqual(list.browsers.map((b) => b.version), ['132.0.6834.83', '99.0.4844.51']);
+  });
+
+  test('install rejects --version with --channel', () => {
+    const { status, doc } = clicker(['install', '--version', '131', '--channel', 'beta']);
+    assert.strictEqual(status, 2);
+    assert.strictEqual(doc.error.code, 'invalid_argument');
+  });
+});
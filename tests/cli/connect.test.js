@@ -0,0 +1,104 @@
+/**
+ * CLI Tests: --connect
+ * Tests attaching to an existing WebDriver endpoint, using a stand-in chromedriver
+ */
+
+const { test, describe, before, after } = require('node:test');
+const assert = require('node:assert');
+const { run, startDriver } = require('./stand-in-driver');
+
+describe('CLI: --connect', () => {
+  let driver;
+
+  before(async () => {
+    // A BiDi endpoint where every command succeeds but screenshots
+    driver = await startDriver((method, params) => {
+      if (method === 'browsingContext.captureScreenshot') {
+        return { type: 'error', error: 'unknown error', message: 'stand-in cannot capture' };
+      }
+      if (method === 'browsingContext.navigate') {
+        return { type: 'success', result: { navigation: null, url: params.url } };
+      }
+      if (method === 'browsingContext.getTree') {
+        return { type: 'success', result: { contexts: [{ context: 'tab-1', url: 'about:blank', children: [] }] } };
+      }
+      return { type: 'success', result: {} };
+    });
+  });
+
+  after(() => {
+    driver.close();
+  });
+
+  test('creates a session on the driver with the extra capabilities and ends it', async () => {
+    const result = await run([
+      'navigate', 'https://example.com',
+      '--connect', driver.url,
+      '--capabilities', '{"browserVersion":"stable"}',
+      '--json',
+    ]);
+
+    // The stand-in has no BiDi endpoint, so connecting fails after the session was created
+    assert.strictEqual(result.status, 9, 'Should exit with the connection_failed status');
+    assert.strictEqual(JSON.parse(result.stdout).error.code, 'connection_failed');
+
+    const create = driver.requests.find((r) => r.method === 'POST' && r.url === '/session');
+    assert.ok(create, 'Should create a session');
+    const caps = create.body.capabilities.alwaysMatch;
+    assert.strictEqual(caps.webSocketUrl, true, 'Should ask for BiDi');
+    assert.strictEqual(caps.browserVersion, 'stable', 'Should pass extra capabilities');
+    assert.ok(!caps['goog:chromeOptions'].binary, 'Should leave the binary to the driver');
+    assert.ok(
+      driver.requests.some((r) => r.method === 'DELETE' && r.url === '/session/stand-in'),
+      'Should end the session it created'
+    );
+  });
+
+  test('ends the session when a command fails after connecting', async () => {
+    driver.requests.length = 0;
+    driver.bidi = true;
+    const result = await run(['screenshot', 'https://example.com', '--connect', driver.url, '--json']);
+    driver.bidi = false;
+
+    assert.notStrictEqual(result.status, 0);
+    assert.strictEqual(JSON.parse(result.stdout).error.step, 'capturing screenshot');
+    assert.ok(
+      driver.requests.some((r) => r.method === 'DELETE' && r.url === '/session/stand-in'),
+      'Should end the remote session on failure'
+    );
+  });
+
+  test('passes launch customization in the capabilities', async () => {
+    driver.requests.length = 0;
+    await run([
+      'navigate', 'https://example.com',
+      '--connect', driver.url,
+      '--proxy-server', 'http://proxy.corp:3128',
+      '--proxy-bypass', 'localhost,*.internal',
+      '--user-data-dir', '/profiles/jobs',
+      '--browser-arg=--start-maximized',
+      '--ignore-default-arg=--disable-sync',
+      '--json',
+    ]);
+
+    const create = driver.requests.find((r) => r.method === 'POST' && r.url === '/session');
+    const args = create.body.capabilities.alwaysMatch['goog:chromeOptions'].args;
+    assert.ok(args.includes('--proxy-server=http://proxy.corp:3128'));
+    assert.ok(args.includes('--proxy-bypass-list=localhost;*.internal'));
+    assert.ok(args.includes('--user-data-dir=/profiles/jobs'), 'Should pass the profile path as is');
+    assert.ok(args.includes('--start-maximized'), 'Should add extra arguments');
+    assert.ok(!args.includes('--disable-sync'), 'Should drop ignored default arguments');
+  });
+
+  test('rejects invalid proxy servers', async () => {
+    const result = await run(['navigate', 'https://example.com', '--proxy-server', 'ftp://proxy:21', '--json']);
+    assert.strictEqual(result.status, 2);
+    assert.strictEqual(JSON.parse(result.stdout).error.code, 'invalid_argument');
+  });
+
+  test('rejects URLs that are neither WebDriver nor BiDi', async () => {
+    const result = await run(['navigate', 'https://example.com', '--connect', 'ftp://example.com', '--json']);
+    assert.strictEqual(result.status, 2);
+    assert.strictEqual(JSON.parse(result.stdout).error.code, 'invalid_argument');
+  });
+});
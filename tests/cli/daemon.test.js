@@ -0,0 +1,73 @@
+/**
+ * CLI Tests: Daemon Sessions
+ * Tests `clicker daemon` and attaching commands with --session
+ */
+
+const { test, describe, before, after } = require('node:test');
+const assert = require('node:assert');
+const { execSync } = require('node:child_process');
+const fs = require('node:fs');
+const os = require('node:os');
+const path = require('node:path');
+
+const CLICKER = path.join(__dirname, '../../clicker/bin/clicker');
+const SESSION = `cli-test-${process.pid}`;
+
+function clicker(args, timeout = 30000) {
+  return execSync(`${CLICKER} ${args}`, { encoding: 'utf-8', timeout, stdio: 'pipe' });
+}
+
+describe('CLI: Daemon', () => {
+  before(() => {
+    clicker(`daemon start --session ${SESSION} --headless`, 90000);
+  });
+
+  after(() => {
+    try {
+      clicker(`daemon stop --session ${SESSION}`);
+    } catch {
+      // Already stopped
+    }
+  });
+
+  test('commands attach to the session and keep its page', () => {
+    clicker(`navigate --session ${SESSION} https://the-internet.herokuapp.com/inputs`);
+
+    const typed = clicker(`type --session ${SESSION} "input" "42"`);
+    assert.match(typed, /value is now: 42/, 'Should type into the page opened by navigate');
+
+    const value = clicker(`eval --session ${SESSION} "document.querySelector('input').value"`);
+    assert.match(value, /Result: 42/, 'A later command should see the same page state');
+  });
+
+  test('screenshot and click leave out the URL argument', () => {
+    clicker(`navigate --session ${SESSION} https://example.com`);
+
+    const dir = fs.mkdtempSync(path.join(os.tmpdir(), 'vibium-daemon-'));
+    const file = path.join(dir, 'page.png');
+    try {
+      clicker(`screenshot --session ${SESSION} -o ${file}`);
+      assert.ok(fs.statSync(file).size > 0, 'Should save a screenshot');
+    } finally {
+      fs.rmSync(dir, { recursive: true, force: true });
+    }
+
+    const result = clicker(`click --session ${SESSION} "a"`);
+    assert.match(result, /iana\.org/, 'Should follow the link in the session browser');
+  });
+
+  test('status lists the running session', () => {
+    const list = clicker('daemon status');
+    assert.match(list, new RegExp(SESSION), 'Should list the session');
+
+    const status = clicker(`daemon status --session ${SESSION}`);
+    assert.match(status, /Open tabs: 1/, 'Should show the open tab');
+  });
+
+  test('commands fail when no daemon runs for the session', () => {
+    assert.throws(
+      () => clicker('eval --session no-such-session "1"'),
+      /no daemon running for session "no-such-session"/
+    );
+  });
+});
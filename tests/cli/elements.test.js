@@ -1,11 +1,13 @@
 /**
- * CLI Tests: Element Finding, Click, and Type
+ * CLI Tests: Element Finding, Click, Type, Upload, Download, and Mouse/Keyboard Input
  * Tests the clicker binary directly
  */
 
 const { test, describe } = require('node:test');
 const assert = require('node:assert');
 const { execSync } = require('node:child_process');
+const fs = require('node:fs');
+const os = require('node:os');
 const path = require('node:path');
 
 const CLICKER = path.join(__dirname, '../../clicker/bin/clicker');
@@ -40,4 +42,143 @@ describe('CLI: Elements', () => {
     );
     assert.match(result, /12345/, 'Should show typed text in result');
   });
+
+  test('type command with --pace types the full text', () => {
+    const result = execSync(
+      `${CLICKER} type https://the-internet.herokuapp.com/inputs "input" "12345" --pace --pace-seed 7 --pace-key 20ms`,
+      {
+        encoding: 'utf-8',
+        timeout: 30000,
+      }
+    );
+    assert.match(result, /seed 7/, 'Should report the pacing seed');
+    assert.match(result, /12345/, 'Should show typed text in result');
+  });
+
+  test('fill command replaces existing content', () => {
+    const result = execSync(
+      `${CLICKER} fill "data:text/html,<input id=q value=old>" "#q" "new"`,
+      {
+        encoding: 'utf-8',
+        timeout: 30000,
+      }
+    );
+    assert.match(result, /Value is now: new$/m, 'Should replace the old value');
+  });
+
+  test('select command selects an option by label', () => {
+    const result = execSync(
+      `${CLICKER} select https://the-internet.herokuapp.com/dropdown "#dropdown" "Option 2" --by label`,
+      {
+        encoding: 'utf-8',
+        timeout: 30000,
+      }
+    );
+    assert.match(result, /Selected: 2/, 'Should select the option with value 2');
+  });
+
+  test('check command checks a checkbox', () => {
+    const result = execSync(
+      `${CLICKER} check https://the-internet.herokuapp.com/checkboxes "#checkboxes input"`,
+      {
+        encoding: 'utf-8',
+        timeout: 30000,
+      }
+    );
+    assert.match(result, /Checked: true/, 'Should end up checked');
+  });
+
+  test('upload command sets files on a file input', () => {
+    const file = path.join(os.tmpdir(), `vibium-upload-${Date.now()}.txt`);
+    fs.writeFileSync(file, 'hello from vibium');
+    try {
+      const result = execSync(
+        `${CLICKER} upload https://the-internet.herokuapp.com/upload "#file-upload" ${file}`,
+        {
+          encoding: 'utf-8',
+          timeout: 30000,
+        }
+      );
+      assert.match(result, new RegExp(path.basename(file)), 'Should show selected file name');
+    } finally {
+      fs.rmSync(file, { force: true });
+    }
+  });
+
+  test('click --download saves the file to --download-dir', () => {
+    const dir = fs.mkdtempSync(path.join(os.tmpdir(), 'vibium-downloads-'));
+    try {
+      const result = execSync(
+        `${CLICKER} click https://the-internet.herokuapp.com/download "a[href^='download/']" --headless --download --download-dir ${dir}`,
+        {
+          encoding: 'utf-8',
+          timeout: 60000,
+        }
+      );
+      assert.match(result, /Download complete/, 'Should report the download');
+      assert.ok(fs.readdirSync(dir).length > 0, 'Should save a file in the download directory');
+    } finally {
+      fs.rmSync(dir, { recursive: true, force: true });
+    }
+  });
+
+  test('type command rejects file inputs', () => {
+    assert.throws(
+      () => execSync(
+        `${CLICKER} type https://the-internet.herokuapp.com/upload "#file-upload" "x" --timeout 2s`,
+        {
+          encoding: 'utf-8',
+          timeout: 30000,
+          stdio: 'pipe',
+        }
+      ),
+      /Editable/,
+      'Should fail the Editable check'
+    );
+  });
+
+  test('press command sends key chords to the focused element', () => {
+    const result = execSync(
+      `${CLICKER} press https://the-internet.herokuapp.com/key_presses "Shift+A" --selector "#target"`,
+      {
+        encoding: 'utf-8',
+        timeout: 30000,
+      }
+    );
+    assert.match(result, /Keys pressed/, 'Should report the key presses');
+  });
+
+  test('hover command reveals hover content', () => {
+    const result = execSync(
+      `${CLICKER} hover https://the-internet.herokuapp.com/hovers ".figure"`,
+      {
+        encoding: 'utf-8',
+        timeout: 30000,
+      }
+    );
+    assert.match(result, /Hovering over element/, 'Should hover the element');
+  });
+
+  test('scroll command moves the page', () => {
+    const result = execSync(
+      `${CLICKER} scroll https://the-internet.herokuapp.com/large --dy 400 --headless`,
+      {
+        encoding: 'utf-8',
+        timeout: 30000,
+      }
+    );
+    assert.match(result, /Page position: 0,[1-9]/, 'Should scroll down');
+  });
+
+  test('click rejects an unknown mouse button', () => {
+    assert.throws(
+      () => execSync(`${CLICKER} click https://example.com "a" --button side`, {
+        encoding: 'utf-8',
+        timeout: 30000,
+        stdio: 'pipe',
+      }),
+      /unknown mouse button/,
+      'Should validate --button'
+    );
+  });
 });
@@ -0,0 +1,49 @@
+/**
+ * CLI Tests: --browser
+ * Tests the Firefox capabilities against a stand-in geckodriver, and browser validation
+ */
+
+const { test, describe, before, after } = require('node:test');
+const assert = require('node:assert');
+const { run, startDriver } = require('./stand-in-driver');
+
+describe('CLI: --browser', () => {
+  let driver;
+
+  before(async () => {
+    driver = await startDriver();
+  });
+
+  after(() => {
+    driver.close();
+  });
+
+  test('asks for Firefox with moz:firefoxOptions', async () => {
+    await run([
+      'navigate', 'https://example.com',
+      '--connect', driver.url,
+      '--browser', 'firefox',
+      '--headless',
+      '--locale', 'de-DE',
+      '--json',
+    ]);
+
+    const create = driver.requests.find((r) => r.method === 'POST' && r.url === '/session');
+    assert.ok(create, 'Should create a session');
+    const caps = create.body.capabilities.alwaysMatch;
+    assert.strictEqual(caps.browserName, 'firefox');
+    assert.strictEqual(caps.webSocketUrl, true, 'Should ask for BiDi');
+    assert.ok(!caps['goog:chromeOptions'], 'Should not send Chrome options');
+    const firefox = caps['moz:firefoxOptions'];
+    assert.deepStrictEqual(firefox.args, ['-headless']);
+    assert.strictEqual(firefox.prefs['intl.accept_languages'], 'de-DE');
+  });
+
+  test('rejects unknown browsers', async () => {
+    const result = await run(['navigate', 'https://example.com', '--browser', 'netscape', '--json']);
+    assert.strictEqual(result.status, 2);
+    const doc = JSON.parse(result.stdout);
+    assert.strictEqual(doc.error.code, 'invalid_argument');
+    assert.match(doc.error.message, /chrome or firefox/);
+  });
+});
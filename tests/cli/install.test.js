@@ -0,0 +1,260 @@
+/**
+ * CLI Tests: install from a mirror, from local zips, checksum checks,
+ * resumed downloads and parallel installs
+ * Serves tiny fake Chrome for Testing zips from a local HTTP server
+ * (XDG_CACHE_HOME only moves the cache on Linux)
+ */
+
+const { test, describe, before, after } = require('node:test');
+const assert = require('node:assert');
+const { spawn } = require('node:child_process');
+const crypto = require('node:crypto');
+const fs = require('node:fs');
+const http = require('node:http');
+const os = require('node:os');
+const path = require('node:path');
+
+const CLICKER = path.join(__dirname, '../../clicker/bin/clicker');
+const VERSION = '131.0.6778.85';
+const skip = process.platform !== 'linux' && 'cache location is only configurable on Linux';
+
+const CRC_TABLE = Array.from({ length: 256 }, (_, n) => {
+  let c = n;
+  for (let k = 0; k < 8; k++) c = c & 1 ? 0xedb88320 ^ (c >>> 1) : c >>> 1;
+  return c >>> 0;
+});
+
+function crc32(buf) {
+  let c = 0xffffffff;
+  for (const b of buf) c = CRC_TABLE[(c ^ b) & 0xff] ^ (c >>> 8);
+  return (c ^ 0xffffffff) >>> 0;
+}
+
+/**
+ * Builds an uncompressed zip. entries: [{ name, data, mode }], where mode is
+ * the Unix mode (0o120777 makes data the symlink target).
+ */
+function makeZip(entries) {
+  const locals = [];
+  const centrals = [];
+  let offset = 0;
+  for (const { name, data, mode = 0o100755 } of entries) {
+    const nameBuf = Buffer.from(name);
+    const dataBuf = Buffer.from(data);
+    const crc = crc32(dataBuf);
+
+    const local = Buffer.alloc(30);
+    local.writeUInt32LE(0x04034b50, 0);
+    local.writeUInt16LE(10, 4);
+    local.writeUInt32LE(crc, 14);
+    local.writeUInt32LE(dataBuf.length, 18);
+    local.writeUInt32LE(dataBuf.length, 22);
+    local.writeUInt16LE(nameBuf.length, 26);
+    locals.push(local, nameBuf, dataBuf);
+
+    const central = Buffer.alloc(46);
+    central.writeUInt32LE(0x02014b50, 0);
+    central.writeUInt16LE(0x031e, 4); // made by Unix, so the mode is used
+    central.writeUInt16LE(10, 6);
+    central.writeUInt32LE(crc, 16);
+    central.writeUInt32LE(dataBuf.length, 20);
+    central.writeUInt32LE(dataBuf.length, 24);
+    central.writeUInt16LE(nameBuf.length, 28);
+    central.writeUInt32LE((mode << 16) >>> 0, 38);
+    central.writeUInt32LE(offset, 42);
+    centrals.push(central, nameBuf);
+
+    offset += local.length + nameBuf.length + dataBuf.length;
+  }
+
+  const dir = Buffer.concat(centrals);
+  const end = Buffer.alloc(22);
+  end.writeUInt32LE(0x06054b50, 0);
+  end.writeUInt16LE(entries.length, 8);
+  end.writeUInt16LE(entries.length, 10);
+  end.writeUInt32LE(dir.length, 12);
+  end.writeUInt32LE(offset, 16);
+  return Buffer.concat([...locals, dir, end]);
+}
+
+const sha256 = (buf) => crypto.createHash('sha256').update(buf).digest('hex');
+
+describe('CLI: install', { skip }, () => {
+  let tmp, server, mirror;
+  const files = {};
+  const requests = [];
+  const cut = new Set(); // paths whose next response is cut off halfway
+  const slow = new Set(); // paths served after a delay
+
+  function clicker(args, env = {}) {
+    return new Promise((resolve) => {
+      const proc = spawn(CLICKER, [...args, '--json'], {
+        env: { ...process.env, XDG_CACHE_HOME: path.join(tmp, 'cache'), VIBIUM_SKIP_BROWSER_DOWNLOAD: '', ...env },
+      });
+      let stdout = '';
+      let stderr = '';
+      proc.stdout.on('data', (d) => (stdout += d));
+      proc.stderr.on('data', (d) => (stderr += d));
+      proc.on('close', (status) => resolve({ status, doc: JSON.parse(stdout), stderr }));
+    });
+  }
+
+  const versionDir = (version = VERSION) => path.join(tmp, 'cache', 'vibium', 'chrome-for-testing', version);
+
+  before(async () => {
+    tmp = fs.mkdtempSync(path.join(os.tmpdir(), 'clicker-install-'));
+    files[`/${VERSION}/linux64/chrome-linux64.zip`] = makeZip([
+      { name: 'chrome-linux64/chrome', data: '#!/bin/sh\n' },
+      { name: 'chrome-linux64/lib/libfake.so', data: 'lib' },
+      { name: 'chrome-linux64/libfake.so', data: 'lib/libfake.so', mode: 0o120777 },
+    ]);
+    files[`/${VERSION}/linux64/chromedriver-linux64.zip`] = makeZip([
+      { name: 'chromedriver-linux64/chromedriver', data: '#!/bin/sh\n' },
+    ]);
+    files['/1.0.0.1/linux64/chrome-linux64.zip'] = makeZip([
+      { name: '../../escaped', data: 'slip' },
+    ]);
+    files['/1.0.0.2/linux64/chrome-linux64.zip'] = makeZip([
+      { name: 'chrome-linux64/out', data: '../../..', mode: 0o120777 },
+      { name: 'chrome-linux64/out/escaped', data: 'slip' },
+    ]);
+    for (const version of ['1.0.0.1', '1.0.0.2', '131.0.6778.86', '131.0.6778.87']) {
+      files[`/${version}/linux64/chromedriver-linux64.zip`] = files[`/${VERSION}/linux64/chromedriver-linux64.zip`];
+    }
+    for (const version of ['131.0.6778.86', '131.0.6778.87']) {
+      files[`/${version}/linux64/chrome-linux64.zip`] = files[`/${VERSION}/linux64/chrome-linux64.zip`];
+    }
+
+    fs.writeFileSync(
+      path.join(tmp, 'SHA256SUMS'),
+      Object.entries(files)
+        .map(([name, data]) => `${sha256(data)}  ${name.slice(1)}\n`)
+        .join('')
+    );
+
+    server = http.createServer((req, res) => {
+      requests.push({ url: req.url, range: req.headers.range });
+      const data = files[req.url];
+      if (!data) {
+        res.writeHead(404);
+        return res.end();
+      }
+
+      const start = Number((/^bytes=(\d+)-$/.exec(req.headers.range || '') || [])[1] || 0);
+      const headers = { 'Content-Length': data.length - start };
+      if (start) headers['Content-Range'] = `bytes ${start}-${data.length - 1}/${data.length}`;
+      setTimeout(() => {
+        res.writeHead(start ? 206 : 200, headers);
+        if (cut.delete(req.url)) {
+          res.write(data.subarray(start, start + Math.floor((data.length - start) / 2)), () => res.socket.destroy());
+        } else {
+          res.end(data.subarray(start));
+        }
+      }, slow.has(req.url) ? 300 : 0);
+    });
+    await new Promise((resolve) => server.listen(0, '127.0.0.1', resolve));
+    mirror = `http://127.0.0.1:${server.address().port}`;
+  });
+
+  after(() => {
+    server.close();
+    fs.rmSync(tmp, { recursive: true, force: true });
+  });
+
+  test('installs from a mirror and verifies checksums', async () => {
+    const { status, doc } = await clicker([
+      'install', '--version', VERSION, '--mirror', mirror, '--checksums', path.join(tmp, 'SHA256SUMS'),
+    ]);
+    assert.strictEqual(status, 0, JSON.stringify(doc));
+    assert.strictEqual(doc.version, VERSION);
+    assert.ok(fs.existsSync(doc.chromedriver));
+    assert.strictEqual(fs.readlinkSync(path.join(versionDir(), 'chrome-linux64', 'libfake.so')), 'lib/libfake.so');
+    fs.rmSync(versionDir(), { recursive: true });
+  });
+
+  test('a checksum mismatch installs nothing', async () => {
+    const bad = path.join(tmp, 'BADSUMS');
+    fs.writeFileSync(bad, `${'0'.repeat(64)}  chrome-linux64.zip\n${'0'.repeat(64)}  chromedriver-linux64.zip\n`);
+    const { status, doc } = await clicker(['install', '--version', VERSION, '--mirror', mirror, '--checksums', bad]);
+    assert.notStrictEqual(status, 0);
+    assert.match(doc.error.message, /checksum mismatch/);
+    assert.ok(!fs.existsSync(versionDir()), 'Should remove the partial install');
+  });
+
+  test('rejects zip slip paths and symlinks escaping the install', async () => {
+    for (const version of ['1.0.0.1', '1.0.0.2']) {
+      const { status, doc } = await clicker(['install', '--version', version, '--mirror', mirror]);
+      assert.notStrictEqual(status, 0);
+      assert.match(doc.error.message, /invalid (file path|symlink) in zip/);
+    }
+    assert.ok(!fs.existsSync(path.join(tmp, 'cache', 'vibium', 'escaped')));
+    assert.ok(!fs.existsSync(path.join(tmp, 'cache', 'vibium', 'chrome-for-testing', 'escaped')));
+  });
+
+  test('resumes an interrupted download and reports progress', async () => {
+    const version = '131.0.6778.86';
+    const url = `/${version}/linux64/chrome-linux64.zip`;
+    cut.add(url);
+
+    const first = await clicker(['install', '--version', version, '--mirror', mirror]);
+    assert.notStrictEqual(first.status, 0);
+    assert.match(first.doc.error.message, /resume/);
+    assert.ok(!fs.existsSync(versionDir(version)), 'An interrupted install should not look installed');
+
+    const second = await clicker(['install', '--version', version, '--mirror', mirror, '--checksums', path.join(tmp, 'SHA256SUMS')]);
+    assert.strictEqual(second.status, 0, JSON.stringify(second.doc));
+    const resumed = requests.filter((r) => r.url === url && r.range);
+    assert.strictEqual(resumed.length, 1, 'Should resume with a Range request');
+
+    const events = second.stderr.split('\n').filter((l) => l.startsWith('{')).map((l) => JSON.parse(l));
+    const chrome = events.filter((e) => e.event === 'progress' && e.stage === 'downloading' && e.name === 'chrome');
+    assert.ok(chrome[0].resumed > 0, 'Should report the resumed bytes');
+    assert.strictEqual(chrome.at(-1).bytes, files[url].length);
+    assert.ok(fs.existsSync(path.join(versionDir(version), '.installed')));
+  });
+
+  test('parallel installs of a version download it once', async () => {
+    const version = '131.0.6778.87';
+    const url = `/${version}/linux64/chrome-linux64.zip`;
+    slow.add(url);
+
+    const results = await Promise.all([1, 2].map(() => clicker(['install', '--version', version, '--mirror', mirror])));
+    for (const { status, doc } of results) {
+      assert.strictEqual(status, 0, JSON.stringify(doc));
+    }
+    assert.deepStrictEqual(results.map((r) => r.doc.alreadyInstalled).sort(), [false, true]);
+    assert.strictEqual(requests.filter((r) => r.url === url).length, 1);
+  });
+
+  test('a partial install is ignored and repaired', async () => {
+    const dir = path.join(versionDir(), 'chrome-linux64');
+    fs.mkdirSync(dir, { recursive: true });
+    fs.writeFileSync(path.join(dir, 'chrome'), '');
+
+    const list = await clicker(['browsers', 'list']);
+    assert.ok(!list.doc.browsers.some((b) => b.version === VERSION), 'A version without the marker is not installed');
+
+    const { status, doc } = await clicker(['install', '--version', VERSION, '--mirror', mirror]);
+    assert.strictEqual(status, 0, JSON.stringify(doc));
+    assert.strictEqual(doc.alreadyInstalled, false);
+    assert.ok(fs.existsSync(path.join(versionDir(), '.installed')));
+    fs.rmSync(versionDir(), { recursive: true });
+  });
+
+  test('installs from local zips without network', async () => {
+    const chromeZip = path.join(tmp, 'chrome-linux64.zip');
+    const driverZip = path.join(tmp, 'chromedriver-linux64.zip');
+    fs.writeFileSync(chromeZip, files[`/${VERSION}/linux64/chrome-linux64.zip`]);
+    fs.writeFileSync(driverZip, files[`/${VERSION}/linux64/chromedriver-linux64.zip`]);
+
+    const { status, doc } = await clicker(
+      ['install', '--version', VERSION, '--from-zip', chromeZip, '--driver-zip', driverZip, '--checksums', path.join(tmp, 'SHA256SUMS')],
+      { VIBIUM_SKIP_BROWSER_DOWNLOAD: '1', HTTPS_PROXY: 'http://127.0.0.1:1' }
+    );
+    assert.strictEqual(status, 0, JSON.stringify(doc));
+    assert.ok(fs.existsSync(doc.chrome));
+
+    const missing = await clicker(['install', '--from-zip', chromeZip, '--driver-zip', driverZip]);
+    assert.strictEqual(missing.status, 2, '--from-zip needs --version');
+  });
+});
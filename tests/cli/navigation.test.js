@@ -46,6 +46,89 @@ describe('CLI: Navigation', () => {
     }
   });
 
+  test('screenshot honours --viewport and --dpr', () => {
+    const outFile = `/tmp/vibium-test-viewport-${Date.now()}.png`;
+    try {
+      execSync(`${CLICKER} screenshot https://example.com -o ${outFile} --headless --viewport 800x600 --dpr 2`, {
+        encoding: 'utf-8',
+        timeout: 30000,
+      });
+
+      // PNG IHDR: width at byte 16, height at byte 20 (big-endian)
+      const buffer = fs.readFileSync(outFile);
+      assert.strictEqual(buffer.readUInt32BE(16), 1600, 'Width should be 800 * dpr');
+      assert.strictEqual(buffer.readUInt32BE(20), 1200, 'Height should be 600 * dpr');
+    } finally {
+      if (fs.existsSync(outFile)) {
+        fs.unlinkSync(outFile);
+      }
+    }
+  });
+
+  test('screenshot --full-page writes JPEG when output ends in .jpg', () => {
+    const outFile = `/tmp/vibium-test-fullpage-${Date.now()}.jpg`;
+    try {
+      execSync(`${CLICKER} screenshot https://example.com -o ${outFile} --headless --full-page --quality 80`, {
+        encoding: 'utf-8',
+        timeout: 30000,
+      });
+
+      // JPEG magic bytes: FF D8 FF
+      const buffer = fs.readFileSync(outFile);
+      assert.strictEqual(buffer[0], 0xFF, 'Should be valid JPEG (byte 0)');
+      assert.strictEqual(buffer[1], 0xD8, 'Should be valid JPEG (byte 1)');
+      assert.strictEqual(buffer[2], 0xFF, 'Should be valid JPEG (byte 2)');
+    } finally {
+      if (fs.existsSync(outFile)) {
+        fs.unlinkSync(outFile);
+      }
+    }
+  });
+
+  test('screenshot --selector captures a single element', () => {
+    const outFile = `/tmp/vibium-test-element-${Date.now()}.png`;
+    try {
+      execSync(`${CLICKER} screenshot https://example.com -o ${outFile} --headless --viewport 1280x720 --selector h1`, {
+        encoding: 'utf-8',
+        timeout: 30000,
+      });
+
+      const buffer = fs.readFileSync(outFile);
+      assert.strictEqual(buffer[1], 0x50, 'Should be valid PNG');
+      assert.ok(buffer.readUInt32BE(20) < 720, 'Element screenshot should be shorter than the viewport');
+    } finally {
+      if (fs.existsSync(outFile)) {
+        fs.unlinkSync(outFile);
+      }
+    }
+  });
+
+  test('pdf command creates valid PDF', () => {
+    const outFile = `/tmp/vibium-test-${Date.now()}.pdf`;
+    try {
+      execSync(`${CLICKER} pdf https://example.com -o ${outFile} --headless --paper A4 --margin 1 --background`, {
+        encoding: 'utf-8',
+        timeout: 30000,
+      });
+
+      const header = fs.readFileSync(outFile).subarray(0, 5).toString();
+      assert.strictEqual(header, '%PDF-', 'Should be valid PDF');
+    } finally {
+      if (fs.existsSync(outFile)) {
+        fs.unlinkSync(outFile);
+      }
+    }
+  });
+
+  test('devices command lists device profiles', () => {
+    const result = execSync(`${CLICKER} devices`, {
+      encoding: 'utf-8',
+      timeout: 30000,
+    });
+    assert.match(result, /Pixel 7/, 'Should list Pixel 7');
+    assert.match(result, /iPhone 15/, 'Should list iPhone 15');
+  });
+
   test('eval command executes JavaScript', () => {
     const result = execSync(`${CLICKER} eval https://example.com "document.title"`, {
       encoding: 'utf-8',
@@ -54,3 +137,59 @@ describe('CLI: Navigation', () => {
     assert.match(result, /Example Domain/i, 'Should return page title');
   });
 });
+
+describe('CLI: Dialogs', () => {
+  test('confirm dialogs are dismissed by default', () => {
+    const result = execSync(`${CLICKER} eval https://example.com "confirm('Sure?')" --headless`, {
+      encoding: 'utf-8',
+      timeout: 30000,
+    });
+    assert.match(result, /false/, 'confirm() should return false');
+  });
+
+  test('--dialog accept accepts confirm dialogs', () => {
+    const result = execSync(`${CLICKER} eval https://example.com "confirm('Sure?')" --headless --dialog accept`, {
+      encoding: 'utf-8',
+      timeout: 30000,
+    });
+    assert.match(result, /true/, 'confirm() should return true');
+  });
+
+  test('--dialog fail reports the blocking dialog', () => {
+    assert.throws(
+      () => execSync(`${CLICKER} eval https://example.com "alert('Hello')" --headless --dialog fail`, {
+        encoding: 'utf-8',
+        timeout: 30000,
+        stdio: 'pipe',
+      }),
+      /blocked by alert dialog/,
+      'Should fail with the dialog message'
+    );
+  });
+});
+
+describe('CLI: Emulation', () => {
+  test('--timezone sets the page timezone', () => {
+    const result = execSync(
+      `${CLICKER} eval https://example.com "Intl.DateTimeFormat().resolvedOptions().timeZone" --headless --timezone Asia/Tokyo`,
+      { encoding: 'utf-8', timeout: 30000 }
+    );
+    assert.match(result, /Result: Asia\/Tokyo/, 'Should report the emulated timezone');
+  });
+
+  test('--locale sets navigator.language', () => {
+    const result = execSync(
+      `${CLICKER} eval https://example.com "navigator.language" --headless --locale de-DE`,
+      { encoding: 'utf-8', timeout: 30000 }
+    );
+    assert.match(result, /Result: de-DE/, 'Should report the emulated locale');
+  });
+
+  test('--user-agent overrides navigator.userAgent', () => {
+    const result = execSync(
+      `${CLICKER} eval https://example.com "navigator.userAgent" --headless --user-agent vibium-test-agent`,
+      { encoding: 'utf-8', timeout: 30000 }
+    );
+    assert.match(result, /Result: vibium-test-agent/, 'Should report the overridden user agent');
+  });
+});
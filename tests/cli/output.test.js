@@ -0,0 +1,71 @@
+/**
+ * CLI Tests: JSON Output
+ * Tests --json: one document on stdout, progress on stderr
+ */
+
+const { test, describe } = require('node:test');
+const assert = require('node:assert');
+const { execSync, spawnSync } = require('node:child_process');
+const path = require('node:path');
+
+const CLICKER = path.join(__dirname, '../../clicker/bin/clicker');
+
+describe('CLI: JSON Output', () => {
+  test('eval --json prints only the result document on stdout', () => {
+    const result = execSync(`${CLICKER} eval https://example.com "document.title" --headless --json`, {
+      encoding: 'utf-8',
+      timeout: 30000,
+      stdio: ['ignore', 'pipe', 'pipe'],
+    });
+    const doc = JSON.parse(result);
+    assert.strictEqual(doc.ok, true);
+    assert.strictEqual(doc.command, 'eval');
+    assert.match(doc.value, /Example Domain/);
+  });
+
+  test('check-actionable --json reports booleans and reasons', () => {
+    const result = execSync(`${CLICKER} check-actionable https://example.com "a" --headless --json`, {
+      encoding: 'utf-8',
+      timeout: 30000,
+      stdio: ['ignore', 'pipe', 'pipe'],
+    });
+    const doc = JSON.parse(result);
+    assert.strictEqual(doc.actionability.visible, true, 'Link should be visible');
+    assert.strictEqual(doc.actionability.editable, false, 'Link should not be editable');
+    assert.ok(doc.actionability.reasons.editable, 'Should explain why the link is not editable');
+  });
+
+  test('failures print a coded error and exit with its status', () => {
+    const proc = spawnSync(CLICKER, ['click', 'https://example.com', '#does-not-exist', '--timeout', '1s', '--headless', '--json'], {
+      encoding: 'utf-8',
+      timeout: 30000,
+    });
+    assert.strictEqual(proc.status, 3, 'Should exit with the element_not_found status');
+    const doc = JSON.parse(proc.stdout);
+    assert.strictEqual(doc.ok, false);
+    assert.strictEqual(doc.command, 'click');
+    assert.strictEqual(doc.error.code, 'element_not_found');
+    assert.match(doc.error.message, /timeout|not found/i);
+    assert.match(proc.stderr, /Launching browser/, 'Progress should go to stderr');
+  });
+
+  test('usage errors are structured too', () => {
+    const proc = spawnSync(CLICKER, ['navigate', '--json'], { encoding: 'utf-8', timeout: 10000 });
+    assert.strictEqual(proc.status, 2, 'Should exit with the invalid_argument status');
+    const doc = JSON.parse(proc.stdout);
+    assert.strictEqual(doc.ok, false);
+    assert.strictEqual(doc.error.code, 'invalid_argument');
+    assert.match(doc.error.message, /arg/);
+  });
+
+  test('out-of-range geolocations are invalid arguments', () => {
+    const proc = spawnSync(CLICKER, ['navigate', 'https://example.com', '--geolocation', '91,13.4', '--json'], {
+      encoding: 'utf-8',
+      timeout: 10000,
+    });
+    assert.strictEqual(proc.status, 2, 'Should exit with the invalid_argument status');
+    const doc = JSON.parse(proc.stdout);
+    assert.strictEqual(doc.error.code, 'invalid_argument');
+    assert.match(doc.error.message, /latitude 91 out of range/);
+  });
+});
@@ -0,0 +1,51 @@
+/**
+ * CLI Tests: REPL
+ * Tests `clicker repl` with commands piped on stdin
+ */
+
+const { test, describe } = require('node:test');
+const assert = require('node:assert');
+const { execSync } = require('node:child_process');
+const path = require('node:path');
+
+const CLICKER = path.join(__dirname, '../../clicker/bin/clicker');
+
+function repl(url, input) {
+  return execSync(`${CLICKER} repl ${url} --headless`, {
+    encoding: 'utf-8',
+    timeout: 60000,
+    input,
+    stdio: ['pipe', 'pipe', 'pipe'],
+  });
+}
+
+describe('CLI: REPL', () => {
+  test('runs high-level commands against the page', () => {
+    const result = repl('https://the-internet.herokuapp.com/inputs', [
+      'find h3',
+      'type input 42',
+      'eval document.querySelector("input").value',
+      'tabs',
+    ].join('\n'));
+    assert.match(result, /tag=H3, text="Inputs"/, 'find should describe the element');
+    assert.match(result, /Value is now: 42/, 'type should type into the input');
+    assert.match(result, /^42$/m, 'eval should print the value');
+    assert.match(result, /\[0\] \S+ https:\/\/the-internet\.herokuapp\.com\/inputs/, 'tabs should list the page');
+  });
+
+  test('sends raw BiDi JSON and prints console events', () => {
+    const result = repl('https://example.com', [
+      '{"method":"browsingContext.getTree","params":{"maxDepth":0}}',
+      'eval console.log("from the page")',
+      'eval new Promise(r => setTimeout(r, 500))',
+    ].join('\n'));
+    assert.match(result, /"contexts": \[/, 'Should pretty-print the raw result');
+    assert.match(result, /\[console\.log\] from the page/, 'Should print console events');
+  });
+
+  test('reports unknown commands and keeps going', () => {
+    const result = repl('https://example.com', 'bogus\neval 1 + 1');
+    assert.match(result, /Error: unknown command "bogus"/);
+    assert.match(result, /^2$/m, 'Should run the next command');
+  });
+});
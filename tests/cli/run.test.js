@@ -0,0 +1,110 @@
+/**
+ * CLI Tests: Scripted Runs
+ * Tests `clicker run` with YAML and JSON scripts
+ */
+
+const { test, describe } = require('node:test');
+const assert = require('node:assert');
+const { execSync, spawnSync } = require('node:child_process');
+const fs = require('node:fs');
+const os = require('node:os');
+const path = require('node:path');
+
+const CLICKER = path.join(__dirname, '../../clicker/bin/clicker');
+
+describe('CLI: Run', () => {
+  test('run executes a YAML script with env substitution and a JSON report', () => {
+    const dir = fs.mkdtempSync(path.join(os.tmpdir(), 'vibium-run-'));
+    const script = path.join(dir, 'login.yaml');
+    fs.writeFileSync(script, `
+name: Login
+steps:
+  - navigate: https://the-internet.herokuapp.com/login
+  - type: {selector: "#username", text: "\${LOGIN_USER:-tomsmith}"}
+  - type: {selector: "#password", text: "\${LOGIN_PASSWORD}"}
+  - click: "button[type=submit]"
+  - assert: {selector: ".flash", text: "You logged into"}
+  - screenshot: logged-in.png
+  - eval: document.title
+`);
+    try {
+      const result = execSync(`${CLICKER} run ${script} --headless --report json`, {
+        encoding: 'utf-8',
+        timeout: 90000,
+        stdio: ['ignore', 'pipe', 'pipe'],
+        env: { ...process.env, LOGIN_PASSWORD: 'SuperSecretPassword!' },
+      });
+      const report = JSON.parse(result);
+      assert.strictEqual(report.failed, 0, 'No step should fail');
+      assert.strictEqual(report.passed, 7, 'All steps should pass');
+      assert.ok(fs.existsSync(path.join(dir, 'logged-in.png')), 'Should save the screenshot next to the script');
+    } finally {
+      fs.rmSync(dir, { recursive: true, force: true });
+    }
+  });
+
+  test('run fails with exit code 1 and writes a JUnit report', () => {
+    const dir = fs.mkdtempSync(path.join(os.tmpdir(), 'vibium-run-'));
+    const script = path.join(dir, 'fail.json');
+    const reportFile = path.join(dir, 'results.xml');
+    fs.writeFileSync(script, JSON.stringify([
+      { navigate: 'https://example.com' },
+      { assert: { title: 'Not the title' }, timeout: '1s' },
+      { eval: '1 + 1' },
+    ]));
+    try {
+      assert.throws(
+        () => execSync(`${CLICKER} run ${script} --headless --report junit --report-file ${reportFile}`, {
+          encoding: 'utf-8',
+          timeout: 60000,
+          stdio: 'pipe',
+        }),
+        (err) => err.status === 1,
+        'Should exit with status 1'
+      );
+      const xml = fs.readFileSync(reportFile, 'utf-8');
+      assert.match(xml, /failures="1"/, 'Should record the failure');
+      assert.match(xml, /skipped="1"/, 'Should skip the remaining step');
+    } finally {
+      fs.rmSync(dir, { recursive: true, force: true });
+    }
+  });
+
+  test('run reports a crashed renderer as browser_crashed and stops', () => {
+    const script = path.join(os.tmpdir(), `vibium-run-${Date.now()}.json`);
+    fs.writeFileSync(script, JSON.stringify([
+      { navigate: 'about:blank' },
+      { navigate: 'chrome://crash', continueOnError: true },
+      { eval: 'document.title', timeout: '5s' },
+      { eval: '1 + 1' },
+    ]));
+    try {
+      const result = spawnSync(CLICKER, ['run', script, '--headless', '--report', 'json'], {
+        encoding: 'utf-8',
+        timeout: 60000,
+      });
+      assert.strictEqual(result.status, 1, 'Should fail the run');
+      const report = JSON.parse(result.stdout);
+      const crashed = report.steps.filter((s) => s.code === 'browser_crashed');
+      assert.strictEqual(crashed.length, 1, 'One step should report the crash');
+      assert.match(crashed[0].error, /renderer crashed/);
+      assert.strictEqual(report.steps[3].status, 'skipped', 'Should stop after the crash');
+    } finally {
+      fs.rmSync(script, { force: true });
+    }
+  });
+
+  test('run reports undefined variables before launching', () => {
+    const script = path.join(os.tmpdir(), `vibium-run-${Date.now()}.yaml`);
+    fs.writeFileSync(script, '- navigate: "${VIBIUM_UNSET_URL}"\n');
+    try {
+      assert.throws(
+        () => execSync(`${CLICKER} run ${script}`, { encoding: 'utf-8', stdio: 'pipe' }),
+        /undefined variables: VIBIUM_UNSET_URL/,
+        'Should name the missing variable'
+      );
+    } finally {
+      fs.rmSync(script, { force: true });
+    }
+  });
+});
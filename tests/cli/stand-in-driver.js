@@ -0,0 +1,107 @@
+/**
+ * Shared helpers for CLI tests against a stand-in WebDriver server
+ * (chromedriver or geckodriver), for commands that take --connect
+ */
+
+const http = require('node:http');
+const { execFile } = require('node:child_process');
+const crypto = require('node:crypto');
+const path = require('node:path');
+
+const CLICKER = path.join(__dirname, '../../clicker/bin/clicker');
+
+// run runs clicker without blocking the event loop the stand-in driver needs
+function run(args) {
+  return new Promise((resolve) => {
+    execFile(CLICKER, args, { encoding: 'utf-8', timeout: 30000 }, (err, stdout, stderr) => {
+      resolve({ status: err ? err.code : 0, stdout, stderr });
+    });
+  });
+}
+
+// acceptWebSocket completes a WebSocket handshake on socket and calls
+// onMessage with each text message and a send function to reply with
+function acceptWebSocket(req, socket, onMessage) {
+  const accept = crypto.createHash('sha1')
+    .update(req.headers['sec-websocket-key'] + '258EAFA5-E914-47DA-95CA-C5AB0DC85B11')
+    .digest('base64');
+  socket.write(
+    'HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n' +
+    `Sec-WebSocket-Accept: ${accept}\r\n\r\n`
+  );
+
+  const send = (text) => {
+    const data = Buffer.from(text);
+    let header;
+    if (data.length < 126) {
+      header = Buffer.from([0x81, data.length]);
+    } else {
+      header = Buffer.alloc(4);
+      header.writeUInt16BE(data.length, 2);
+      header[0] = 0x81;
+      header[1] = 126;
+    }
+    socket.write(Buffer.concat([header, data]));
+  };
+
+  let buf = Buffer.alloc(0);
+  socket.on('error', () => {});
+  socket.on('data', (chunk) => {
+    buf = Buffer.concat([buf, chunk]);
+    while (buf.length >= 2) {
+      const opcode = buf[0] & 0x0f;
+      let len = buf[1] & 0x7f;
+      let off = 2;
+      if (len === 126) {
+        len = buf.readUInt16BE(2);
+        off = 4;
+      }
+      const mask = buf.subarray(off, off + 4); // clients always mask
+      off += 4;
+      if (buf.length < off + len) return;
+      const payload = Buffer.from(buf.subarray(off, off + len).map((b, i) => b ^ mask[i % 4]));
+      buf = buf.subarray(off + len);
+      if (opcode === 8) return socket.end();
+      if (opcode === 1) onMessage(payload.toString(), send);
+    }
+  });
+}
+
+// startDriver starts a stand-in WebDriver server on a free port and records
+// every request it gets. New sessions get a BiDi URL nobody listens on,
+// unless driver.bidi is set: then they connect to the server itself, where
+// respond(method, params) returns the reply to each command without its id
+// (default: success with an empty result).
+async function startDriver(respond = () => ({ type: 'success', result: {} })) {
+  const driver = { url: '', bidi: false, requests: [] };
+
+  const server = http.createServer((req, res) => {
+    let body = '';
+    req.on('data', (chunk) => { body += chunk; });
+    req.on('end', () => {
+      driver.requests.push({ method: req.method, url: req.url, body: body ? JSON.parse(body) : null });
+      res.setHeader('Content-Type', 'application/json');
+      if (req.method === 'POST' && req.url === '/session') {
+        const wsURL = driver.bidi ? `${driver.url.replace('http', 'ws')}/session/stand-in` : 'ws://127.0.0.1:9/session/stand-in';
+        res.end(JSON.stringify({
+          value: { sessionId: 'stand-in', capabilities: { webSocketUrl: wsURL } },
+        }));
+      } else {
+        res.end(JSON.stringify({ value: { ready: true } }));
+      }
+    });
+  });
+  server.on('upgrade', (req, socket) => {
+    acceptWebSocket(req, socket, (text, send) => {
+      const { id, method, params } = JSON.parse(text);
+      send(JSON.stringify({ id, ...respond(method, params) }));
+    });
+  });
+  await new Promise((resolve) => server.listen(0, '127.0.0.1', resolve));
+
+  driver.url = `http://127.0.0.1:${server.address().port}`;
+  driver.close = () => server.close();
+  return driver;
+}
+
+module.exports = { run, startDriver };
@@ -7,6 +7,7 @@ const { test, describe, before, after } = require('node:test');
 const assert = require('node:assert');
 const { spawn } = require('node:child_process');
 const path = require('node:path');
+const fs = require('node:fs');
 
 const CLICKER = path.join(__dirname, '../../clicker/bin/clicker');
 
@@ -127,21 +128,40 @@ describe('MCP Server: Protocol', () => {
     assert.ok(response.result.capabilities.tools, 'Should have tools capability');
   });
 
-  test('tools/list returns all 7 browser tools', async () => {
+  test('tools/list returns all 26 browser tools', async () => {
     const response = await client.call('tools/list', {});
 
     assert.ok(response.result, 'Should have result');
     assert.ok(response.result.tools, 'Should have tools array');
-    assert.strictEqual(response.result.tools.length, 7, 'Should have 7 tools');
+    assert.strictEqual(response.result.tools.length, 26, 'Should have 26 tools');
 
     const toolNames = response.result.tools.map(t => t.name);
     assert.ok(toolNames.includes('browser_launch'), 'Should have browser_launch');
+    assert.ok(toolNames.includes('browser_set_viewport'), 'Should have browser_set_viewport');
     assert.ok(toolNames.includes('browser_navigate'), 'Should have browser_navigate');
+    assert.ok(toolNames.includes('browser_back'), 'Should have browser_back');
+    assert.ok(toolNames.includes('browser_forward'), 'Should have browser_forward');
+    assert.ok(toolNames.includes('browser_reload'), 'Should have browser_reload');
     assert.ok(toolNames.includes('browser_click'), 'Should have browser_click');
+    assert.ok(toolNames.includes('browser_hover'), 'Should have browser_hover');
+    assert.ok(toolNames.includes('browser_drag'), 'Should have browser_drag');
+    assert.ok(toolNames.includes('browser_press'), 'Should have browser_press');
+    assert.ok(toolNames.includes('browser_scroll'), 'Should have browser_scroll');
     assert.ok(toolNames.includes('browser_type'), 'Should have browser_type');
+    assert.ok(toolNames.includes('browser_fill'), 'Should have browser_fill');
+    assert.ok(toolNames.includes('browser_select'), 'Should have browser_select');
+    assert.ok(toolNames.includes('browser_check'), 'Should have browser_check');
+    assert.ok(toolNames.includes('browser_upload'), 'Should have browser_upload');
+    assert.ok(toolNames.includes('browser_wait_for_download'), 'Should have browser_wait_for_download');
     assert.ok(toolNames.includes('browser_screenshot'), 'Should have browser_screenshot');
+    assert.ok(toolNames.includes('browser_pdf'), 'Should have browser_pdf');
     assert.ok(toolNames.includes('browser_find'), 'Should have browser_find');
+    assert.ok(toolNames.includes('browser_handle_dialog'), 'Should have browser_handle_dialog');
     assert.ok(toolNames.includes('browser_quit'), 'Should have browser_quit');
+    assert.ok(toolNames.includes('browser_tabs_list'), 'Should have browser_tabs_list');
+    assert.ok(toolNames.includes('browser_tab_new'), 'Should have browser_tab_new');
+    assert.ok(toolNames.includes('browser_tab_switch'), 'Should have browser_tab_switch');
+    assert.ok(toolNames.includes('browser_tab_close'), 'Should have browser_tab_close');
   });
 
   test('unknown method returns error', async () => {
@@ -246,6 +266,52 @@ describe('MCP Server: Browser Tools', () => {
     assert.ok(content.data.length > 100, 'Should have base64 data');
   });
 
+  test('browser_screenshot clips to a rectangle', async () => {
+    const response = await client.call('tools/call', {
+      name: 'browser_screenshot',
+      arguments: { clip: { x: 0, y: 0, width: 50, height: 40 } },
+    });
+
+    assert.ok(!response.result.isError, 'Should not be an error');
+    const png = Buffer.from(response.result.content[0].data, 'base64');
+    assert.strictEqual(png.readUInt32BE(16), 50, 'Should be as wide as the clip');
+    assert.strictEqual(png.readUInt32BE(20), 40, 'Should be as high as the clip');
+  });
+
+  test('browser_pdf saves a PDF file', async () => {
+    const filename = `vibium-test-${Date.now()}.pdf`;
+    const response = await client.call('tools/call', {
+      name: 'browser_pdf',
+      arguments: { filename: `../../${filename}`, paper: 'A4' },
+    });
+
+    assert.ok(response.result, 'Should have result');
+    assert.ok(!response.result.isError, 'Should not be an error');
+
+    const match = response.result.content[0].text.match(/PDF saved to (.+)$/);
+    assert.ok(match, 'Should report saved path');
+    const savedPath = match[1];
+    try {
+      assert.strictEqual(path.basename(savedPath), filename, 'Should strip directories from filename');
+      const header = fs.readFileSync(savedPath).subarray(0, 5).toString();
+      assert.strictEqual(header, '%PDF-', 'Should be a valid PDF');
+    } finally {
+      fs.rmSync(savedPath, { force: true });
+    }
+  });
+
+  test('browser_upload is rejected without --upload-dir', async () => {
+    const response = await client.call('tools/call', {
+      name: 'browser_upload',
+      arguments: { selector: 'input', paths: ['/etc/passwd'] },
+    });
+
+    assert.ok(response.result, 'Should have result');
+    assert.ok(response.result.isError, 'Should be an error');
+    assert.match(response.result.content[0].text, /upload-dir/, 'Should explain how to enable uploads');
+    assert.strictEqual(response.result.structuredContent.error.code, 'invalid_argument');
+  });
+
   test('browser_click clicks element', async () => {
     const response = await client.call('tools/call', {
       name: 'browser_click',
@@ -260,6 +326,128 @@ describe('MCP Server: Browser Tools', () => {
     );
   });
 
+  test('browser_back returns to the previous page', async () => {
+    const response = await client.call('tools/call', {
+      name: 'browser_back',
+      arguments: {},
+    });
+
+    assert.ok(response.result, 'Should have result');
+    assert.ok(!response.result.isError, 'Should not be an error');
+    assert.ok(
+      response.result.content[0].text.includes('example.com'),
+      'Should be back on example.com'
+    );
+  });
+
+  test('browser_reload reloads the page', async () => {
+    const response = await client.call('tools/call', {
+      name: 'browser_reload',
+      arguments: {},
+    });
+
+    assert.ok(response.result, 'Should have result');
+    assert.ok(!response.result.isError, 'Should not be an error');
+    assert.ok(
+      response.result.content[0].text.includes('Reloaded'),
+      'Should confirm reload'
+    );
+  });
+
+  test('browser_tab_new opens and activates a tab', async () => {
+    const response = await client.call('tools/call', {
+      name: 'browser_tab_new',
+      arguments: { url: 'https://example.com' },
+    });
+
+    assert.ok(response.result, 'Should have result');
+    assert.ok(!response.result.isError, 'Should not be an error');
+    assert.ok(
+      response.result.content[0].text.includes('Opened new tab'),
+      'Should confirm new tab'
+    );
+  });
+
+  test('browser_tabs_list shows both tabs with the new one active', async () => {
+    const response = await client.call('tools/call', {
+      name: 'browser_tabs_list',
+      arguments: {},
+    });
+
+    assert.ok(response.result, 'Should have result');
+    assert.ok(!response.result.isError, 'Should not be an error');
+    const lines = response.result.content[0].text.split('\n');
+    assert.strictEqual(lines.length, 2, 'Should list 2 tabs');
+    assert.ok(lines[1].startsWith('*'), 'New tab should be active');
+  });
+
+  test('browser_tab_close closes the active tab', async () => {
+    const response = await client.call('tools/call', {
+      name: 'browser_tab_close',
+      arguments: {},
+    });
+
+    assert.ok(response.result, 'Should have result');
+    assert.ok(!response.result.isError, 'Should not be an error');
+    assert.ok(
+      response.result.content[0].text.includes('Closed tab'),
+      'Should confirm close'
+    );
+  });
+
+  test('browser_click with newTab switches to the tab the click opens', async () => {
+    await client.call('tools/call', {
+      name: 'browser_navigate',
+      arguments: { url: 'data:text/html,<a href="https://example.com" target="_blank">Open</a>' },
+    });
+
+    const response = await client.call('tools/call', {
+      name: 'browser_click',
+      arguments: { selector: 'a', newTab: true },
+    });
+    assert.ok(!response.result.isError, 'Should not be an error');
+    assert.match(response.result.content[0].text, /switched to new tab/);
+
+    const tabs = await client.call('tools/call', { name: 'browser_tabs_list', arguments: {} });
+    const lines = tabs.result.content[0].text.split('\n');
+    assert.strictEqual(lines.length, 2, 'Should list 2 tabs');
+    assert.ok(lines[1].startsWith('*'), 'The opened tab should be active');
+
+    await client.call('tools/call', { name: 'browser_tab_close', arguments: {} });
+  });
+
+  test('browser_click fails on a confirm dialog under the fail policy', async () => {
+    await client.call('tools/call', {
+      name: 'browser_handle_dialog',
+      arguments: { policy: 'fail' },
+    });
+    await client.call('tools/call', {
+      name: 'browser_navigate',
+      arguments: { url: 'data:text/html,<button onclick="confirm(\'Sure?\')">Go</button>' },
+    });
+
+    const response = await client.call('tools/call', {
+      name: 'browser_click',
+      arguments: { selector: 'button' },
+    });
+
+    assert.ok(response.result, 'Should have result');
+    assert.ok(response.result.isError, 'Should be an error');
+    assert.match(response.result.content[0].text, /confirm dialog/, 'Should name the dialog');
+    assert.strictEqual(response.result.structuredContent.error.code, 'unexpected_dialog');
+  });
+
+  test('browser_handle_dialog accepts the open dialog', async () => {
+    const response = await client.call('tools/call', {
+      name: 'browser_handle_dialog',
+      arguments: { accept: true, policy: 'dismiss' },
+    });
+
+    assert.ok(response.result, 'Should have result');
+    assert.ok(!response.result.isError, 'Should not be an error');
+    assert.match(response.result.content[0].text, /Accepted confirm dialog: "Sure\?"/, 'Should confirm accept');
+  });
+
   test('browser_quit closes session', async () => {
     const response = await client.call('tools/call', {
       name: 'browser_quit',